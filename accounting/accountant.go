@@ -0,0 +1,402 @@
+package accounting
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/ilkamo/jupiter-go/history"
+)
+
+const (
+	wrappedSolMint   = "So11111111111111111111111111111111111111112"
+	lamportsPerSol   = 1_000_000_000
+	defaultQuoteMint = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v" // USDC
+
+	// lamportsPerSignature is the base fee of a transaction per signature.
+	lamportsPerSignature = 5000
+)
+
+// Period is the length of the periods of a report.
+type Period int
+
+const (
+	Daily Period = iota
+	Monthly
+	Quarterly
+	Yearly
+)
+
+// start returns the start of the period t belongs to, in UTC.
+func (p Period) start(t time.Time) time.Time {
+	t = t.UTC()
+
+	switch p {
+	case Daily:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	case Quarterly:
+		return time.Date(t.Year(), t.Month()-(t.Month()-1)%3, 1, 0, 0, 0, 0, time.UTC)
+	case Yearly:
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+func (p Period) end(start time.Time) time.Time {
+	switch p {
+	case Daily:
+		return start.AddDate(0, 0, 1)
+	case Quarterly:
+		return start.AddDate(0, 3, 0)
+	case Yearly:
+		return start.AddDate(1, 0, 0)
+	default:
+		return start.AddDate(0, 1, 0)
+	}
+}
+
+// Disposal is the sale of an amount of a mint, matched with the lots it was acquired in.
+// Values are in raw units of the quote mint.
+type Disposal struct {
+	Time      time.Time
+	Signature string
+	Mint      string
+	Amount    decimal.Decimal
+	Proceeds  decimal.Decimal
+	CostBasis decimal.Decimal
+	// Uncovered is the part of the amount without a known acquisition, disposed of at a zero cost basis.
+	Uncovered   decimal.Decimal
+	RealizedPnL decimal.Decimal
+}
+
+// Costs are the network costs of swapping, in lamports, with their value in raw units of the quote mint.
+type Costs struct {
+	// Fees are the transaction fees, including PriorityFees.
+	Fees         uint64
+	PriorityFees uint64
+	Tips         uint64
+	Value        decimal.Decimal
+	// Unvalued are the lamports of fees and tips left out of Value, for lack of a SOL price at the time
+	// of their trade.
+	Unvalued uint64
+}
+
+func (c *Costs) add(o Costs) {
+	c.Fees += o.Fees
+	c.PriorityFees += o.PriorityFees
+	c.Tips += o.Tips
+	c.Value = c.Value.Add(o.Value)
+	c.Unvalued += o.Unvalued
+}
+
+// PeriodReport summarizes the trades of a period.
+type PeriodReport struct {
+	Start       time.Time
+	End         time.Time
+	Trades      int
+	Disposals   []Disposal
+	RealizedPnL decimal.Decimal
+	Costs       Costs
+	// NetPnL is the realized PnL minus the costs.
+	NetPnL decimal.Decimal
+}
+
+// Position is the holding of a mint at the end of the report, with the PnL realized on it.
+type Position struct {
+	Mint        string
+	Amount      decimal.Decimal
+	CostBasis   decimal.Decimal
+	RealizedPnL decimal.Decimal
+	// Value and UnrealizedPnL are only set when the accountant has a valuer.
+	Value         decimal.Decimal
+	UnrealizedPnL decimal.Decimal
+	Lots          []Lot
+}
+
+// Report is the accounting of the trades of a wallet. Values are in raw units of the quote mint.
+type Report struct {
+	Wallet        string
+	QuoteMint     string
+	Method        Method
+	Periods       []PeriodReport
+	Positions     []Position
+	RealizedPnL   decimal.Decimal
+	UnrealizedPnL decimal.Decimal
+	Costs         Costs
+	// NetPnL is the realized and unrealized PnL minus the costs.
+	NetPnL decimal.Decimal
+}
+
+type accountant struct {
+	quoteMint string
+	method    Method
+	period    Period
+	valuer    Valuer
+}
+
+// NewAccountant creates an accountant that values trades in the given quote mint, USDC if empty.
+func NewAccountant(quoteMint string, opts ...AccountantOption) (Accountant, error) {
+	if quoteMint == "" {
+		quoteMint = defaultQuoteMint
+	}
+
+	a := &accountant{
+		quoteMint: quoteMint,
+		method:    FIFO,
+		period:    Monthly,
+	}
+
+	for _, opt := range opts {
+		if err := opt(a); err != nil {
+			return nil, fmt.Errorf("could not apply option: %w", err)
+		}
+	}
+
+	return a, nil
+}
+
+// Report returns one report per wallet of the trades, in the order the wallets first appear.
+//
+// Trades between the quote mint and another mint realize PnL at the quote mint amount.
+// Trades between two other mints carry the cost basis of the disposed lots over to the acquired mint,
+// without realizing PnL, since the history holds no price for them.
+// Costs are valued in the quote mint at the SOL price at the time of their trade, see solValueAt.
+// Every trade must have a block time, which places it in a period.
+func (a accountant) Report(ctx context.Context, trades []history.Trade) ([]Report, error) {
+	var (
+		wallets  []string
+		byWallet = make(map[string][]history.Trade)
+	)
+
+	for _, t := range trades {
+		if _, ok := byWallet[t.Wallet]; !ok {
+			wallets = append(wallets, t.Wallet)
+		}
+
+		byWallet[t.Wallet] = append(byWallet[t.Wallet], t)
+	}
+
+	reports := make([]Report, 0, len(wallets))
+
+	for _, wallet := range wallets {
+		report, err := a.report(ctx, wallet, byWallet[wallet])
+		if err != nil {
+			return nil, fmt.Errorf("could not report wallet %s: %w", wallet, err)
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+func (a accountant) report(ctx context.Context, wallet string, trades []history.Trade) (Report, error) {
+	trades = slices.Clone(trades)
+	history.SortTrades(trades)
+
+	report := Report{
+		Wallet:    wallet,
+		QuoteMint: a.quoteMint,
+		Method:    a.method,
+	}
+
+	var (
+		books    = make(map[string]*lots)
+		realized = make(map[string]decimal.Decimal)
+		mints    []string
+		period   *PeriodReport
+		// lastSolValue is the SOL price of the last trade between SOL and the quote mint.
+		lastSolValue *decimal.Decimal
+	)
+
+	book := func(mint string) *lots {
+		if _, ok := books[mint]; !ok {
+			books[mint] = &lots{method: a.method}
+			mints = append(mints, mint)
+		}
+
+		return books[mint]
+	}
+
+	for _, t := range trades {
+		if t.BlockTime.IsZero() {
+			return Report{}, fmt.Errorf("trade %s has no block time", t.Signature)
+		}
+
+		start := a.period.start(t.BlockTime)
+		if period == nil || !period.Start.Equal(start) {
+			report.Periods = append(report.Periods, PeriodReport{Start: start, End: a.period.end(start)})
+			period = &report.Periods[len(report.Periods)-1]
+		}
+
+		period.Trades++
+
+		inAmount := fromUint64(t.InputAmount)
+		outAmount := fromUint64(t.OutputAmount)
+
+		// cost is the cost of the acquired output in the quote mint.
+		var cost decimal.Decimal
+
+		switch {
+		case t.InputMint == a.quoteMint:
+			cost = inAmount
+		case t.OutputMint == a.quoteMint:
+			cost = outAmount
+		}
+
+		if t.InputMint != a.quoteMint {
+			basis, uncovered := book(t.InputMint).remove(inAmount)
+
+			proceeds := basis
+			if t.OutputMint == a.quoteMint {
+				proceeds = outAmount
+			} else {
+				cost = basis
+			}
+
+			disposal := Disposal{
+				Time:        t.BlockTime,
+				Signature:   t.Signature,
+				Mint:        t.InputMint,
+				Amount:      inAmount,
+				Proceeds:    proceeds,
+				CostBasis:   basis,
+				Uncovered:   uncovered,
+				RealizedPnL: proceeds.Sub(basis),
+			}
+
+			period.Disposals = append(period.Disposals, disposal)
+			period.RealizedPnL = period.RealizedPnL.Add(disposal.RealizedPnL)
+			realized[t.InputMint] = realized[t.InputMint].Add(disposal.RealizedPnL)
+		}
+
+		if t.OutputMint != a.quoteMint {
+			book(t.OutputMint).add(Lot{
+				Mint:      t.OutputMint,
+				Signature: t.Signature,
+				Acquired:  t.BlockTime,
+				Amount:    outAmount,
+				Cost:      cost,
+			})
+		}
+
+		if value, ok := a.tradeSolValue(t); ok {
+			lastSolValue = &value
+		}
+
+		solValue, err := a.solValueAt(ctx, t, lastSolValue)
+		if err != nil {
+			return Report{}, err
+		}
+
+		costs := Costs{
+			Fees:         t.Fee,
+			PriorityFees: t.PriorityFee,
+			Tips:         t.Tip,
+		}
+
+		if solValue != nil {
+			costs.Value = fromUint64(t.Fee + t.Tip).Mul(*solValue)
+		} else {
+			costs.Unvalued = t.Fee + t.Tip
+		}
+
+		period.Costs.add(costs)
+	}
+
+	for i := range report.Periods {
+		p := &report.Periods[i]
+		p.NetPnL = p.RealizedPnL.Sub(p.Costs.Value)
+
+		report.RealizedPnL = report.RealizedPnL.Add(p.RealizedPnL)
+		report.Costs.add(p.Costs)
+	}
+
+	slices.SortFunc(mints, strings.Compare)
+
+	for _, mint := range mints {
+		b := books[mint]
+
+		position := Position{
+			Mint:        mint,
+			Amount:      b.amount(),
+			CostBasis:   b.cost(),
+			RealizedPnL: realized[mint],
+			Lots:        slices.Clone(b.open),
+		}
+
+		if a.valuer != nil && position.Amount.IsPositive() {
+			amount := position.Amount.BigInt()
+			if !amount.IsUint64() {
+				return Report{}, fmt.Errorf("could not value %s: amount %s overflows 64 bits", mint, amount)
+			}
+
+			value, err := a.valuer.Value(ctx, mint, amount.Uint64())
+			if err != nil {
+				return Report{}, fmt.Errorf("could not value %s: %w", mint, err)
+			}
+
+			position.Value = value
+			position.UnrealizedPnL = value.Sub(position.CostBasis)
+			report.UnrealizedPnL = report.UnrealizedPnL.Add(position.UnrealizedPnL)
+		}
+
+		report.Positions = append(report.Positions, position)
+	}
+
+	report.NetPnL = report.RealizedPnL.Add(report.UnrealizedPnL).Sub(report.Costs.Value)
+
+	return report, nil
+}
+
+// solValueAt returns the value of a lamport in raw units of the quote mint at the time of the trade, nil if unknown.
+// It is exact when the quote mint is wrapped SOL. Otherwise it is the price of the last trade between SOL
+// and the quote mint, the trade itself included, unless the valuer is a HistoricalValuer, which values it
+// at the block time of the trade.
+func (a accountant) solValueAt(
+	ctx context.Context,
+	t history.Trade,
+	lastSolValue *decimal.Decimal,
+) (*decimal.Decimal, error) {
+	if a.quoteMint == wrappedSolMint {
+		one := decimal.NewFromInt(1)
+		return &one, nil
+	}
+
+	if _, ok := a.tradeSolValue(t); ok {
+		return lastSolValue, nil
+	}
+
+	if valuer, ok := a.valuer.(HistoricalValuer); ok {
+		value, err := valuer.ValueAt(ctx, wrappedSolMint, lamportsPerSol, t.BlockTime)
+		if err != nil {
+			return nil, fmt.Errorf("could not value SOL at %s: %w", t.BlockTime.Format(time.RFC3339), err)
+		}
+
+		value = value.Div(decimal.NewFromInt(lamportsPerSol))
+
+		return &value, nil
+	}
+
+	return lastSolValue, nil
+}
+
+// tradeSolValue returns the value of a lamport in raw units of the quote mint at the price of a trade
+// between SOL and the quote mint.
+func (a accountant) tradeSolValue(t history.Trade) (decimal.Decimal, bool) {
+	switch {
+	case t.InputAmount == 0 || t.OutputAmount == 0:
+		return decimal.Zero, false
+	case t.InputMint == wrappedSolMint && t.OutputMint == a.quoteMint:
+		return fromUint64(t.OutputAmount).Div(fromUint64(t.InputAmount)), true
+	case t.InputMint == a.quoteMint && t.OutputMint == wrappedSolMint:
+		return fromUint64(t.InputAmount).Div(fromUint64(t.OutputAmount)), true
+	default:
+		return decimal.Zero, false
+	}
+}
@@ -0,0 +1,276 @@
+package accounting_test
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilkamo/jupiter-go/accounting"
+	"github.com/ilkamo/jupiter-go/history"
+)
+
+const (
+	testWallet    = "BXzmfHxfEMcMj8hDccUNdrwXVNeybyfb2iV2nktE1VnJ"
+	testQuoteMint = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	testTokenMint = "JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN"
+	testOtherMint = "DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263"
+	testSolMint   = "So11111111111111111111111111111111111111112"
+)
+
+// valuerMock values amounts at a fixed price per raw unit.
+type valuerMock struct {
+	prices map[string]decimal.Decimal
+}
+
+func (v valuerMock) Value(_ context.Context, mint string, amount uint64) (decimal.Decimal, error) {
+	price, ok := v.prices[mint]
+	if !ok {
+		return decimal.Zero, fmt.Errorf("no price for %s", mint)
+	}
+
+	return price.Mul(decimal.NewFromInt(int64(amount))), nil
+}
+
+// historicalValuerMock values SOL at a price per raw unit for the month of the trade.
+type historicalValuerMock struct {
+	valuerMock
+	solPrices map[time.Month]decimal.Decimal
+}
+
+func (v historicalValuerMock) ValueAt(
+	_ context.Context,
+	mint string,
+	amount uint64,
+	at time.Time,
+) (decimal.Decimal, error) {
+	price, ok := v.solPrices[at.Month()]
+	if mint != testSolMint || !ok {
+		return decimal.Zero, fmt.Errorf("no price for %s in %s", mint, at.Month())
+	}
+
+	return price.Mul(decimal.NewFromInt(int64(amount))), nil
+}
+
+func testTrade(signature string, day time.Time, inputMint string, in uint64, outputMint string, out uint64) history.Trade {
+	return history.Trade{
+		Wallet:       testWallet,
+		Signature:    signature,
+		Slot:         uint64(day.Unix()),
+		BlockTime:    day,
+		InputMint:    inputMint,
+		InputAmount:  in,
+		OutputMint:   outputMint,
+		OutputAmount: out,
+		Fee:          10_000,
+		PriorityFee:  5_000,
+		Tip:          5_000,
+	}
+}
+
+func testTrades() []history.Trade {
+	jan10 := time.Date(2025, time.January, 10, 12, 0, 0, 0, time.UTC)
+	jan20 := time.Date(2025, time.January, 20, 12, 0, 0, 0, time.UTC)
+	feb5 := time.Date(2025, time.February, 5, 12, 0, 0, 0, time.UTC)
+
+	// Given out of order, the accountant sorts them.
+	return []history.Trade{
+		testTrade("sell", feb5, testTokenMint, 150, testQuoteMint, 3000),
+		testTrade("buy-1", jan10, testQuoteMint, 1000, testTokenMint, 100),
+		testTrade("buy-2", jan20, testQuoteMint, 2000, testTokenMint, 100),
+	}
+}
+
+func d(v int64) decimal.Decimal {
+	return decimal.NewFromInt(v)
+}
+
+func TestAccountant_Report(t *testing.T) {
+	valuer := historicalValuerMock{
+		valuerMock: valuerMock{prices: map[string]decimal.Decimal{testTokenMint: d(30)}},
+		// 1 SOL = 150 USDC in January and 300 USDC in February, in raw units.
+		solPrices: map[time.Month]decimal.Decimal{
+			time.January:  decimal.NewFromFloat(0.15),
+			time.February: decimal.NewFromFloat(0.3),
+		},
+	}
+
+	t.Run("invalid method", func(t *testing.T) {
+		_, err := accounting.NewAccountant(testQuoteMint, accounting.WithMethod(accounting.Method(7)))
+		require.EqualError(t, err, "could not apply option: unknown method 7")
+	})
+
+	t.Run("invalid period", func(t *testing.T) {
+		_, err := accounting.NewAccountant(testQuoteMint, accounting.WithPeriod(accounting.Period(7)))
+		require.EqualError(t, err, "could not apply option: unknown period 7")
+
+		_, err = accounting.NewAccountant(testQuoteMint, accounting.WithPeriod(accounting.Daily))
+		require.NoError(t, err)
+	})
+
+	tests := []struct {
+		method     accounting.Method
+		realized   int64
+		remaining  int64
+		unrealized int64
+	}{
+		{method: accounting.FIFO, realized: 1000, remaining: 1000, unrealized: 500},
+		{method: accounting.LIFO, realized: 500, remaining: 500, unrealized: 1000},
+		{method: accounting.AverageCost, realized: 750, remaining: 750, unrealized: 750},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method.String(), func(t *testing.T) {
+			a, err := accounting.NewAccountant(testQuoteMint,
+				accounting.WithMethod(tt.method),
+				accounting.WithValuer(valuer),
+			)
+			require.NoError(t, err)
+
+			reports, err := a.Report(context.TODO(), testTrades())
+			require.NoError(t, err)
+			require.Len(t, reports, 1)
+
+			report := reports[0]
+			require.Equal(t, testWallet, report.Wallet)
+			require.Equal(t, tt.method, report.Method)
+
+			require.Len(t, report.Periods, 2)
+			require.Equal(t, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), report.Periods[0].Start)
+			require.Equal(t, time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC), report.Periods[0].End)
+			require.Equal(t, 2, report.Periods[0].Trades)
+			require.True(t, report.Periods[0].RealizedPnL.IsZero())
+			require.Len(t, report.Periods[1].Disposals, 1)
+			require.Equal(t, d(tt.realized).String(), report.Periods[1].RealizedPnL.String())
+
+			// 15000 lamports of fee and tip per trade at 0.15 raw USDC per lamport in January, 0.3 in February.
+			require.Equal(t, uint64(20_000), report.Periods[0].Costs.Fees)
+			require.Equal(t, uint64(10_000), report.Periods[0].Costs.Tips)
+			require.Equal(t, d(4500).String(), report.Periods[0].Costs.Value.String())
+			require.Equal(t, d(-4500).String(), report.Periods[0].NetPnL.String())
+			require.Equal(t, d(4500).String(), report.Periods[1].Costs.Value.String())
+			require.Zero(t, report.Costs.Unvalued)
+
+			require.Len(t, report.Positions, 1)
+			position := report.Positions[0]
+			require.Equal(t, testTokenMint, position.Mint)
+			require.Equal(t, d(50).String(), position.Amount.String())
+			require.Equal(t, d(tt.remaining).String(), position.CostBasis.String())
+			require.Equal(t, d(1500).String(), position.Value.String())
+			require.Equal(t, d(tt.unrealized).String(), position.UnrealizedPnL.String())
+
+			require.Equal(t, d(tt.realized).String(), report.RealizedPnL.String())
+			require.Equal(t, d(tt.unrealized).String(), report.UnrealizedPnL.String())
+			require.Equal(t, d(9000).String(), report.Costs.Value.String())
+			require.Equal(t, d(tt.realized+tt.unrealized-9000).String(), report.NetPnL.String())
+		})
+	}
+
+	t.Run("swap between two non quote mints carries the cost basis over", func(t *testing.T) {
+		a, err := accounting.NewAccountant(testQuoteMint, accounting.WithPeriod(accounting.Yearly))
+		require.NoError(t, err)
+
+		mar1 := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+		trades := append(testTrades(), testTrade("rotate", mar1, testTokenMint, 50, testOtherMint, 10))
+
+		reports, err := a.Report(context.TODO(), trades)
+		require.NoError(t, err)
+
+		report := reports[0]
+		require.Len(t, report.Periods, 1)
+		require.Equal(t, 4, report.Periods[0].Trades)
+
+		rotation := report.Periods[0].Disposals[1]
+		require.True(t, rotation.RealizedPnL.IsZero())
+		require.Equal(t, d(1000).String(), rotation.CostBasis.String())
+
+		require.Len(t, report.Positions, 2)
+		require.Equal(t, testOtherMint, report.Positions[0].Mint)
+		require.Equal(t, d(1000).String(), report.Positions[0].CostBasis.String())
+		require.True(t, report.Positions[1].Amount.IsZero())
+		require.Equal(t, d(1000).String(), report.Positions[1].RealizedPnL.String())
+
+		// Without a valuer or trades of SOL, positions and costs are not valued.
+		require.True(t, report.Positions[0].Value.IsZero())
+		require.True(t, report.Costs.Value.IsZero())
+		require.Equal(t, uint64(60_000), report.Costs.Unvalued)
+	})
+
+	t.Run("disposal without acquisition has a zero cost basis", func(t *testing.T) {
+		a, err := accounting.NewAccountant(testSolMint)
+		require.NoError(t, err)
+
+		day := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+		trade := testTrade("sell", day, testTokenMint, 10, testSolMint, 1_000_000)
+		trade.Wallet = "other"
+
+		reports, err := a.Report(context.TODO(), []history.Trade{trade})
+		require.NoError(t, err)
+
+		disposal := reports[0].Periods[0].Disposals[0]
+		require.Equal(t, "other", reports[0].Wallet)
+		require.Equal(t, d(10).String(), disposal.Uncovered.String())
+		require.Equal(t, d(1_000_000).String(), disposal.RealizedPnL.String())
+
+		// Costs are exact in lamports when the quote mint is wrapped SOL.
+		require.Equal(t, d(15_000).String(), reports[0].Costs.Value.String())
+	})
+
+	t.Run("costs at the price of the last SOL trade", func(t *testing.T) {
+		a, err := accounting.NewAccountant(testQuoteMint)
+		require.NoError(t, err)
+
+		jan1 := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+		reports, err := a.Report(context.TODO(), append(testTrades(),
+			// 1 SOL = 150 USDC, then 200 USDC, in raw units.
+			testTrade("buy-sol", jan1.Add(time.Hour), testQuoteMint, 150_000_000, testSolMint, 1_000_000_000),
+			testTrade("sell-sol", jan1.AddDate(0, 0, 15), testSolMint, 1_000_000_000, testQuoteMint, 200_000_000),
+			testTrade("before", jan1, testQuoteMint, 1000, testTokenMint, 100),
+		))
+		require.NoError(t, err)
+
+		costs := reports[0].Periods[0].Costs
+		require.Equal(t, uint64(15_000), costs.Unvalued, "the trade before any SOL trade")
+		// buy-sol and buy-1 at 0.15, sell-sol and buy-2 at 0.2 raw USDC per lamport.
+		require.Equal(t, d(10_500).String(), costs.Value.String())
+		require.Equal(t, d(3000).String(), reports[0].Periods[1].Costs.Value.String())
+	})
+
+	t.Run("trade without block time", func(t *testing.T) {
+		a, err := accounting.NewAccountant(testQuoteMint)
+		require.NoError(t, err)
+
+		trades := append(testTrades(), testTrade("unknown-time", time.Time{}, testQuoteMint, 10, testTokenMint, 1))
+
+		_, err = a.Report(context.TODO(), trades)
+		require.EqualError(t, err, "could not report wallet "+testWallet+": trade unknown-time has no block time")
+	})
+
+	t.Run("position amount above 64 bits", func(t *testing.T) {
+		a, err := accounting.NewAccountant(testQuoteMint, accounting.WithValuer(valuer))
+		require.NoError(t, err)
+
+		jan10 := time.Date(2025, time.January, 10, 12, 0, 0, 0, time.UTC)
+
+		_, err = a.Report(context.TODO(), []history.Trade{
+			testTrade("buy-1", jan10, testQuoteMint, 1000, testTokenMint, math.MaxUint64),
+			testTrade("buy-2", jan10.Add(time.Hour), testQuoteMint, 1000, testTokenMint, math.MaxUint64),
+		})
+		require.EqualError(t, err, "could not report wallet "+testWallet+": could not value "+testTokenMint+
+			": amount 36893488147419103230 overflows 64 bits")
+	})
+
+	t.Run("valuer error", func(t *testing.T) {
+		a, err := accounting.NewAccountant(testQuoteMint, accounting.WithValuer(historicalValuerMock{}))
+		require.NoError(t, err)
+
+		_, err = a.Report(context.TODO(), testTrades())
+		require.EqualError(t, err, "could not report wallet "+testWallet+
+			": could not value SOL at 2025-01-10T12:00:00Z: no price for "+testSolMint+" in January")
+	})
+}
@@ -0,0 +1,26 @@
+package accounting
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/ilkamo/jupiter-go/history"
+)
+
+type Accountant interface {
+	Report(context.Context, []history.Trade) ([]Report, error)
+}
+
+// Valuer values a raw amount of a mint in raw units of a quote mint.
+type Valuer interface {
+	Value(ctx context.Context, mint string, amount uint64) (decimal.Decimal, error)
+}
+
+// HistoricalValuer is a Valuer that can also value an amount at a past time.
+// The accountant uses it to value the network costs of each trade at its block time.
+type HistoricalValuer interface {
+	Valuer
+	ValueAt(ctx context.Context, mint string, amount uint64, at time.Time) (decimal.Decimal, error)
+}
@@ -0,0 +1,115 @@
+package accounting
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Method is the cost basis method used to match disposals with acquisitions.
+type Method int
+
+const (
+	// FIFO disposes of the oldest lots first.
+	FIFO Method = iota
+	// LIFO disposes of the newest lots first.
+	LIFO
+	// AverageCost pools all the lots of a mint at their average cost.
+	AverageCost
+)
+
+func (m Method) String() string {
+	switch m {
+	case FIFO:
+		return "FIFO"
+	case LIFO:
+		return "LIFO"
+	case AverageCost:
+		return "AverageCost"
+	}
+
+	return fmt.Sprintf("Method(%d)", int(m))
+}
+
+// Lot is an acquisition of a mint that has not been fully disposed of.
+type Lot struct {
+	Mint      string
+	Signature string
+	Acquired  time.Time
+	// Amount is the remaining raw amount of the lot and Cost its cost in raw units of the quote mint.
+	Amount decimal.Decimal
+	Cost   decimal.Decimal
+}
+
+// lots holds the open lots of a single mint.
+type lots struct {
+	method Method
+	open   []Lot
+}
+
+func (l *lots) add(lot Lot) {
+	if l.method == AverageCost && len(l.open) > 0 {
+		pooled := &l.open[0]
+		pooled.Amount = pooled.Amount.Add(lot.Amount)
+		pooled.Cost = pooled.Cost.Add(lot.Cost)
+
+		return
+	}
+
+	l.open = append(l.open, lot)
+}
+
+// remove disposes of the amount and returns the cost basis of the disposed lots and the
+// part of the amount that was not covered by any lot.
+func (l *lots) remove(amount decimal.Decimal) (basis decimal.Decimal, uncovered decimal.Decimal) {
+	remaining := amount
+
+	for remaining.IsPositive() && len(l.open) > 0 {
+		idx := 0
+		if l.method == LIFO {
+			idx = len(l.open) - 1
+		}
+
+		lot := &l.open[idx]
+
+		if lot.Amount.LessThanOrEqual(remaining) {
+			basis = basis.Add(lot.Cost)
+			remaining = remaining.Sub(lot.Amount)
+			l.open = append(l.open[:idx], l.open[idx+1:]...)
+
+			continue
+		}
+
+		cost := lot.Cost.Mul(remaining).Div(lot.Amount)
+		basis = basis.Add(cost)
+		lot.Cost = lot.Cost.Sub(cost)
+		lot.Amount = lot.Amount.Sub(remaining)
+		remaining = decimal.Zero
+	}
+
+	return basis, remaining
+}
+
+func (l *lots) amount() decimal.Decimal {
+	total := decimal.Zero
+	for _, lot := range l.open {
+		total = total.Add(lot.Amount)
+	}
+
+	return total
+}
+
+func (l *lots) cost() decimal.Decimal {
+	total := decimal.Zero
+	for _, lot := range l.open {
+		total = total.Add(lot.Cost)
+	}
+
+	return total
+}
+
+func fromUint64(v uint64) decimal.Decimal {
+	return decimal.NewFromBigInt(new(big.Int).SetUint64(v), 0)
+}
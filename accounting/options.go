@@ -0,0 +1,41 @@
+package accounting
+
+import (
+	"fmt"
+)
+
+// AccountantOption is a function that allows to specify options for the accountant.
+type AccountantOption func(*accountant) error
+
+// WithMethod sets the cost basis method, FIFO by default.
+func WithMethod(method Method) AccountantOption {
+	return func(a *accountant) error {
+		if method < FIFO || method > AverageCost {
+			return fmt.Errorf("unknown method %d", method)
+		}
+
+		a.method = method
+		return nil
+	}
+}
+
+// WithPeriod sets the length of the periods of the reports, monthly by default.
+func WithPeriod(period Period) AccountantOption {
+	return func(a *accountant) error {
+		if period < Daily || period > Yearly {
+			return fmt.Errorf("unknown period %d", period)
+		}
+
+		a.period = period
+		return nil
+	}
+}
+
+// WithValuer sets the valuer used for open positions, and for costs if it is a HistoricalValuer.
+// It must value in the quote mint of the accountant.
+func WithValuer(valuer Valuer) AccountantOption {
+	return func(a *accountant) error {
+		a.valuer = valuer
+		return nil
+	}
+}
@@ -0,0 +1,80 @@
+package accounting
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+
+	"github.com/ilkamo/jupiter-go/history"
+	"github.com/ilkamo/jupiter-go/swap"
+)
+
+// TradeFromSwapResult converts the result of a swapper into a trade executed at the given time.
+// Amounts are the quoted ones, the hop AMM is the AMM key of the quote and the fee is the base fee
+// of the swap transaction signatures plus the prioritization fee reported by /swap.
+func TradeFromSwapResult(wallet string, res swap.Result, executedAt time.Time) (history.Trade, error) {
+	if res.TxID == "" {
+		return history.Trade{}, fmt.Errorf("swap result has no transaction")
+	}
+
+	inAmount, err := strconv.ParseUint(res.Quote.InAmount, 10, 64)
+	if err != nil {
+		return history.Trade{}, fmt.Errorf("could not parse quote in amount: %w", err)
+	}
+
+	outAmount, err := strconv.ParseUint(res.Quote.OutAmount, 10, 64)
+	if err != nil {
+		return history.Trade{}, fmt.Errorf("could not parse quote out amount: %w", err)
+	}
+
+	trade := history.Trade{
+		Wallet:       wallet,
+		Signature:    string(res.TxID),
+		BlockTime:    executedAt.UTC(),
+		InputMint:    res.Quote.InputMint,
+		InputAmount:  inAmount,
+		OutputMint:   res.Quote.OutputMint,
+		OutputAmount: outAmount,
+	}
+
+	if res.Quote.ContextSlot != nil {
+		trade.Slot = *res.Quote.ContextSlot
+	}
+
+	tx, err := solana.TransactionFromBase64(res.Swap.SwapTransaction)
+	if err != nil {
+		return history.Trade{}, fmt.Errorf("could not deserialize swap transaction: %w", err)
+	}
+
+	if res.Swap.PrioritizationFeeLamports != nil {
+		trade.PriorityFee = *res.Swap.PrioritizationFeeLamports
+	}
+
+	trade.Fee = uint64(tx.Message.Header.NumRequiredSignatures)*lamportsPerSignature + trade.PriorityFee
+
+	for _, step := range res.Quote.RoutePlan {
+		hop := history.Hop{
+			AMM:        step.SwapInfo.AmmKey,
+			InputMint:  step.SwapInfo.InputMint,
+			OutputMint: step.SwapInfo.OutputMint,
+		}
+
+		if step.SwapInfo.Label != nil {
+			hop.Label = *step.SwapInfo.Label
+		}
+
+		if hop.InputAmount, err = strconv.ParseUint(step.SwapInfo.InAmount, 10, 64); err != nil {
+			return history.Trade{}, fmt.Errorf("could not parse route plan in amount: %w", err)
+		}
+
+		if hop.OutputAmount, err = strconv.ParseUint(step.SwapInfo.OutAmount, 10, 64); err != nil {
+			return history.Trade{}, fmt.Errorf("could not parse route plan out amount: %w", err)
+		}
+
+		trade.Hops = append(trade.Hops, hop)
+	}
+
+	return trade, nil
+}
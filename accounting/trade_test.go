@@ -0,0 +1,95 @@
+package accounting_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilkamo/jupiter-go/accounting"
+	"github.com/ilkamo/jupiter-go/history"
+	"github.com/ilkamo/jupiter-go/jupiter"
+	"github.com/ilkamo/jupiter-go/jupiter/jupitertest"
+	"github.com/ilkamo/jupiter-go/swap"
+)
+
+func TestTradeFromSwapResult(t *testing.T) {
+	slot := uint64(321)
+	priorityFee := uint64(7000)
+	label := "Meteora DLMM"
+
+	res := swap.Result{
+		Quote: jupiter.QuoteResponse{
+			ContextSlot: &slot,
+			InputMint:   testSolMint,
+			InAmount:    "100000",
+			OutputMint:  testTokenMint,
+			OutAmount:   "24266",
+			RoutePlan: []jupiter.RoutePlanStep{{
+				Percent: 100,
+				SwapInfo: jupiter.SwapInfo{
+					AmmKey:     "amm",
+					Label:      &label,
+					InputMint:  testSolMint,
+					InAmount:   "100000",
+					OutputMint: testTokenMint,
+					OutAmount:  "24266",
+				},
+			}},
+		},
+		Swap: jupiter.SwapResponse{
+			SwapTransaction:           jupitertest.DefaultFixtures().Swap.SwapTransaction,
+			PrioritizationFeeLamports: &priorityFee,
+		},
+		TxID: "signature",
+	}
+
+	executedAt := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("missing transaction", func(t *testing.T) {
+		_, err := accounting.TradeFromSwapResult(testWallet, swap.Result{}, executedAt)
+		require.EqualError(t, err, "swap result has no transaction")
+	})
+
+	t.Run("invalid amount", func(t *testing.T) {
+		invalid := res
+		invalid.Quote.InAmount = "abc"
+
+		_, err := accounting.TradeFromSwapResult(testWallet, invalid, executedAt)
+		require.ErrorContains(t, err, "could not parse quote in amount")
+	})
+
+	t.Run("invalid transaction", func(t *testing.T) {
+		invalid := res
+		invalid.Swap.SwapTransaction = ""
+
+		_, err := accounting.TradeFromSwapResult(testWallet, invalid, executedAt)
+		require.ErrorContains(t, err, "could not deserialize swap transaction")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		trade, err := accounting.TradeFromSwapResult(testWallet, res, executedAt)
+		require.NoError(t, err)
+		require.Equal(t, history.Trade{
+			Wallet:       testWallet,
+			Signature:    "signature",
+			Slot:         slot,
+			BlockTime:    executedAt,
+			InputMint:    testSolMint,
+			InputAmount:  100000,
+			OutputMint:   testTokenMint,
+			OutputAmount: 24266,
+			// One signature at 5000 lamports plus the priority fee.
+			Fee:         12000,
+			PriorityFee: priorityFee,
+			Hops: []history.Hop{{
+				AMM:          "amm",
+				Label:        label,
+				InputMint:    testSolMint,
+				InputAmount:  100000,
+				OutputMint:   testTokenMint,
+				OutputAmount: 24266,
+			}},
+		}, trade)
+	})
+}
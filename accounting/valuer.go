@@ -0,0 +1,54 @@
+package accounting
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/ilkamo/jupiter-go/jupiter"
+)
+
+type jupiterValuer struct {
+	jupClient jupiter.ClientWithResponsesInterface
+	quoteMint string
+}
+
+// NewJupiterValuer creates a valuer that values amounts at the out amount of a Jupiter quote into the quote mint.
+func NewJupiterValuer(jupClient jupiter.ClientWithResponsesInterface, quoteMint string) (Valuer, error) {
+	if jupClient == nil {
+		return nil, fmt.Errorf("jupiter client is required")
+	}
+
+	if quoteMint == "" {
+		quoteMint = defaultQuoteMint
+	}
+
+	return jupiterValuer{jupClient: jupClient, quoteMint: quoteMint}, nil
+}
+
+func (v jupiterValuer) Value(ctx context.Context, mint string, amount uint64) (decimal.Decimal, error) {
+	if mint == v.quoteMint || amount == 0 {
+		return fromUint64(amount), nil
+	}
+
+	resp, err := v.jupClient.QuoteGetWithResponse(ctx, &jupiter.QuoteGetParams{
+		InputMint:  mint,
+		OutputMint: v.quoteMint,
+		Amount:     amount,
+	})
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("could not get quote: %w", err)
+	}
+
+	if resp.JSON200 == nil {
+		return decimal.Zero, fmt.Errorf("could not get quote: %s: %s", resp.Status(), resp.Body)
+	}
+
+	value, err := decimal.NewFromString(resp.JSON200.OutAmount)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("could not parse quote out amount: %w", err)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,91 @@
+package accounting_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilkamo/jupiter-go/accounting"
+	"github.com/ilkamo/jupiter-go/jupiter"
+)
+
+type jupiterMock struct {
+	jupiter.ClientWithResponsesInterface
+	outAmount       string
+	quoteStatusCode int
+	lastParams      *jupiter.QuoteGetParams
+}
+
+func (j jupiterMock) QuoteGetWithResponse(
+	_ context.Context,
+	params *jupiter.QuoteGetParams,
+	_ ...jupiter.RequestEditorFn,
+) (*jupiter.QuoteGetResponse, error) {
+	if j.lastParams != nil {
+		*j.lastParams = *params
+	}
+
+	if j.quoteStatusCode != 0 {
+		return &jupiter.QuoteGetResponse{
+			Body:         []byte("bad request"),
+			HTTPResponse: &http.Response{StatusCode: j.quoteStatusCode, Status: http.StatusText(j.quoteStatusCode)},
+		}, nil
+	}
+
+	return &jupiter.QuoteGetResponse{
+		HTTPResponse: &http.Response{StatusCode: http.StatusOK},
+		JSON200: &jupiter.QuoteResponse{
+			InputMint:  params.InputMint,
+			OutputMint: params.OutputMint,
+			OutAmount:  j.outAmount,
+		},
+	}, nil
+}
+
+func TestJupiterValuer_Value(t *testing.T) {
+	t.Run("missing client", func(t *testing.T) {
+		_, err := accounting.NewJupiterValuer(nil, "")
+		require.EqualError(t, err, "jupiter client is required")
+	})
+
+	t.Run("quote mint is valued at its amount", func(t *testing.T) {
+		v, err := accounting.NewJupiterValuer(jupiterMock{quoteStatusCode: http.StatusBadRequest}, "")
+		require.NoError(t, err)
+
+		value, err := v.Value(context.TODO(), testQuoteMint, 1234)
+		require.NoError(t, err)
+		require.Equal(t, "1234", value.String())
+	})
+
+	t.Run("other mints are valued at the quote out amount", func(t *testing.T) {
+		var params jupiter.QuoteGetParams
+
+		v, err := accounting.NewJupiterValuer(jupiterMock{outAmount: "150000000", lastParams: &params}, testQuoteMint)
+		require.NoError(t, err)
+
+		value, err := v.Value(context.TODO(), testSolMint, 1_000_000_000)
+		require.NoError(t, err)
+		require.Equal(t, "150000000", value.String())
+		require.Equal(t, testSolMint, params.InputMint)
+		require.Equal(t, testQuoteMint, params.OutputMint)
+		require.Equal(t, uint64(1_000_000_000), params.Amount)
+	})
+
+	t.Run("quote error", func(t *testing.T) {
+		v, err := accounting.NewJupiterValuer(jupiterMock{quoteStatusCode: http.StatusBadRequest}, "")
+		require.NoError(t, err)
+
+		_, err = v.Value(context.TODO(), testSolMint, 1)
+		require.EqualError(t, err, "could not get quote: Bad Request: bad request")
+	})
+
+	t.Run("invalid out amount", func(t *testing.T) {
+		v, err := accounting.NewJupiterValuer(jupiterMock{outAmount: "abc"}, "")
+		require.NoError(t, err)
+
+		_, err = v.Value(context.TODO(), testSolMint, 1)
+		require.ErrorContains(t, err, "could not parse quote out amount")
+	})
+}
@@ -0,0 +1,12 @@
+package cassette
+
+import "net/http"
+
+// Recorder is an http.RoundTripper that records interactions to a cassette file or replays them from it.
+type Recorder interface {
+	http.RoundTripper
+	// HTTPClient returns an HTTP client using the recorder as transport.
+	HTTPClient() *http.Client
+	// Stop saves the cassette file when recording.
+	Stop() error
+}
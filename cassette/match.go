@@ -0,0 +1,165 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// requestKey returns the string two requests matching each other share.
+func (r *recorder) requestKey(method string, u *url.URL, body []byte) string {
+	query := u.Query()
+	for name := range r.redactedQuery {
+		query.Del(name)
+	}
+
+	// An empty path and "/" both address the root, e.g. of an RPC endpoint.
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+
+	return strings.Join([]string{method, path, query.Encode(), r.normalizeBody(body)}, "\n")
+}
+
+// normalizeBody removes the ignored fields of a JSON body and re-encodes it with sorted keys.
+// Other bodies are returned as is.
+func (r *recorder) normalizeBody(body []byte) string {
+	v, ok := decodeJSON(body)
+	if !ok {
+		return string(body)
+	}
+
+	// Batches of JSON-RPC requests have their fields ignored in each request.
+	if batch, isBatch := v.([]any); isBatch {
+		for i := range batch {
+			batch[i] = r.removeIgnored(batch[i])
+		}
+	} else {
+		v = r.removeIgnored(v)
+	}
+
+	normalized, err := json.Marshal(v)
+	if err != nil {
+		return string(body)
+	}
+
+	return string(normalized)
+}
+
+func (r *recorder) removeIgnored(v any) any {
+	for _, path := range r.ignoredFields {
+		removePath(v, strings.Split(path, "."))
+	}
+
+	return v
+}
+
+func removePath(v any, path []string) {
+	if len(path) == 0 {
+		return
+	}
+
+	switch node := v.(type) {
+	case map[string]any:
+		if len(path) == 1 {
+			delete(node, path[0])
+			return
+		}
+
+		removePath(node[path[0]], path[1:])
+	case []any:
+		i, err := strconv.Atoi(path[0])
+		if err != nil || i < 0 || i >= len(node) {
+			return
+		}
+
+		if len(path) == 1 {
+			node[i] = nil
+			return
+		}
+
+		removePath(node[i], path[1:])
+	}
+}
+
+func decodeJSON(body []byte) (any, bool) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, false
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, false
+	}
+
+	return v, true
+}
+
+// replaceID sets the "id" of a replayed JSON-RPC response to the one of the request.
+func replaceID(respBody, reqBody []byte) []byte {
+	req, ok := decodeJSON(reqBody)
+	if !ok {
+		return respBody
+	}
+
+	resp, ok := decodeJSON(respBody)
+	if !ok {
+		return respBody
+	}
+
+	reqObj, reqIsObj := req.(map[string]any)
+	respObj, respIsObj := resp.(map[string]any)
+
+	if !reqIsObj || !respIsObj {
+		return respBody
+	}
+
+	id, reqHasID := reqObj["id"]
+	if _, respHasID := respObj["id"]; !reqHasID || !respHasID {
+		return respBody
+	}
+
+	respObj["id"] = id
+
+	replaced, err := json.Marshal(respObj)
+	if err != nil {
+		return respBody
+	}
+
+	return replaced
+}
+
+func (r *recorder) redactURL(u *url.URL) string {
+	redacted := *u
+
+	query := redacted.Query()
+	for name := range r.redactedQuery {
+		if query.Has(name) {
+			query.Set(name, Redacted)
+		}
+	}
+
+	redacted.RawQuery = query.Encode()
+	redacted.User = nil
+
+	return redacted.String()
+}
+
+func (r *recorder) redactHeaders(h http.Header) http.Header {
+	redacted := h.Clone()
+
+	for name := range redacted {
+		if _, ok := r.redactedHeaders[http.CanonicalHeaderKey(name)]; ok {
+			redacted[name] = []string{Redacted}
+		}
+	}
+
+	return redacted
+}
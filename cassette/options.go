@@ -0,0 +1,60 @@
+package cassette
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// RecorderOption is a function that allows to specify options for the recorder.
+type RecorderOption func(*recorder) error
+
+// WithTransport sets the transport used to reach the real servers when recording, http.DefaultTransport by default.
+func WithTransport(transport http.RoundTripper) RecorderOption {
+	return func(r *recorder) error {
+		if transport == nil {
+			return fmt.Errorf("transport is required")
+		}
+
+		r.transport = transport
+		return nil
+	}
+}
+
+// WithRedactedHeaders adds request and response headers whose values are not saved.
+func WithRedactedHeaders(names ...string) RecorderOption {
+	return func(r *recorder) error {
+		for _, name := range names {
+			r.redactedHeaders[http.CanonicalHeaderKey(name)] = struct{}{}
+		}
+		return nil
+	}
+}
+
+// WithRedactedQueryParams adds query parameters whose values are not saved. They are ignored when matching.
+func WithRedactedQueryParams(names ...string) RecorderOption {
+	return func(r *recorder) error {
+		for _, name := range names {
+			r.redactedQuery[name] = struct{}{}
+		}
+		return nil
+	}
+}
+
+// WithIgnoredFields adds JSON body fields ignored when matching requests, as dot separated paths
+// such as "params.1.minContextSlot". Array elements are addressed by index.
+func WithIgnoredFields(paths ...string) RecorderOption {
+	return func(r *recorder) error {
+		r.ignoredFields = append(r.ignoredFields, paths...)
+		return nil
+	}
+}
+
+// WithRedactor adds a function applied to every interaction before it is saved, e.g. to remove a token
+// from the URL path of an RPC endpoint. When replaying, it is also applied to every live request, with an
+// empty response, so that the request matches its redacted recording.
+func WithRedactor(redactor func(*Interaction)) RecorderOption {
+	return func(r *recorder) error {
+		r.redactors = append(r.redactors, redactor)
+		return nil
+	}
+}
@@ -0,0 +1,225 @@
+package cassette
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+)
+
+// ErrNoInteraction is returned when replaying a request that was not recorded.
+var ErrNoInteraction = errors.New("no recorded interaction")
+
+type recorder struct {
+	path            string
+	mode            Mode
+	transport       http.RoundTripper
+	redactedHeaders map[string]struct{}
+	redactedQuery   map[string]struct{}
+	ignoredFields   []string
+	redactors       []func(*Interaction)
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// NewRecorder creates a recorder for the cassette file at path.
+//
+// The Authorization and X-Api-Key headers and the api-key query parameter are redacted, and the JSON-RPC
+// "id" field is ignored when matching requests. Requests match on their method, URL path, query and JSON body.
+// Identical requests are replayed in the order they were recorded, e.g. the polls of a signature status.
+func NewRecorder(path string, mode Mode, opts ...RecorderOption) (Recorder, error) {
+	r := &recorder{
+		path:      path,
+		mode:      mode,
+		transport: http.DefaultTransport,
+		redactedHeaders: map[string]struct{}{
+			"Authorization": {},
+			"X-Api-Key":     {},
+		},
+		redactedQuery: map[string]struct{}{
+			"api-key": {},
+		},
+		ignoredFields: []string{"id"},
+	}
+
+	for _, opt := range opts {
+		if err := opt(r); err != nil {
+			return nil, fmt.Errorf("could not apply option: %w", err)
+		}
+	}
+
+	if r.mode == ModeAuto {
+		r.mode = ModeRecord
+
+		if _, err := os.Stat(path); err == nil {
+			r.mode = ModeReplay
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("could not stat cassette: %w", err)
+		}
+	}
+
+	switch r.mode {
+	case ModeReplay:
+		c, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+
+		r.cassette = c
+		r.used = make([]bool, len(c.Interactions))
+	case ModeRecord:
+	default:
+		return nil, fmt.Errorf("unknown mode %d", mode)
+	}
+
+	return r, nil
+}
+
+func (r *recorder) HTTPClient() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+func (r *recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.cassette.Save(r.path)
+}
+
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, body)
+	}
+
+	return r.record(req, body)
+}
+
+func (r *recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read response body: %w", err)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := Interaction{
+		Request: r.redactRequest(req, body),
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Headers:    r.redactHeaders(resp.Header),
+			Body:       string(respBody),
+		},
+	}
+
+	for _, redact := range r.redactors {
+		redact(&interaction)
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+func (r *recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	// The live request is redacted like the recorded ones, e.g. a token in its path, so that they match.
+	live := Interaction{Request: r.redactRequest(req, body)}
+	for _, redact := range r.redactors {
+		redact(&live)
+	}
+
+	liveURL, err := url.Parse(live.Request.URL)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse redacted url: %w", err)
+	}
+
+	key := r.requestKey(live.Request.Method, liveURL, []byte(live.Request.Body))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] {
+			continue
+		}
+
+		recordedURL, err := url.Parse(interaction.Request.URL)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse recorded url: %w", err)
+		}
+
+		if r.requestKey(interaction.Request.Method, recordedURL, []byte(interaction.Request.Body)) != key {
+			continue
+		}
+
+		r.used[i] = true
+
+		respBody := replaceID([]byte(interaction.Response.Body), body)
+
+		return &http.Response{
+			StatusCode:    interaction.Response.StatusCode,
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        interaction.Response.Headers.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(respBody)),
+			ContentLength: int64(len(respBody)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w for %s %s", ErrNoInteraction, req.Method, live.Request.URL)
+}
+
+// redactRequest returns the request as saved, before the redactors run.
+func (r *recorder) redactRequest(req *http.Request, body []byte) Request {
+	return Request{
+		Method:  req.Method,
+		URL:     r.redactURL(req.URL),
+		Headers: r.redactHeaders(req.Header),
+		Body:    string(body),
+	}
+}
+
+// readBody reads the body of the request and restores it for the real transport.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read request body: %w", err)
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
@@ -0,0 +1,283 @@
+package cassette_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilkamo/jupiter-go/cassette"
+	"github.com/ilkamo/jupiter-go/jupiter"
+	"github.com/ilkamo/jupiter-go/jupiter/jupitertest"
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+	"github.com/ilkamo/jupiter-go/solana/solanatest"
+)
+
+const testAPIKey = "super-secret-key"
+
+func newJupiterClient(t *testing.T, url string, rec cassette.Recorder) *jupiter.ClientWithResponses {
+	t.Helper()
+
+	client, err := jupiter.NewClientWithResponses(url,
+		jupiter.WithHTTPClient(rec.HTTPClient()),
+		jupiter.WithRequestEditorFn(func(_ context.Context, req *http.Request) error {
+			req.Header.Set("x-api-key", testAPIKey)
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestRecorder_Jupiter(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	path := filepath.Join(t.TempDir(), "jupiter.json")
+	fixtures := jupitertest.DefaultFixtures()
+
+	params := &jupiter.QuoteGetParams{
+		InputMint:  fixtures.Quote.InputMint,
+		OutputMint: fixtures.Quote.OutputMint,
+		Amount:     100000,
+	}
+
+	srv := jupitertest.NewServer()
+	srv.Fail(jupitertest.PathQuote, jupitertest.RateLimited())
+
+	rec, err := cassette.NewRecorder(path, cassette.ModeRecord)
+	require.NoError(t, err)
+
+	client := newJupiterClient(t, srv.URL(), rec)
+
+	for _, expected := range []int{http.StatusTooManyRequests, http.StatusOK} {
+		resp, err := client.QuoteGetWithResponse(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, expected, resp.StatusCode())
+	}
+
+	swapResp, err := client.SwapPostWithResponse(ctx, jupiter.SwapRequest{
+		UserPublicKey: "BXzmfHxfEMcMj8hDccUNdrwXVNeybyfb2iV2nktE1VnJ",
+		QuoteResponse: fixtures.Quote,
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, swapResp.StatusCode())
+
+	require.NoError(t, rec.Stop())
+	srv.Close()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), testAPIKey)
+	require.Contains(t, string(data), cassette.Redacted)
+
+	recorded, err := cassette.Load(path)
+	require.NoError(t, err)
+	require.Len(t, recorded.Interactions, 3)
+
+	t.Run("replay", func(t *testing.T) {
+		rec, err := cassette.NewRecorder(path, cassette.ModeReplay)
+		require.NoError(t, err)
+
+		// The server is closed: every response comes from the cassette, on another host.
+		client := newJupiterClient(t, "http://jupiter.invalid", rec)
+
+		for _, expected := range []int{http.StatusTooManyRequests, http.StatusOK} {
+			resp, err := client.QuoteGetWithResponse(ctx, params)
+			require.NoError(t, err)
+			require.Equal(t, expected, resp.StatusCode())
+		}
+
+		replayed, err := client.SwapPostWithResponse(ctx, jupiter.SwapRequest{
+			UserPublicKey: "BXzmfHxfEMcMj8hDccUNdrwXVNeybyfb2iV2nktE1VnJ",
+			QuoteResponse: fixtures.Quote,
+		})
+		require.NoError(t, err)
+		require.Equal(t, *swapResp.JSON200, *replayed.JSON200)
+
+		// Every interaction was replayed once.
+		_, err = client.QuoteGetWithResponse(ctx, params)
+		require.ErrorIs(t, err, cassette.ErrNoInteraction)
+	})
+
+	t.Run("replay does not match another query", func(t *testing.T) {
+		rec, err := cassette.NewRecorder(path, cassette.ModeReplay)
+		require.NoError(t, err)
+
+		client := newJupiterClient(t, "http://jupiter.invalid", rec)
+
+		_, err = client.QuoteGetWithResponse(ctx, &jupiter.QuoteGetParams{
+			InputMint:  fixtures.Quote.InputMint,
+			OutputMint: fixtures.Quote.OutputMint,
+			Amount:     200000,
+		})
+		require.ErrorIs(t, err, cassette.ErrNoInteraction)
+	})
+}
+
+func TestRecorder_SolanaRPC(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	path := filepath.Join(t.TempDir(), "rpc.json")
+
+	node := solanatest.NewServer()
+
+	tokenAccount := solana.NewWallet().PublicKey()
+	node.SetTokenBalance(tokenAccount, solanatest.TokenBalance{Amount: 42, Decimals: 6})
+
+	sig := solana.Signature{1, 2, 3}
+	txID := jupSolana.TxID(sig.String())
+
+	newClient := func(endpoint string, rec cassette.Recorder) jupSolana.Client {
+		client, err := jupSolana.NewClient(
+			jupSolana.Wallet{Wallet: solana.NewWallet()},
+			endpoint,
+			jupSolana.WithHTTPClient(rec.HTTPClient()),
+		)
+		require.NoError(t, err)
+
+		return client
+	}
+
+	check := func(client jupSolana.Client) {
+		balance, err := client.GetTokenAccountBalance(ctx, tokenAccount.String())
+		require.NoError(t, err)
+		require.Equal(t, "42", balance.Amount.String())
+
+		_, err = client.CheckSignature(ctx, txID)
+		require.EqualError(t, err, "transaction not finalized yet")
+
+		if node != nil {
+			node.SetSignatureStatus(sig, rpc.ConfirmationStatusFinalized, nil)
+		}
+
+		ok, err := client.CheckSignature(ctx, txID)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	rec, err := cassette.NewRecorder(path, cassette.ModeRecord)
+	require.NoError(t, err)
+
+	check(newClient(node.URL()+"?api-key="+testAPIKey, rec))
+
+	require.NoError(t, rec.Stop())
+	node.Close()
+	node = nil
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), testAPIKey)
+
+	rec, err = cassette.NewRecorder(path, cassette.ModeReplay)
+	require.NoError(t, err)
+
+	// JSON-RPC ids and the api-key differ from the recording.
+	check(newClient("http://rpc.invalid/?api-key=another-key", rec))
+}
+
+func TestRecorder_Matching(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	post := func(t *testing.T, rec cassette.Recorder, url, body string) (string, error) {
+		resp, err := rec.HTTPClient().Post(url, "application/json", strings.NewReader(body))
+		if err != nil {
+			return "", err
+		}
+
+		defer func() { _ = resp.Body.Close() }()
+
+		respBody, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return string(respBody), nil
+	}
+
+	path := filepath.Join(t.TempDir(), "nested", "matching.json")
+
+	rec, err := cassette.NewRecorder(path, cassette.ModeAuto,
+		cassette.WithIgnoredFields("params.0.timestamp"),
+		cassette.WithRedactor(func(i *cassette.Interaction) {
+			i.Request.URL = strings.ReplaceAll(i.Request.URL, "token123", cassette.Redacted)
+		}),
+	)
+	require.NoError(t, err)
+
+	body, err := post(t, rec, srv.URL+"/token123/rpc", `{"params":[{"amount":1,"timestamp":100}],"id":1}`)
+	require.NoError(t, err)
+	require.Equal(t, `{"params":[{"amount":1,"timestamp":100}],"id":1}`, body)
+	require.NoError(t, rec.Stop())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "token123")
+
+	replay := func(t *testing.T) cassette.Recorder {
+		rec, err := cassette.NewRecorder(path, cassette.ModeAuto, cassette.WithIgnoredFields("params.0.timestamp"))
+		require.NoError(t, err)
+
+		return rec
+	}
+
+	t.Run("redacted path", func(t *testing.T) {
+		rec, err := cassette.NewRecorder(path, cassette.ModeAuto,
+			cassette.WithIgnoredFields("params.0.timestamp"),
+			cassette.WithRedactor(func(i *cassette.Interaction) {
+				i.Request.URL = strings.ReplaceAll(i.Request.URL, "token123", cassette.Redacted)
+			}),
+		)
+		require.NoError(t, err)
+
+		body, err := post(t, rec, srv.URL+"/token123/rpc", `{"params":[{"amount":1,"timestamp":300}],"id":3}`)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"params":[{"amount":1,"timestamp":100}],"id":3}`, body)
+	})
+
+	t.Run("ignored fields and key order do not matter", func(t *testing.T) {
+		body, err := post(t, replay(t), srv.URL+"/"+cassette.Redacted+"/rpc", `{"id":7,"params":[{"timestamp":200,"amount":1}]}`)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"params":[{"amount":1,"timestamp":100}],"id":7}`, body)
+	})
+
+	t.Run("other fields do", func(t *testing.T) {
+		_, err := post(t, replay(t), srv.URL+"/"+cassette.Redacted+"/rpc", `{"id":1,"params":[{"amount":2,"timestamp":100}]}`)
+		require.ErrorIs(t, err, cassette.ErrNoInteraction)
+	})
+
+	t.Run("path does", func(t *testing.T) {
+		_, err := post(t, replay(t), srv.URL+"/other/rpc", `{"id":1,"params":[{"amount":1,"timestamp":100}]}`)
+		require.ErrorIs(t, err, cassette.ErrNoInteraction)
+	})
+}
+
+func TestNewRecorder(t *testing.T) {
+	t.Run("missing cassette", func(t *testing.T) {
+		_, err := cassette.NewRecorder(filepath.Join(t.TempDir(), "missing.json"), cassette.ModeReplay)
+		require.ErrorContains(t, err, "could not read cassette")
+	})
+
+	t.Run("unknown mode", func(t *testing.T) {
+		_, err := cassette.NewRecorder("cassette.json", cassette.Mode(9))
+		require.EqualError(t, err, "unknown mode 9")
+	})
+
+	t.Run("nil transport", func(t *testing.T) {
+		_, err := cassette.NewRecorder("cassette.json", cassette.ModeRecord, cassette.WithTransport(nil))
+		require.EqualError(t, err, "could not apply option: transport is required")
+	})
+}
@@ -0,0 +1,707 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"github.com/ilkamo/jupiter-go/jupiter"
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+	"github.com/ilkamo/jupiter-go/swap"
+)
+
+// quoteFlags are the flags of the commands that get a quote.
+type quoteFlags struct {
+	slippageBps uint64
+	exactOut    bool
+	maxAccounts uint64
+	dexes       string
+}
+
+func addQuoteFlags(fs *flag.FlagSet) *quoteFlags {
+	q := &quoteFlags{}
+
+	fs.Uint64Var(&q.slippageBps, "slippage-bps", 50, "slippage tolerance in basis points")
+	fs.BoolVar(&q.exactOut, "exact-out", false, "AMOUNT is the amount of OUT to receive")
+	fs.Uint64Var(&q.maxAccounts, "max-accounts", 0, "maximum number of accounts of the route, 0 for no limit")
+	fs.StringVar(&q.dexes, "dexes", "", "comma separated list of DEX labels to route through")
+
+	return q
+}
+
+// params resolves the tokens and the amount of the arguments into quote parameters.
+func (q *quoteFlags) params(ctx context.Context, c *config, args []string) (jupiter.QuoteGetParams, error) {
+	t, err := newTokens(c.rpcURL)
+	if err != nil {
+		return jupiter.QuoteGetParams{}, err
+	}
+
+	in, err := t.resolve(ctx, args[0])
+	if err != nil {
+		return jupiter.QuoteGetParams{}, err
+	}
+
+	out, err := t.resolve(ctx, args[1])
+	if err != nil {
+		return jupiter.QuoteGetParams{}, err
+	}
+
+	amountToken := in
+	if q.exactOut {
+		amountToken = out
+	}
+
+	amount, err := parseAmount(args[2], amountToken.Decimals)
+	if err != nil {
+		return jupiter.QuoteGetParams{}, err
+	}
+
+	slippageBps := jupiter.SlippageParameter(q.slippageBps)
+
+	params := jupiter.QuoteGetParams{
+		InputMint:   in.Mint,
+		OutputMint:  out.Mint,
+		Amount:      amount,
+		SlippageBps: &slippageBps,
+	}
+
+	if q.exactOut {
+		mode := jupiter.ExactOut
+		params.SwapMode = &mode
+	}
+
+	if q.maxAccounts > 0 {
+		maxAccounts := jupiter.MaxAccountsParameter(q.maxAccounts)
+		params.MaxAccounts = &maxAccounts
+	}
+
+	if q.dexes != "" {
+		dexes := jupiter.DexesParameter(strings.Split(q.dexes, ","))
+		params.Dexes = &dexes
+	}
+
+	return params, nil
+}
+
+func getQuote(
+	ctx context.Context,
+	jupClient jupiter.ClientWithResponsesInterface,
+	params jupiter.QuoteGetParams,
+) (jupiter.QuoteResponse, error) {
+	if err := params.Validate(); err != nil {
+		return jupiter.QuoteResponse{}, fmt.Errorf("could not get quote: %w", err)
+	}
+
+	resp, err := jupClient.QuoteGetWithResponse(ctx, &params)
+	if err != nil {
+		return jupiter.QuoteResponse{}, fmt.Errorf("could not get quote: %w", err)
+	}
+
+	if resp.JSON200 == nil {
+		return jupiter.QuoteResponse{}, fmt.Errorf("could not get quote: %s: %s", resp.Status(), resp.Body)
+	}
+
+	return *resp.JSON200, nil
+}
+
+// printQuote prints the amounts of a quote in the decimals of their tokens, and its route.
+func printQuote(ctx context.Context, w io.Writer, c *config, quote jupiter.QuoteResponse) error {
+	t, err := newTokens(c.rpcURL)
+	if err != nil {
+		return err
+	}
+
+	in, err := t.resolve(ctx, quote.InputMint)
+	if err != nil {
+		return err
+	}
+
+	out, err := t.resolve(ctx, quote.OutputMint)
+	if err != nil {
+		return err
+	}
+
+	minOut := formatAmount(quote.OtherAmountThreshold, out.Decimals) + " " + out.Symbol
+	if quote.SwapMode == jupiter.SwapModeExactOut {
+		minOut = formatAmount(quote.OtherAmountThreshold, in.Decimals) + " " + in.Symbol
+	}
+
+	labels := make([]string, 0, len(quote.RoutePlan))
+	for _, step := range quote.RoutePlan {
+		label := step.SwapInfo.AmmKey
+		if step.SwapInfo.Label != nil {
+			label = *step.SwapInfo.Label
+		}
+
+		labels = append(labels, label)
+	}
+
+	thresholdName := "Minimum out"
+	if quote.SwapMode == jupiter.SwapModeExactOut {
+		thresholdName = "Maximum in"
+	}
+
+	return render(w, c, quote, nil, [][]string{
+		{"In", formatAmount(quote.InAmount, in.Decimals) + " " + in.Symbol},
+		{"Out", formatAmount(quote.OutAmount, out.Decimals) + " " + out.Symbol},
+		{thresholdName, minOut},
+		{"Slippage", strconv.FormatUint(quote.SlippageBps, 10) + " bps"},
+		{"Price impact", quote.PriceImpactPct + " %"},
+		{"Route", strings.Join(labels, " > ")},
+	})
+}
+
+func runQuote(ctx context.Context, e *env, args []string) error {
+	fs, c := newFlagSet(e, "quote")
+	q := addQuoteFlags(fs)
+
+	if err := parse(fs, c, args, 3, 3); err != nil {
+		return err
+	}
+
+	params, err := q.params(ctx, c, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	jupClient, err := c.jupiterClient()
+	if err != nil {
+		return err
+	}
+
+	quote, err := getQuote(ctx, jupClient, params)
+	if err != nil {
+		return err
+	}
+
+	return printQuote(ctx, e.stdout, c, quote)
+}
+
+// swapOutput is the JSON output of the swap and send commands.
+type swapOutput struct {
+	Quote      *jupiter.QuoteResponse `json:"quote,omitempty"`
+	Signature  string                 `json:"signature,omitempty"`
+	Simulation *simulation            `json:"simulation,omitempty"`
+}
+
+func runSwap(ctx context.Context, e *env, args []string) error {
+	fs, c := newFlagSet(e, "swap")
+	q := addQuoteFlags(fs)
+	verify := fs.Bool("verify", true, "verify the swap transaction against the quote before signing it")
+	dryRun := fs.Bool("dry-run", false, "simulate the transaction instead of sending it")
+
+	if err := parse(fs, c, args, 3, 3); err != nil {
+		return err
+	}
+
+	params, err := q.params(ctx, c, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	wallet, err := c.wallet()
+	if err != nil {
+		return err
+	}
+
+	jupClient, err := c.jupiterClient()
+	if err != nil {
+		return err
+	}
+
+	solClient, sim, err := c.sender(wallet, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	var opts []swap.SwapperOption
+
+	if *verify {
+		verifier, err := swap.NewVerifier(wallet.PublicKey().String(), c.rpcURL)
+		if err != nil {
+			return fmt.Errorf("could not create verifier: %w", err)
+		}
+
+		opts = append(opts, swap.WithVerifier(verifier))
+	}
+
+	swapper, err := swap.NewSwapper(jupClient, solClient, wallet.PublicKey().String(), opts...)
+	if err != nil {
+		return fmt.Errorf("could not create swapper: %w", err)
+	}
+
+	res, err := swapper.Swap(ctx, swap.Request{Quote: params})
+
+	out := swapOutput{Signature: string(res.TxID)}
+	if res.Quote.InputMint != "" {
+		out.Quote = &res.Quote
+	}
+
+	if sim != nil {
+		out.Simulation = sim.simulation()
+	}
+
+	if printErr := printSent(e.stdout, c, out); printErr != nil {
+		return printErr
+	}
+
+	return err
+}
+
+func runSwapInstructions(ctx context.Context, e *env, args []string) error {
+	fs, c := newFlagSet(e, "swap-instructions")
+	q := addQuoteFlags(fs)
+	user := fs.String("user", "", "public key of the user, the wallet public key if empty")
+
+	if err := parse(fs, c, args, 3, 3); err != nil {
+		return err
+	}
+
+	userPublicKey := *user
+	if userPublicKey == "" {
+		wallet, err := c.wallet()
+		if err != nil {
+			return err
+		}
+
+		userPublicKey = wallet.PublicKey().String()
+	}
+
+	params, err := q.params(ctx, c, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	jupClient, err := c.jupiterClient()
+	if err != nil {
+		return err
+	}
+
+	quote, err := getQuote(ctx, jupClient, params)
+	if err != nil {
+		return err
+	}
+
+	resp, err := jupClient.SwapInstructionsPostWithResponse(ctx, jupiter.SwapRequest{
+		QuoteResponse: quote,
+		UserPublicKey: userPublicKey,
+	})
+	if err != nil {
+		return fmt.Errorf("could not get swap instructions: %w", err)
+	}
+
+	if resp.JSON200 == nil {
+		return fmt.Errorf("could not get swap instructions: %s: %s", resp.Status(), resp.Body)
+	}
+
+	instructions := *resp.JSON200
+
+	var rows [][]string
+
+	addRows := func(kind string, ixs ...jupiter.Instruction) {
+		for _, ix := range ixs {
+			rows = append(rows, []string{kind, ix.ProgramId, strconv.Itoa(len(ix.Accounts))})
+		}
+	}
+
+	addRows("compute budget", instructions.ComputeBudgetInstructions...)
+	addRows("setup", instructions.SetupInstructions...)
+	addRows("swap", instructions.SwapInstruction)
+
+	if instructions.CleanupInstruction != nil {
+		addRows("cleanup", *instructions.CleanupInstruction)
+	}
+
+	if instructions.OtherInstructions != nil {
+		addRows("other", *instructions.OtherInstructions...)
+	}
+
+	for _, table := range instructions.AddressLookupTableAddresses {
+		rows = append(rows, []string{"lookup table", table, ""})
+	}
+
+	return render(e.stdout, c, instructions, []string{"KIND", "PROGRAM", "ACCOUNTS"}, rows)
+}
+
+func runSend(ctx context.Context, e *env, args []string) error {
+	fs, c := newFlagSet(e, "send")
+	dryRun := fs.Bool("dry-run", false, "simulate the transaction instead of sending it")
+
+	if err := parse(fs, c, args, 1, 1); err != nil {
+		return err
+	}
+
+	txBase64 := fs.Arg(0)
+	if txBase64 == "-" {
+		b, err := io.ReadAll(e.stdin)
+		if err != nil {
+			return fmt.Errorf("could not read transaction: %w", err)
+		}
+
+		txBase64 = strings.TrimSpace(string(b))
+	}
+
+	wallet, err := c.wallet()
+	if err != nil {
+		return err
+	}
+
+	solClient, sim, err := c.sender(wallet, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	txID, err := solClient.SendTransactionOnChain(ctx, txBase64)
+
+	out := swapOutput{Signature: string(txID)}
+	if sim != nil {
+		out.Simulation = sim.simulation()
+	}
+
+	if printErr := printSent(e.stdout, c, out); printErr != nil {
+		return printErr
+	}
+
+	return err
+}
+
+// sender returns the client that sends transactions, or simulates them on a dry run.
+func (c *config) sender(wallet jupSolana.Wallet, dryRun bool) (jupSolana.Client, *simulator, error) {
+	solClient, err := c.solanaClient(wallet)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !dryRun {
+		return solClient, nil, nil
+	}
+
+	sim := &simulator{Client: solClient, rpcClient: c.rpcClient(), wallet: wallet}
+
+	return sim, sim, nil
+}
+
+func printSent(w io.Writer, c *config, out swapOutput) error {
+	if c.output == "json" {
+		return render(w, c, out, nil, nil)
+	}
+
+	var rows [][]string
+
+	if out.Signature != "" {
+		label := "Signature"
+		if out.Simulation != nil {
+			// The transaction was only simulated: its signature is not on-chain.
+			label = "Simulated signature"
+		}
+
+		rows = append(rows, []string{label, out.Signature})
+	}
+
+	if out.Simulation != nil {
+		if out.Simulation.UnitsConsumed != nil {
+			rows = append(rows, []string{"Units consumed", strconv.FormatUint(*out.Simulation.UnitsConsumed, 10)})
+		}
+
+		for _, log := range out.Simulation.Logs {
+			rows = append(rows, []string{"Log", log})
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	return render(w, c, out, nil, rows)
+}
+
+// statusOutput is the JSON output of the status command.
+type statusOutput struct {
+	Signature     string  `json:"signature"`
+	Found         bool    `json:"found"`
+	Slot          uint64  `json:"slot,omitempty"`
+	Confirmations *uint64 `json:"confirmations,omitempty"`
+	Commitment    string  `json:"commitment,omitempty"`
+	Err           any     `json:"err,omitempty"`
+}
+
+func runStatus(ctx context.Context, e *env, args []string) error {
+	fs, c := newFlagSet(e, "status")
+
+	if err := parse(fs, c, args, 1, 1); err != nil {
+		return err
+	}
+
+	signature, err := solana.SignatureFromBase58(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	resp, err := c.rpcClient().GetSignatureStatuses(ctx, true, signature)
+	if err != nil {
+		return fmt.Errorf("could not get signature status: %w", err)
+	}
+
+	out := statusOutput{Signature: signature.String()}
+
+	if len(resp.Value) > 0 && resp.Value[0] != nil {
+		status := resp.Value[0]
+
+		out.Found = true
+		out.Slot = status.Slot
+		out.Confirmations = status.Confirmations
+		out.Commitment = string(status.ConfirmationStatus)
+		out.Err = status.Err
+	}
+
+	rows := [][]string{{"Signature", out.Signature}}
+
+	if !out.Found {
+		rows = append(rows, []string{"Status", "not found"})
+		return render(e.stdout, c, out, nil, rows)
+	}
+
+	result := "ok"
+	if out.Err != nil {
+		b, _ := json.Marshal(out.Err)
+		result = "failed: " + string(b)
+	}
+
+	rows = append(rows,
+		[]string{"Slot", strconv.FormatUint(out.Slot, 10)},
+		[]string{"Commitment", out.Commitment},
+		[]string{"Result", result},
+	)
+
+	return render(e.stdout, c, out, nil, rows)
+}
+
+func runWatch(ctx context.Context, e *env, args []string) error {
+	fs, c := newFlagSet(e, "watch")
+	commitment := fs.String("commitment", "confirmed", "commitment to wait for: processed, confirmed or finalized")
+	timeout := fs.Duration("timeout", time.Minute, "maximum time to wait")
+
+	if err := parse(fs, c, args, 1, 1); err != nil {
+		return err
+	}
+
+	var status jupSolana.CommitmentStatus
+
+	switch *commitment {
+	case "processed":
+		status = jupSolana.CommitmentProcessed
+	case "confirmed":
+		status = jupSolana.CommitmentConfirmed
+	case "finalized":
+		status = jupSolana.CommitmentFinalized
+	default:
+		return fmt.Errorf("unknown commitment %q", *commitment)
+	}
+
+	monitor, err := jupSolana.NewMonitor(c.websocketURL())
+	if err != nil {
+		return fmt.Errorf("could not create monitor: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	resp, err := monitor.WaitForCommitmentStatus(ctx, jupSolana.TxID(fs.Arg(0)), status)
+	if err != nil {
+		return fmt.Errorf("could not watch transaction: %w", err)
+	}
+
+	out := struct {
+		Signature  string `json:"signature"`
+		Commitment string `json:"commitment"`
+		Err        string `json:"err,omitempty"`
+	}{Signature: fs.Arg(0), Commitment: status.String()}
+
+	result := "ok"
+	if resp.InstructionErr != nil {
+		out.Err = resp.InstructionErr.Error()
+		result = out.Err
+	}
+
+	if err := render(e.stdout, c, out, nil, [][]string{
+		{"Signature", out.Signature},
+		{"Commitment", out.Commitment},
+		{"Result", result},
+	}); err != nil {
+		return err
+	}
+
+	return resp.InstructionErr
+}
+
+// balanceOutput is the JSON output of the balance command.
+type balanceOutput struct {
+	Owner   string `json:"owner"`
+	Mint    string `json:"mint"`
+	Symbol  string `json:"symbol"`
+	Account string `json:"account"`
+	Amount  string `json:"amount"`
+	Balance string `json:"balance"`
+}
+
+func runBalance(ctx context.Context, e *env, args []string) error {
+	fs, c := newFlagSet(e, "balance")
+	owner := fs.String("owner", "", "public key of the owner, the wallet public key if empty")
+
+	if err := parse(fs, c, args, 0, 1); err != nil {
+		return err
+	}
+
+	ownerKey, err := c.owner(*owner)
+	if err != nil {
+		return err
+	}
+
+	out := balanceOutput{Owner: ownerKey.String()}
+
+	if fs.NArg() == 0 || strings.EqualFold(fs.Arg(0), "SOL") {
+		resp, err := c.rpcClient().GetBalance(ctx, ownerKey, rpc.CommitmentConfirmed)
+		if err != nil {
+			return fmt.Errorf("could not get balance: %w", err)
+		}
+
+		out.Mint = solana.SolMint.String()
+		out.Symbol = "SOL"
+		out.Account = ownerKey.String()
+		out.Amount = strconv.FormatUint(resp.Value, 10)
+		out.Balance = formatAmount(out.Amount, 9)
+	} else {
+		t, err := newTokens(c.rpcURL)
+		if err != nil {
+			return err
+		}
+
+		tok, err := t.resolve(ctx, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		account, err := associatedTokenAccount(ctx, c.rpcClient(), ownerKey, solana.MustPublicKeyFromBase58(tok.Mint))
+		if err != nil {
+			return err
+		}
+
+		solClient, err := jupSolana.NewWatchOnlyClient(ownerKey.String(), c.rpcURL)
+		if err != nil {
+			return fmt.Errorf("could not create solana client: %w", err)
+		}
+
+		balance, err := solClient.GetTokenAccountBalance(ctx, account.String())
+		if err != nil {
+			return err
+		}
+
+		out.Mint = tok.Mint
+		out.Symbol = tok.Symbol
+		out.Account = account.String()
+		out.Amount = balance.Amount.String()
+		out.Balance = formatAmount(out.Amount, balance.Decimals)
+	}
+
+	return render(e.stdout, c, out, nil, [][]string{
+		{"Owner", out.Owner},
+		{"Account", out.Account},
+		{"Balance", out.Balance + " " + out.Symbol},
+	})
+}
+
+// associatedTokenAccount returns the associated token account of the owner for the mint, derived with the token
+// program that owns the mint: SPL Token or Token-2022.
+func associatedTokenAccount(
+	ctx context.Context,
+	rpcClient *rpc.Client,
+	owner, mint solana.PublicKey,
+) (solana.PublicKey, error) {
+	mintAccount, err := rpcClient.GetAccountInfo(ctx, mint)
+	if err != nil {
+		return solana.PublicKey{}, fmt.Errorf("could not get mint %s: %w", mint, err)
+	}
+
+	tokenProgram := mintAccount.Value.Owner
+	if !tokenProgram.IsAnyOf(solana.TokenProgramID, solana.Token2022ProgramID) {
+		return solana.PublicKey{}, fmt.Errorf("mint %s is not owned by a token program: %s", mint, tokenProgram)
+	}
+
+	account, _, err := solana.FindProgramAddress(
+		[][]byte{owner[:], tokenProgram[:], mint[:]},
+		solana.SPLAssociatedTokenAccountProgramID,
+	)
+	if err != nil {
+		return solana.PublicKey{}, fmt.Errorf("could not find associated token account: %w", err)
+	}
+
+	return account, nil
+}
+
+// owner returns the given public key, or the wallet public key if empty.
+func (c *config) owner(publicKey string) (solana.PublicKey, error) {
+	if publicKey != "" {
+		key, err := solana.PublicKeyFromBase58(publicKey)
+		if err != nil {
+			return solana.PublicKey{}, fmt.Errorf("invalid owner: %w", err)
+		}
+
+		return key, nil
+	}
+
+	wallet, err := c.wallet()
+	if err != nil {
+		return solana.PublicKey{}, err
+	}
+
+	return wallet.PublicKey(), nil
+}
+
+func runLabels(ctx context.Context, e *env, args []string) error {
+	fs, c := newFlagSet(e, "labels")
+
+	if err := parse(fs, c, args, 0, 0); err != nil {
+		return err
+	}
+
+	jupClient, err := c.jupiterClient()
+	if err != nil {
+		return err
+	}
+
+	resp, err := jupClient.ProgramIdToLabelGetWithResponse(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get labels: %w", err)
+	}
+
+	if resp.JSON200 == nil {
+		return fmt.Errorf("could not get labels: %s: %s", resp.Status(), resp.Body)
+	}
+
+	labels := *resp.JSON200
+
+	programIDs := make([]string, 0, len(labels))
+	for programID := range labels {
+		programIDs = append(programIDs, programID)
+	}
+
+	sort.Slice(programIDs, func(i, j int) bool {
+		return labels[programIDs[i]] < labels[programIDs[j]]
+	})
+
+	rows := make([][]string, 0, len(programIDs))
+	for _, programID := range programIDs {
+		rows = append(rows, []string{labels[programID], programID})
+	}
+
+	return render(e.stdout, c, labels, []string{"LABEL", "PROGRAM"}, rows)
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"github.com/ilkamo/jupiter-go/jupiter"
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+)
+
+const (
+	envAPIURL     = "JUP_API_URL"
+	envAPIKey     = "JUP_API_KEY"
+	envRPCURL     = "SOLANA_RPC_URL"
+	envWSURL      = "SOLANA_WS_URL"
+	envKeypair    = "JUP_KEYPAIR"
+	envPrivateKey = "JUP_PRIVATE_KEY"
+	envKeystore   = "JUP_KEYSTORE"
+	envKey        = "JUP_KEY"
+	envPassphrase = "JUP_KEYSTORE_PASSPHRASE"
+
+	defaultRPCURL = "https://api.mainnet-beta.solana.com"
+)
+
+// config holds the flags shared by the commands.
+type config struct {
+	apiURL   string
+	apiKey   string
+	rpcURL   string
+	wsURL    string
+	keypair  string
+	keystore string
+	key      string
+	output   string
+
+	getenv func(string) string
+	stdin  io.Reader
+}
+
+// newFlagSet creates the flag set of a command with the shared flags, defaulting to the environment.
+func newFlagSet(e *env, name string) (*flag.FlagSet, *config) {
+	cmd := commands[name]
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.stdout)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stdout, "Usage: jup %s [flags] %s\n\n%s.\n\nFlags:\n", name, cmd.args, cmd.short)
+		fs.PrintDefaults()
+	}
+
+	c := &config{getenv: e.getenv, stdin: e.stdin}
+
+	fs.StringVar(&c.apiURL, "api-url", envOr(e.getenv, envAPIURL, jupiter.DefaultAPIURL), "Jupiter API URL")
+	fs.StringVar(&c.apiKey, "api-key", e.getenv(envAPIKey), "Jupiter API key")
+	fs.StringVar(&c.rpcURL, "rpc", envOr(e.getenv, envRPCURL, defaultRPCURL), "Solana RPC URL")
+	fs.StringVar(&c.wsURL, "ws", e.getenv(envWSURL), "Solana websocket URL, derived from the RPC URL if empty")
+	fs.StringVar(&c.keypair, "keypair", e.getenv(envKeypair),
+		"path of a Solana CLI keypair file, -key or "+envPrivateKey+" is used if empty")
+	fs.StringVar(&c.keystore, "keystore", envOr(e.getenv, envKeystore, defaultKeystoreDir()), "keystore directory")
+	fs.StringVar(&c.key, "key", e.getenv(envKey), "name of the keystore key to use, its passphrase is read from "+
+		envPassphrase+" or stdin")
+	fs.StringVar(&c.output, "output", "table", "output format: table or json")
+
+	return fs, c
+}
+
+// parse parses the flags and checks the number of positional arguments is between minArgs and maxArgs.
+func parse(fs *flag.FlagSet, c *config, args []string, minArgs, maxArgs int) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if c.output != "table" && c.output != "json" {
+		return fmt.Errorf("unknown output %q", c.output)
+	}
+
+	if fs.NArg() < minArgs || fs.NArg() > maxArgs {
+		fs.Usage()
+		return fmt.Errorf("%s: wrong number of arguments", fs.Name())
+	}
+
+	return nil
+}
+
+func envOr(getenv func(string) string, key, fallback string) string {
+	if v := getenv(key); v != "" {
+		return v
+	}
+
+	return fallback
+}
+
+func (c *config) jupiterClient() (*jupiter.ClientWithResponses, error) {
+	var opts []jupiter.ClientOption
+
+	if c.apiKey != "" {
+		opts = append(opts, jupiter.WithRequestEditorFn(func(_ context.Context, req *http.Request) error {
+			req.Header.Set("x-api-key", c.apiKey)
+			return nil
+		}))
+	}
+
+	client, err := jupiter.NewClientWithResponses(c.apiURL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not create jupiter client: %w", err)
+	}
+
+	return client, nil
+}
+
+func (c *config) rpcClient() *rpc.Client {
+	return rpc.New(c.rpcURL)
+}
+
+func (c *config) websocketURL() string {
+	if c.wsURL != "" {
+		return c.wsURL
+	}
+
+	if rest, ok := strings.CutPrefix(c.rpcURL, "https"); ok {
+		return "wss" + rest
+	}
+
+	return "ws" + strings.TrimPrefix(c.rpcURL, "http")
+}
+
+// wallet loads the keypair file, the keystore key or the private key of the environment.
+func (c *config) wallet() (jupSolana.Wallet, error) {
+	if c.keypair == "" && c.key != "" {
+		ks, err := c.openKeystore()
+		if err != nil {
+			return jupSolana.Wallet{}, err
+		}
+
+		passphrase, err := c.passphrase()
+		if err != nil {
+			return jupSolana.Wallet{}, err
+		}
+
+		return ks.Wallet(c.key, passphrase)
+	}
+
+	return c.plainWallet()
+}
+
+// plainWallet loads the keypair file or the private key of the environment.
+func (c *config) plainWallet() (jupSolana.Wallet, error) {
+	var (
+		wallet jupSolana.Wallet
+		err    error
+	)
+
+	switch {
+	case c.keypair != "":
+		wallet, err = jupSolana.NewWalletFromKeypairFile(c.keypair)
+	case c.getenv(envPrivateKey) != "":
+		wallet, err = jupSolana.NewWalletFromPrivateKeyBase58(c.getenv(envPrivateKey))
+	default:
+		return jupSolana.Wallet{}, fmt.Errorf("no key: set -keypair, -key, %s or %s", envKeypair, envPrivateKey)
+	}
+
+	if err != nil {
+		return jupSolana.Wallet{}, fmt.Errorf("could not load private key: %w", err)
+	}
+
+	return wallet, nil
+}
+
+func (c *config) solanaClient(wallet jupSolana.Wallet) (jupSolana.Client, error) {
+	client, err := jupSolana.NewClient(wallet, c.rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not create solana client: %w", err)
+	}
+
+	return client, nil
+}
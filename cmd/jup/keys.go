@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/ilkamo/jupiter-go/solana/keystore"
+)
+
+func defaultKeystoreDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "jup", "keystore")
+}
+
+func (c *config) openKeystore() (keystore.Keystore, error) {
+	if c.keystore == "" {
+		return nil, fmt.Errorf("no keystore: set -keystore or %s", envKeystore)
+	}
+
+	ks, err := keystore.NewKeystore(c.keystore)
+	if err != nil {
+		return nil, fmt.Errorf("could not open keystore: %w", err)
+	}
+
+	return ks, nil
+}
+
+// passphrase reads the keystore passphrase from the environment, from the terminal without echo,
+// or from the first line of stdin.
+func (c *config) passphrase() (string, error) {
+	if p := c.getenv(envPassphrase); p != "" {
+		return p, nil
+	}
+
+	if f, ok := c.stdin.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		fmt.Fprint(os.Stderr, "Passphrase: ")
+		defer fmt.Fprintln(os.Stderr)
+
+		p, err := term.ReadPassword(int(f.Fd()))
+		if err != nil {
+			return "", fmt.Errorf("could not read passphrase: %w", err)
+		}
+
+		return string(p), nil
+	}
+
+	line, err := bufio.NewReader(c.stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("could not read passphrase: set %s or write it to stdin", envPassphrase)
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func runKeys(_ context.Context, e *env, args []string) error {
+	fs, c := newFlagSet(e, "keys")
+
+	if err := parse(fs, c, args, 1, 2); err != nil {
+		return err
+	}
+
+	action, name := fs.Arg(0), fs.Arg(1)
+
+	if (action == "list") != (name == "") {
+		fs.Usage()
+		return fmt.Errorf("keys: wrong number of arguments")
+	}
+
+	ks, err := c.openKeystore()
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "list":
+		keys, err := ks.List()
+		if err != nil {
+			return err
+		}
+
+		rows := make([][]string, 0, len(keys))
+		for _, key := range keys {
+			rows = append(rows, []string{key.Name, key.PublicKey, key.CreatedAt.Format(time.RFC3339)})
+		}
+
+		return render(e.stdout, c, keys, []string{"NAME", "PUBLIC KEY", "CREATED"}, rows)
+	case "create", "import":
+		var key keystore.Key
+
+		passphrase, err := c.passphrase()
+		if err != nil {
+			return err
+		}
+
+		if action == "create" {
+			key, err = ks.Create(name, passphrase)
+		} else {
+			wallet, walletErr := c.plainWallet()
+			if walletErr != nil {
+				return walletErr
+			}
+
+			key, err = ks.Import(name, passphrase, wallet)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return render(e.stdout, c, key, nil, [][]string{
+			{"Name", key.Name},
+			{"Public key", key.PublicKey},
+			{"Path", key.Path},
+		})
+	case "export":
+		passphrase, err := c.passphrase()
+		if err != nil {
+			return err
+		}
+
+		keypair, err := ks.Export(name, passphrase)
+		if err != nil {
+			return err
+		}
+
+		defer clear(keypair)
+
+		_, err = fmt.Fprintf(e.stdout, "%s\n", keypair)
+
+		return err
+	}
+
+	return fmt.Errorf("unknown keys action %q: use list, create, import or export", action)
+}
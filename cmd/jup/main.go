@@ -0,0 +1,108 @@
+// Command jup gets Jupiter quotes, sends swaps and monitors transactions from the command line.
+//
+// Usage:
+//
+//	jup <command> [flags] [arguments]
+//
+// Run "jup help" for the list of commands and "jup <command> -h" for their flags.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+)
+
+type command struct {
+	args  string
+	short string
+	run   func(ctx context.Context, e *env, args []string) error
+}
+
+// commands is filled in init, as the commands refer to it for their usage.
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"quote":             {"IN OUT AMOUNT", "Get a quote to swap AMOUNT of IN into OUT", runQuote},
+		"swap":              {"IN OUT AMOUNT", "Quote, sign and send a swap", runSwap},
+		"swap-instructions": {"IN OUT AMOUNT", "Get the instructions of a swap", runSwapInstructions},
+		"send":              {"TX", "Sign and send a base64 transaction, read from stdin if TX is -", runSend},
+		"export":            {"IN OUT AMOUNT", "Save an unsigned swap to a bundle file to sign offline", runExport},
+		"sign":              {"BUNDLE", "Sign a bundle file, without network access", runSign},
+		"broadcast":         {"BUNDLE", "Send the signed transaction of a bundle file", runBroadcast},
+		"nonce":             {"create | show ACCOUNT | advance ACCOUNT", "Manage durable nonce accounts", runNonce},
+		"status":            {"SIGNATURE", "Get the status of a transaction", runStatus},
+		"watch":             {"SIGNATURE", "Wait for a transaction to reach a commitment", runWatch},
+		"balance":           {"[TOKEN]", "Get the SOL or TOKEN balance of the wallet", runBalance},
+		"labels":            {"", "List the DEX labels of the programs Jupiter routes through", runLabels},
+		"keys":              {"list | create NAME | import NAME | export NAME", "Manage the encrypted keystore", runKeys},
+	}
+}
+
+// env holds the process environment of a run, so that commands can be tested.
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	getenv func(string) string
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "jup:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, getenv func(string) string) error {
+	e := &env{stdin: stdin, stdout: stdout, getenv: getenv}
+
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stdout)
+		return nil
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q, run \"jup help\"", args[0])
+	}
+
+	return cmd.run(ctx, e, args[1:])
+}
+
+func usage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "Usage: jup <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-18s %s\n", name, commands[name].short)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Tokens are symbols such as SOL or USDC, or mint addresses. Amounts are decimal, e.g. 0.5.")
+	fmt.Fprintln(w, "Environment: "+strings.Join([]string{
+		envAPIURL, envAPIKey, envRPCURL, envWSURL, envKeypair, envPrivateKey, envKeystore, envKey, envPassphrase,
+	}, ", "))
+}
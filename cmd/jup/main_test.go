@@ -0,0 +1,616 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilkamo/jupiter-go/jupiter/aggregator"
+	"github.com/ilkamo/jupiter-go/jupiter/jupitertest"
+	"github.com/ilkamo/jupiter-go/offline"
+	"github.com/ilkamo/jupiter-go/solana/solanatest"
+	"github.com/ilkamo/jupiter-go/swap"
+)
+
+type testEnv struct {
+	jup    *jupitertest.Server
+	sol    *solanatest.Server
+	wallet solana.PrivateKey
+	vars   map[string]string
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	jup := jupitertest.NewServer()
+	t.Cleanup(jup.Close)
+
+	sol := solanatest.NewServer()
+	t.Cleanup(sol.Close)
+
+	wallet := solana.NewWallet().PrivateKey
+
+	keypair, err := json.Marshal([]byte(wallet))
+	require.NoError(t, err)
+
+	keypairPath := filepath.Join(t.TempDir(), "id.json")
+	require.NoError(t, os.WriteFile(keypairPath, keypair, 0o600))
+
+	return &testEnv{
+		jup:    jup,
+		sol:    sol,
+		wallet: wallet,
+		vars: map[string]string{
+			envAPIURL:   jup.URL(),
+			envRPCURL:   sol.URL(),
+			envWSURL:    sol.WSURL(),
+			envKeypair:  keypairPath,
+			envKeystore: filepath.Join(t.TempDir(), "keystore"),
+		},
+	}
+}
+
+func (te *testEnv) run(t *testing.T, stdin string, args ...string) (string, error) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var stdout bytes.Buffer
+
+	err := run(ctx, args, strings.NewReader(stdin), &stdout, func(key string) string {
+		return te.vars[key]
+	})
+
+	return stdout.String(), err
+}
+
+func TestRun(t *testing.T) {
+	te := newTestEnv(t)
+
+	t.Run("usage", func(t *testing.T) {
+		out, err := te.run(t, "")
+		require.NoError(t, err)
+		require.Contains(t, out, "swap-instructions")
+
+		_, err = te.run(t, "", "unknown")
+		require.EqualError(t, err, `unknown command "unknown", run "jup help"`)
+	})
+
+	t.Run("wrong number of arguments", func(t *testing.T) {
+		out, err := te.run(t, "", "quote", "SOL", "JUP")
+		require.EqualError(t, err, "quote: wrong number of arguments")
+		require.Contains(t, out, "Usage: jup quote [flags] IN OUT AMOUNT")
+	})
+
+	t.Run("unknown output", func(t *testing.T) {
+		_, err := te.run(t, "", "labels", "-output", "xml")
+		require.EqualError(t, err, `unknown output "xml"`)
+	})
+
+	t.Run("no key", func(t *testing.T) {
+		noKey := &testEnv{vars: map[string]string{envRPCURL: te.sol.URL()}}
+
+		_, err := noKey.run(t, "", "balance")
+		require.EqualError(t, err, "no key: set -keypair, -key, JUP_KEYPAIR or JUP_PRIVATE_KEY")
+	})
+}
+
+func TestQuote(t *testing.T) {
+	te := newTestEnv(t)
+
+	t.Run("table", func(t *testing.T) {
+		out, err := te.run(t, "", "quote", "-slippage-bps", "250", "-max-accounts", "20", "sol", "JUP", "0.0001")
+		require.NoError(t, err)
+		require.Contains(t, out, "0.0001 SOL")
+		require.Contains(t, out, "0.024266 JUP")
+		require.Contains(t, out, "0.023659 JUP")
+
+		params, err := te.jup.RequestsTo(jupitertest.PathQuote)[0].QuoteGetParams()
+		require.NoError(t, err)
+		require.Equal(t, uint64(100000), params.Amount)
+		require.Equal(t, uint64(250), *params.SlippageBps)
+		require.Equal(t, uint64(20), *params.MaxAccounts)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		out, err := te.run(t, "", "quote", "-output", "json", "SOL", "JUP", "0.0001")
+		require.NoError(t, err)
+
+		var quote map[string]any
+		require.NoError(t, json.Unmarshal([]byte(out), &quote))
+		require.Equal(t, "24266", quote["outAmount"])
+	})
+
+	t.Run("no route", func(t *testing.T) {
+		te.jup.Fail(jupitertest.PathQuote, jupitertest.NoRoute())
+
+		_, err := te.run(t, "", "quote", "SOL", "JUP", "1")
+		require.ErrorContains(t, err, "could not get quote: 400 Bad Request")
+	})
+
+	t.Run("invalid slippage", func(t *testing.T) {
+		requests := len(te.jup.RequestsTo(jupitertest.PathQuote))
+
+		_, err := te.run(t, "", "quote", "-slippage-bps", "20000", "SOL", "JUP", "1")
+		require.EqualError(t, err, "could not get quote: invalid request: slippageBps: 20000 bps is above 10000")
+		require.Len(t, te.jup.RequestsTo(jupitertest.PathQuote), requests)
+	})
+
+	t.Run("invalid amount", func(t *testing.T) {
+		_, err := te.run(t, "", "quote", "SOL", "JUP", "0.0000000001")
+		require.EqualError(t, err, `amount "0.0000000001" must be positive with at most 9 decimals`)
+	})
+}
+
+func TestSwap(t *testing.T) {
+	te := newTestEnv(t)
+
+	t.Run("send", func(t *testing.T) {
+		out, err := te.run(t, "", "swap", "-verify=false", "SOL", "JUP", "0.0001")
+		require.NoError(t, err)
+
+		txs := te.sol.Transactions()
+		require.Len(t, txs, 1)
+		require.Contains(t, out, txs[0].Signatures[0].String())
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		te.sol.SetSimulation(solanatest.Simulation{
+			Logs:          []string{"Program JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4 success"},
+			UnitsConsumed: 85000,
+		})
+
+		out, err := te.run(t, "", "swap", "-verify=false", "-dry-run", "-output", "json", "SOL", "JUP", "0.0001")
+		require.NoError(t, err)
+		require.Len(t, te.sol.Transactions(), 1)
+
+		var res swapOutput
+		require.NoError(t, json.Unmarshal([]byte(out), &res))
+		require.NotEmpty(t, res.Signature)
+		require.Equal(t, "24266", res.Quote.OutAmount)
+		require.Equal(t, uint64(85000), *res.Simulation.UnitsConsumed)
+
+		out, err = te.run(t, "", "swap", "-verify=false", "-dry-run", "SOL", "JUP", "0.0001")
+		require.NoError(t, err)
+		require.Contains(t, out, "Simulated signature")
+	})
+
+	t.Run("dry run failure", func(t *testing.T) {
+		te.sol.SetSimulation(solanatest.Simulation{Err: solanatest.InstructionError(3, 6001)})
+
+		_, err := te.run(t, "", "swap", "-verify=false", "-dry-run", "SOL", "JUP", "0.0001")
+		require.ErrorContains(t, err, "simulation failed")
+	})
+
+	t.Run("verification fails for a transaction of another wallet", func(t *testing.T) {
+		_, err := te.run(t, "", "swap", "SOL", "JUP", "0.0001")
+		require.ErrorContains(t, err, "fee payer is not")
+		require.Len(t, te.sol.Transactions(), 1)
+	})
+}
+
+func TestSwapInstructions(t *testing.T) {
+	te := newTestEnv(t)
+
+	user := solana.NewWallet().PublicKey().String()
+
+	out, err := te.run(t, "", "swap-instructions", "-user", user, "SOL", "JUP", "0.0001")
+	require.NoError(t, err)
+	require.Contains(t, out, "JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4")
+	require.Contains(t, out, "compute budget")
+
+	req, err := te.jup.RequestsTo(jupitertest.PathSwapInstructions)[0].SwapRequest()
+	require.NoError(t, err)
+	require.Equal(t, user, req.UserPublicKey)
+}
+
+func TestSend(t *testing.T) {
+	te := newTestEnv(t)
+
+	swapTx := jupitertest.DefaultFixtures().Swap.SwapTransaction
+
+	out, err := te.run(t, swapTx+"\n", "send", "-")
+	require.NoError(t, err)
+
+	txs := te.sol.Transactions()
+	require.Len(t, txs, 1)
+	require.Contains(t, out, txs[0].Signatures[0].String())
+
+	_, err = te.run(t, "", "send", "not a transaction")
+	require.ErrorContains(t, err, "could not deserialize swap transaction")
+}
+
+func TestStatusAndWatch(t *testing.T) {
+	te := newTestEnv(t)
+
+	sig := solana.Signature{1, 2, 3}
+
+	t.Run("not found", func(t *testing.T) {
+		out, err := te.run(t, "", "status", sig.String())
+		require.NoError(t, err)
+		require.Contains(t, out, "not found")
+	})
+
+	te.sol.SetSignatureStatus(sig, rpc.ConfirmationStatusConfirmed, nil)
+
+	t.Run("status", func(t *testing.T) {
+		out, err := te.run(t, "", "status", "-output", "json", sig.String())
+		require.NoError(t, err)
+
+		var status statusOutput
+		require.NoError(t, json.Unmarshal([]byte(out), &status))
+		require.True(t, status.Found)
+		require.Equal(t, "confirmed", status.Commitment)
+		require.Equal(t, te.sol.Slot(), status.Slot)
+	})
+
+	t.Run("watch", func(t *testing.T) {
+		out, err := te.run(t, "", "watch", "-commitment", "confirmed", sig.String())
+		require.NoError(t, err)
+		require.Contains(t, out, "ok")
+
+		_, err = te.run(t, "", "watch", "-commitment", "soon", sig.String())
+		require.EqualError(t, err, `unknown commitment "soon"`)
+	})
+
+	t.Run("watch failed transaction", func(t *testing.T) {
+		failed := solana.Signature{4, 5, 6}
+		te.sol.SetSignatureStatus(failed, rpc.ConfirmationStatusFinalized, solanatest.InstructionError(0, 1))
+
+		_, err := te.run(t, "", "watch", failed.String())
+		require.ErrorContains(t, err, "transaction confirmed with error")
+	})
+}
+
+func TestBalance(t *testing.T) {
+	te := newTestEnv(t)
+
+	owner := te.wallet.PublicKey()
+	te.sol.SetBalance(owner, 1_500_000_000)
+
+	t.Run("sol", func(t *testing.T) {
+		out, err := te.run(t, "", "balance")
+		require.NoError(t, err)
+		require.Contains(t, out, "1.5 SOL")
+	})
+
+	t.Run("token", func(t *testing.T) {
+		mint := solana.MustPublicKeyFromBase58("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+		te.sol.SetAccount(mint, solanatest.Account{Owner: solana.TokenProgramID})
+
+		account, _, err := solana.FindAssociatedTokenAddress(owner, mint)
+		require.NoError(t, err)
+
+		te.sol.SetTokenBalance(account, solanatest.TokenBalance{Amount: 12_340_000, Decimals: 6})
+
+		out, err := te.run(t, "", "balance", "-output", "json", "USDC")
+		require.NoError(t, err)
+
+		var balance balanceOutput
+		require.NoError(t, json.Unmarshal([]byte(out), &balance))
+		require.Equal(t, account.String(), balance.Account)
+		require.Equal(t, "12.34", balance.Balance)
+	})
+
+	t.Run("token-2022", func(t *testing.T) {
+		// The mint is moved to Token-2022 for the test.
+		mint := solana.MustPublicKeyFromBase58("JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN")
+		te.sol.SetAccount(mint, solanatest.Account{Owner: solana.Token2022ProgramID})
+
+		account, _, err := solana.FindProgramAddress(
+			[][]byte{owner[:], solana.Token2022ProgramID[:], mint[:]},
+			solana.SPLAssociatedTokenAccountProgramID,
+		)
+		require.NoError(t, err)
+
+		te.sol.SetTokenBalance(account, solanatest.TokenBalance{Amount: 5_000_000, Decimals: 6})
+
+		out, err := te.run(t, "", "balance", "-output", "json", "JUP")
+		require.NoError(t, err)
+
+		var balance balanceOutput
+		require.NoError(t, json.Unmarshal([]byte(out), &balance))
+		require.Equal(t, account.String(), balance.Account)
+		require.Equal(t, "5", balance.Balance)
+	})
+
+	t.Run("mint not owned by a token program", func(t *testing.T) {
+		te.sol.SetBalance(solana.MustPublicKeyFromBase58("DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263"), 1)
+
+		_, err := te.run(t, "", "balance", "BONK")
+		require.ErrorContains(t, err, "is not owned by a token program")
+	})
+
+	t.Run("other owner", func(t *testing.T) {
+		other := solana.NewWallet().PublicKey()
+		te.sol.SetBalance(other, 1)
+
+		out, err := te.run(t, "", "balance", "-owner", other.String(), "SOL")
+		require.NoError(t, err)
+		require.Contains(t, out, "0.000000001 SOL")
+	})
+}
+
+func TestLabels(t *testing.T) {
+	te := newTestEnv(t)
+
+	out, err := te.run(t, "", "labels")
+	require.NoError(t, err)
+	require.Contains(t, out, "LABEL")
+	require.Contains(t, out, "Meteora DLMM")
+}
+
+func TestKeys(t *testing.T) {
+	te := newTestEnv(t)
+
+	t.Run("import the keypair", func(t *testing.T) {
+		out, err := te.run(t, "secret\n", "keys", "import", "ops")
+		require.NoError(t, err)
+		require.Contains(t, out, te.wallet.PublicKey().String())
+	})
+
+	t.Run("create with the passphrase of the environment", func(t *testing.T) {
+		te.vars[envPassphrase] = "other secret"
+		defer delete(te.vars, envPassphrase)
+
+		_, err := te.run(t, "", "keys", "create", "bot")
+		require.NoError(t, err)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		out, err := te.run(t, "", "keys", "-output", "json", "list")
+		require.NoError(t, err)
+
+		var keys []map[string]any
+		require.NoError(t, json.Unmarshal([]byte(out), &keys))
+		require.Len(t, keys, 2)
+		require.Equal(t, "bot", keys[0]["name"])
+		require.Equal(t, te.wallet.PublicKey().String(), keys[1]["publicKey"])
+	})
+
+	t.Run("export", func(t *testing.T) {
+		out, err := te.run(t, "secret\n", "keys", "export", "ops")
+		require.NoError(t, err)
+
+		var keypair []byte
+		require.NoError(t, json.Unmarshal([]byte(out), &keypair))
+		require.Equal(t, []byte(te.wallet), keypair)
+	})
+
+	t.Run("use a keystore key", func(t *testing.T) {
+		keystoreOnly := &testEnv{vars: map[string]string{
+			envRPCURL:   te.sol.URL(),
+			envKeystore: te.vars[envKeystore],
+			envKey:      "ops",
+		}}
+		te.sol.SetBalance(te.wallet.PublicKey(), 2_000_000_000)
+
+		out, err := keystoreOnly.run(t, "secret\n", "balance")
+		require.NoError(t, err)
+		require.Contains(t, out, "2 SOL")
+
+		_, err = keystoreOnly.run(t, "wrong\n", "balance")
+		require.ErrorContains(t, err, "wrong passphrase")
+	})
+
+	t.Run("wrong arguments", func(t *testing.T) {
+		_, err := te.run(t, "", "keys", "list", "ops")
+		require.EqualError(t, err, "keys: wrong number of arguments")
+
+		_, err = te.run(t, "", "keys", "remove", "ops")
+		require.EqualError(t, err, `unknown keys action "remove": use list, create, import or export`)
+	})
+}
+
+func TestOffline(t *testing.T) {
+	te := newTestEnv(t)
+	// The verifier derives the destination token account of the swap with the token program of JUP.
+	te.sol.SetAccount(solana.MustPublicKeyFromBase58("JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN"), solanatest.Account{
+		Owner: solana.TokenProgramID,
+	})
+	path := filepath.Join(t.TempDir(), "bundle.json")
+
+	t.Run("export a swap of a cold wallet", func(t *testing.T) {
+		out, err := te.run(t, "", "export", "-user", "BXzmfHxfEMcMj8hDccUNdrwXVNeybyfb2iV2nktE1VnJ",
+			"-out", path, "SOL", "JUP", "0.0001")
+		require.NoError(t, err)
+		require.Contains(t, out, "0.024266 JUP")
+		require.Contains(t, out, "BXzmfHxfEMcMj8hDccUNdrwXVNeybyfb2iV2nktE1VnJ (missing)")
+
+		_, err = te.run(t, "", "sign", path)
+		require.ErrorContains(t, err, "is not a signer of the transaction")
+	})
+
+	t.Run("export verifies the swap", func(t *testing.T) {
+		_, err := te.run(t, "", "export", "-out", path, "SOL", "JUP", "0.0001")
+		require.ErrorContains(t, err, "fee payer is not")
+	})
+
+	// The swap transaction is replaced by one of the test wallet, which it can sign.
+	setSwapTransaction := func(t *testing.T, instructions ...solana.Instruction) {
+		blockhash, _ := te.sol.Blockhash()
+
+		tx, err := solana.NewTransaction(instructions, blockhash, solana.TransactionPayer(te.wallet.PublicKey()))
+		require.NoError(t, err)
+
+		fixtures := jupitertest.DefaultFixtures()
+		fixtures.Swap.SwapTransaction, err = tx.ToBase64()
+		require.NoError(t, err)
+		te.jup.SetFixtures(fixtures)
+	}
+
+	transfer := system.NewTransferInstruction(1000, te.wallet.PublicKey(), solana.NewWallet().PublicKey()).Build()
+
+	t.Run("sign refuses a transaction not matching the quote", func(t *testing.T) {
+		setSwapTransaction(t, transfer)
+
+		_, err := te.run(t, "", "export", "-verify=false", "-out", path, "SOL", "JUP", "0.0001")
+		require.NoError(t, err)
+
+		offlineEnv := &testEnv{vars: map[string]string{envKeypair: te.vars[envKeypair]}}
+
+		_, err = offlineEnv.run(t, "", "sign", path)
+		require.ErrorIs(t, err, offline.ErrQuoteMismatch)
+	})
+
+	setSwapTransaction(t, transfer, testRouteInstruction(t))
+
+	t.Run("broadcast needs the signatures", func(t *testing.T) {
+		_, err := te.run(t, "", "export", "-verify=false", "-out", path, "SOL", "JUP", "0.0001")
+		require.NoError(t, err)
+
+		_, err = te.run(t, "", "broadcast", path)
+		require.ErrorContains(t, err, "missing signatures")
+		require.Empty(t, te.sol.Transactions())
+	})
+
+	t.Run("sign offline and broadcast", func(t *testing.T) {
+		offlineEnv := &testEnv{vars: map[string]string{envKeypair: te.vars[envKeypair]}}
+		signedPath := filepath.Join(t.TempDir(), "signed.json")
+
+		out, err := offlineEnv.run(t, "", "sign", "-out", signedPath, path)
+		require.NoError(t, err)
+		require.Contains(t, out, te.wallet.PublicKey().String()+" (signed)")
+
+		out, err = te.run(t, "", "broadcast", signedPath)
+		require.NoError(t, err)
+
+		txs := te.sol.Transactions()
+		require.Len(t, txs, 1)
+		require.Contains(t, out, txs[0].Signatures[0].String())
+		require.NoError(t, txs[0].VerifySignatures())
+	})
+}
+
+// testRouteInstruction copies the route instruction of the fixture swap, with accounts that need no signature.
+func testRouteInstruction(t *testing.T) solana.Instruction {
+	t.Helper()
+
+	tx, err := solana.TransactionFromBase64(jupitertest.DefaultFixtures().Swap.SwapTransaction)
+	require.NoError(t, err)
+
+	for _, ix := range tx.Message.Instructions {
+		if !tx.Message.AccountKeys[ix.ProgramIDIndex].Equals(aggregator.ProgramID) {
+			continue
+		}
+
+		accounts := make(solana.AccountMetaSlice, len(ix.Accounts))
+		for i := range accounts {
+			accounts[i] = solana.Meta(solana.NewWallet().PublicKey())
+		}
+
+		return solana.NewInstruction(aggregator.ProgramID, accounts, ix.Data)
+	}
+
+	require.FailNow(t, "no route instruction in the fixture swap")
+
+	return nil
+}
+
+func TestNonce(t *testing.T) {
+	te := newTestEnv(t)
+
+	t.Run("create", func(t *testing.T) {
+		out, err := te.run(t, "", "nonce", "-output", "json", "create")
+		require.NoError(t, err)
+
+		var res nonceOutput
+		require.NoError(t, json.Unmarshal([]byte(out), &res))
+
+		txs := te.sol.Transactions()
+		require.Len(t, txs, 1)
+		require.Equal(t, txs[0].Signatures[0].String(), res.Signature)
+		require.Equal(t, solana.PublicKeySlice{te.wallet.PublicKey(), solana.MustPublicKeyFromBase58(res.Address)},
+			txs[0].Message.Signers())
+		require.NoError(t, txs[0].VerifySignatures())
+	})
+
+	// The nonce account of the fixture wallet, which signs the fixture swap.
+	nonceAccount := solana.NewWallet().PublicKey()
+	nonce := solana.Hash{7}
+
+	var data bytes.Buffer
+	require.NoError(t, bin.NewBinEncoder(&data).Encode(system.NonceAccount{
+		State:            1,
+		AuthorizedPubkey: solana.MustPublicKeyFromBase58("BXzmfHxfEMcMj8hDccUNdrwXVNeybyfb2iV2nktE1VnJ"),
+		Nonce:            solana.PublicKey(nonce),
+	}))
+	te.sol.SetAccount(nonceAccount, solanatest.Account{
+		Lamports: 1_447_680,
+		Owner:    solana.SystemProgramID,
+		Data:     data.Bytes(),
+	})
+
+	// The verifier derives the destination token account of the swap with the token program of JUP.
+	te.sol.SetAccount(solana.MustPublicKeyFromBase58("JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN"), solanatest.Account{
+		Owner: solana.TokenProgramID,
+	})
+
+	t.Run("show", func(t *testing.T) {
+		out, err := te.run(t, "", "nonce", "show", nonceAccount.String())
+		require.NoError(t, err)
+		require.Contains(t, out, nonce.String())
+		require.Contains(t, out, "BXzmfHxfEMcMj8hDccUNdrwXVNeybyfb2iV2nktE1VnJ")
+	})
+
+	t.Run("advance", func(t *testing.T) {
+		_, err := te.run(t, "", "nonce", "advance", nonceAccount.String())
+		require.NoError(t, err)
+		require.Len(t, te.sol.Transactions(), 2)
+	})
+
+	t.Run("export a swap with a durable nonce", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "bundle.json")
+
+		out, err := te.run(t, "", "export", "-user", "BXzmfHxfEMcMj8hDccUNdrwXVNeybyfb2iV2nktE1VnJ",
+			"-nonce", nonceAccount.String(), "-out", path, "SOL", "JUP", "0.0001")
+		require.NoError(t, err)
+		require.Contains(t, out, "Nonce account")
+
+		b, err := offline.Load(path)
+		require.NoError(t, err)
+		require.Equal(t, nonceAccount.String(), b.NonceAccount)
+		require.Equal(t, nonce.String(), b.Blockhash)
+	})
+
+	t.Run("export verifies the swap with the durable nonce", func(t *testing.T) {
+		foreignNonce := solana.NewWallet().PublicKey()
+
+		var data bytes.Buffer
+		require.NoError(t, bin.NewBinEncoder(&data).Encode(system.NonceAccount{
+			State:            1,
+			AuthorizedPubkey: solana.NewWallet().PublicKey(),
+			Nonce:            solana.PublicKey(nonce),
+		}))
+		te.sol.SetAccount(foreignNonce, solanatest.Account{
+			Lamports: 1_447_680,
+			Owner:    solana.SystemProgramID,
+			Data:     data.Bytes(),
+		})
+
+		_, err := te.run(t, "", "export", "-user", "BXzmfHxfEMcMj8hDccUNdrwXVNeybyfb2iV2nktE1VnJ",
+			"-nonce", foreignNonce.String(), "-out", filepath.Join(t.TempDir(), "bundle.json"), "SOL", "JUP", "0.0001")
+		require.ErrorIs(t, err, swap.ErrVerificationFailed)
+		require.ErrorContains(t, err, "unexpected signer")
+	})
+
+	t.Run("wrong arguments", func(t *testing.T) {
+		_, err := te.run(t, "", "nonce", "show")
+		require.EqualError(t, err, "nonce: wrong number of arguments")
+
+		_, err = te.run(t, "", "nonce", "withdraw", nonceAccount.String())
+		require.EqualError(t, err, `unknown nonce action "withdraw": use create, show or advance`)
+	})
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/gagliardetto/solana-go"
+
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+)
+
+// nonceOutput is the JSON output of the nonce command.
+type nonceOutput struct {
+	Address              string `json:"address"`
+	Authority            string `json:"authority,omitempty"`
+	Nonce                string `json:"nonce,omitempty"`
+	LamportsPerSignature uint64 `json:"lamportsPerSignature,omitempty"`
+	Signature            string `json:"signature,omitempty"`
+}
+
+func runNonce(ctx context.Context, e *env, args []string) error {
+	fs, c := newFlagSet(e, "nonce")
+
+	if err := parse(fs, c, args, 1, 2); err != nil {
+		return err
+	}
+
+	action, account := fs.Arg(0), fs.Arg(1)
+
+	if (action == "create") != (account == "") {
+		fs.Usage()
+		return fmt.Errorf("nonce: wrong number of arguments")
+	}
+
+	var nonceAccount solana.PublicKey
+
+	if account != "" {
+		pk, err := solana.PublicKeyFromBase58(account)
+		if err != nil {
+			return fmt.Errorf("invalid nonce account: %w", err)
+		}
+
+		nonceAccount = pk
+	}
+
+	switch action {
+	case "show":
+		// Reading the account needs no key.
+		watcher, err := jupSolana.NewWatchOnlyClient(account, c.rpcURL)
+		if err != nil {
+			return fmt.Errorf("could not create solana client: %w", err)
+		}
+
+		nonce, err := watcher.GetNonceAccount(ctx, nonceAccount)
+		if err != nil {
+			return err
+		}
+
+		out := nonceOutput{
+			Address:              nonce.Address.String(),
+			Authority:            nonce.Authority.String(),
+			Nonce:                nonce.Nonce.String(),
+			LamportsPerSignature: nonce.LamportsPerSignature,
+		}
+
+		return render(e.stdout, c, out, nil, [][]string{
+			{"Address", out.Address},
+			{"Authority", out.Authority},
+			{"Nonce", out.Nonce},
+			{"Lamports per signature", strconv.FormatUint(out.LamportsPerSignature, 10)},
+		})
+	case "create", "advance":
+		wallet, err := c.wallet()
+		if err != nil {
+			return err
+		}
+
+		nonceClient, err := jupSolana.NewNonceClient(wallet, c.rpcURL)
+		if err != nil {
+			return fmt.Errorf("could not create solana client: %w", err)
+		}
+
+		var txID jupSolana.TxID
+
+		if action == "create" {
+			nonceAccount, txID, err = nonceClient.CreateNonceAccount(ctx)
+		} else {
+			txID, err = nonceClient.AdvanceNonceAccount(ctx, nonceAccount)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		out := nonceOutput{Address: nonceAccount.String(), Signature: string(txID)}
+
+		return render(e.stdout, c, out, nil, [][]string{
+			{"Address", out.Address},
+			{"Signature", out.Signature},
+		})
+	}
+
+	return fmt.Errorf("unknown nonce action %q: use create, show or advance", action)
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/gagliardetto/solana-go"
+
+	"github.com/ilkamo/jupiter-go/jupiter"
+	"github.com/ilkamo/jupiter-go/offline"
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+	"github.com/ilkamo/jupiter-go/swap"
+)
+
+func runExport(ctx context.Context, e *env, args []string) error {
+	fs, c := newFlagSet(e, "export")
+	q := addQuoteFlags(fs)
+	user := fs.String("user", "", "public key of the signing wallet, the wallet public key if empty")
+	verify := fs.Bool("verify", true, "verify the swap transaction against the quote before exporting it")
+	out := fs.String("out", "swap.json", "path of the bundle file to write")
+	nonce := fs.String("nonce", "", "nonce account of the signing wallet, so that the transaction does not expire")
+
+	if err := parse(fs, c, args, 3, 3); err != nil {
+		return err
+	}
+
+	userPublicKey := *user
+	if userPublicKey == "" {
+		wallet, err := c.wallet()
+		if err != nil {
+			return err
+		}
+
+		userPublicKey = wallet.PublicKey().String()
+	}
+
+	params, err := q.params(ctx, c, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	jupClient, err := c.jupiterClient()
+	if err != nil {
+		return err
+	}
+
+	quote, err := getQuote(ctx, jupClient, params)
+	if err != nil {
+		return err
+	}
+
+	resp, err := jupClient.SwapPostWithResponse(ctx, jupiter.SwapRequest{
+		QuoteResponse: quote,
+		UserPublicKey: userPublicKey,
+	})
+	if err != nil {
+		return fmt.Errorf("could not get swap transaction: %w", err)
+	}
+
+	if resp.JSON200 == nil {
+		return fmt.Errorf("could not get swap transaction: %s: %s", resp.Status(), resp.Body)
+	}
+
+	swapResp := *resp.JSON200
+
+	if *nonce != "" {
+		nonceAccount, err := solana.PublicKeyFromBase58(*nonce)
+		if err != nil {
+			return fmt.Errorf("invalid nonce account: %w", err)
+		}
+
+		watcher, err := jupSolana.NewWatchOnlyClient(userPublicKey, c.rpcURL)
+		if err != nil {
+			return fmt.Errorf("could not create solana client: %w", err)
+		}
+
+		swapResp.SwapTransaction, err = watcher.UseDurableNonce(ctx, swapResp.SwapTransaction, nonceAccount)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The transaction is verified as it will be signed, after the durable nonce is set.
+	if *verify {
+		verifier, err := swap.NewVerifier(userPublicKey, c.rpcURL)
+		if err != nil {
+			return fmt.Errorf("could not create verifier: %w", err)
+		}
+
+		tx, err := jupSolana.NewTransactionFromBase64(swapResp.SwapTransaction)
+		if err != nil {
+			return fmt.Errorf("could not deserialize transaction: %w", err)
+		}
+
+		if err := verifier.Verify(ctx, tx, quote); err != nil {
+			return fmt.Errorf("could not verify transaction: %w", err)
+		}
+	}
+
+	b, err := offline.NewSwapBundle(quote, swapResp)
+	if err != nil {
+		return err
+	}
+
+	if err := b.Save(*out); err != nil {
+		return err
+	}
+
+	return printBundle(e, c, *out, b)
+}
+
+func runSign(_ context.Context, e *env, args []string) error {
+	fs, c := newFlagSet(e, "sign")
+	out := fs.String("out", "", "path of the signed bundle file, the bundle file if empty")
+
+	if err := parse(fs, c, args, 1, 1); err != nil {
+		return err
+	}
+
+	b, err := offline.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	wallet, err := c.wallet()
+	if err != nil {
+		return err
+	}
+
+	signed, err := b.Sign(wallet)
+	if err != nil {
+		return err
+	}
+
+	path := *out
+	if path == "" {
+		path = fs.Arg(0)
+	}
+
+	if err := signed.Save(path); err != nil {
+		return err
+	}
+
+	return printBundle(e, c, path, signed)
+}
+
+func runBroadcast(ctx context.Context, e *env, args []string) error {
+	fs, c := newFlagSet(e, "broadcast")
+
+	if err := parse(fs, c, args, 1, 1); err != nil {
+		return err
+	}
+
+	b, err := offline.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if len(b.Signers) == 0 {
+		return fmt.Errorf("bundle %s has no signers", fs.Arg(0))
+	}
+
+	// The fee payer is watched only: the transaction is already signed.
+	solClient, err := jupSolana.NewWatchOnlyClient(b.Signers[0], c.rpcURL)
+	if err != nil {
+		return fmt.Errorf("could not create solana client: %w", err)
+	}
+
+	txID, err := b.Broadcast(ctx, solClient)
+	if err != nil {
+		return err
+	}
+
+	return printSent(e.stdout, c, swapOutput{Quote: b.Quote, Signature: string(txID)})
+}
+
+// printBundle prints what the bundle swaps and who signed it, resolving known tokens only as signing is offline.
+func printBundle(e *env, c *config, path string, b offline.Bundle) error {
+	rows := [][]string{{"Bundle", path}}
+
+	if b.Quote != nil {
+		rows = append(rows,
+			[]string{"In", offlineAmount(b.Quote.InAmount, b.Quote.InputMint)},
+			[]string{"Out", offlineAmount(b.Quote.OutAmount, b.Quote.OutputMint)},
+			[]string{"Slippage", strconv.FormatUint(b.Quote.SlippageBps, 10) + " bps"},
+		)
+	}
+
+	if b.NonceAccount != "" {
+		rows = append(rows,
+			[]string{"Nonce account", b.NonceAccount},
+			[]string{"Nonce", b.Blockhash},
+		)
+	} else {
+		rows = append(rows,
+			[]string{"Blockhash", b.Blockhash},
+			[]string{"Last valid block height", strconv.FormatUint(b.LastValidBlockHeight, 10)},
+		)
+	}
+
+	for _, signer := range b.Signers {
+		status := "missing"
+		if _, ok := b.Signatures[signer]; ok {
+			status = "signed"
+		}
+
+		rows = append(rows, []string{"Signer", signer + " (" + status + ")"})
+	}
+
+	return render(e.stdout, c, b, nil, rows)
+}
+
+// offlineAmount formats an amount in the decimals of a known token, or as raw units of the mint.
+func offlineAmount(raw, mint string) string {
+	for _, known := range knownTokens {
+		if known.Mint == mint {
+			return formatAmount(raw, known.Decimals) + " " + known.Symbol
+		}
+	}
+
+	return raw + " " + shortMint(mint)
+}
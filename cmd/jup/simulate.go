@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+)
+
+// simulator is a solana client that simulates transactions instead of sending them, for -dry-run.
+type simulator struct {
+	jupSolana.Client
+	rpcClient *rpc.Client
+	wallet    jupSolana.Wallet
+	result    *rpc.SimulateTransactionResult
+}
+
+// SendTransactionOnChain signs and simulates the transaction, with the latest blockhash, and returns its signature.
+func (s *simulator) SendTransactionOnChain(ctx context.Context, txBase64 string) (jupSolana.TxID, error) {
+	tx, err := jupSolana.NewTransactionFromBase64(txBase64)
+	if err != nil {
+		return "", fmt.Errorf("could not deserialize transaction: %w", err)
+	}
+
+	res, err := s.SendTransaction(ctx, &tx, jupSolana.SendOptions{})
+	return res.TxID, err
+}
+
+// SendTransaction signs and simulates the transaction, with the latest blockhash, and returns its signature.
+// The send options do not apply to a simulation.
+func (s *simulator) SendTransaction(
+	ctx context.Context,
+	tx *solana.Transaction,
+	_ jupSolana.SendOptions,
+) (jupSolana.SendResult, error) {
+	signed, err := s.wallet.SignTransaction(*tx)
+	if err != nil {
+		return jupSolana.SendResult{}, fmt.Errorf("could not sign transaction: %w", err)
+	}
+
+	resp, err := s.rpcClient.SimulateTransactionWithOpts(ctx, &signed, &rpc.SimulateTransactionOpts{
+		Commitment:             rpc.CommitmentProcessed,
+		ReplaceRecentBlockhash: true,
+	})
+	if err != nil {
+		return jupSolana.SendResult{}, fmt.Errorf("could not simulate transaction: %w", err)
+	}
+
+	if resp.Value == nil {
+		return jupSolana.SendResult{}, fmt.Errorf("could not simulate transaction: empty response")
+	}
+
+	s.result = resp.Value
+
+	if resp.Value.Err != nil {
+		return jupSolana.SendResult{}, fmt.Errorf("simulation failed: %v", resp.Value.Err)
+	}
+
+	return jupSolana.SendResult{TxID: jupSolana.TxID(signed.Signatures[0].String())}, nil
+}
+
+// simulation is the output of a dry run.
+type simulation struct {
+	Err           any      `json:"err"`
+	Logs          []string `json:"logs"`
+	UnitsConsumed *uint64  `json:"unitsConsumed,omitempty"`
+}
+
+func (s *simulator) simulation() *simulation {
+	if s.result == nil {
+		return nil
+	}
+
+	return &simulation{Err: s.result.Err, Logs: s.result.Logs, UnitsConsumed: s.result.UnitsConsumed}
+}
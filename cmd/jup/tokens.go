@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/shopspring/decimal"
+
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+)
+
+type token struct {
+	Symbol   string
+	Mint     string
+	Decimals uint8
+}
+
+// knownTokens are resolved by symbol without RPC calls.
+var knownTokens = []token{
+	{Symbol: "SOL", Mint: "So11111111111111111111111111111111111111112", Decimals: 9},
+	{Symbol: "USDC", Mint: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", Decimals: 6},
+	{Symbol: "USDT", Mint: "Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB", Decimals: 6},
+	{Symbol: "JUP", Mint: "JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN", Decimals: 6},
+	{Symbol: "BONK", Mint: "DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263", Decimals: 5},
+	{Symbol: "WIF", Mint: "EKpQGSJtjMFqKZ9KQanSqYXRcF8fBopzLHYxdM65zcjm", Decimals: 6},
+}
+
+// tokens resolves symbols and mints, fetching the decimals of unknown mints from the RPC.
+type tokens struct {
+	decimals jupSolana.Decimals
+}
+
+func newTokens(rpcURL string) (tokens, error) {
+	known := make(map[string]uint8, len(knownTokens))
+	for _, t := range knownTokens {
+		known[t.Mint] = t.Decimals
+	}
+
+	d, err := jupSolana.NewDecimals(rpcURL, jupSolana.WithKnownDecimals(known))
+	if err != nil {
+		return tokens{}, fmt.Errorf("could not create decimals resolver: %w", err)
+	}
+
+	return tokens{decimals: d}, nil
+}
+
+// resolve returns the token of a symbol, case insensitive, or of a mint address.
+func (t tokens) resolve(ctx context.Context, s string) (token, error) {
+	for _, known := range knownTokens {
+		if strings.EqualFold(s, known.Symbol) || s == known.Mint {
+			return known, nil
+		}
+	}
+
+	if _, err := solana.PublicKeyFromBase58(s); err != nil {
+		return token{}, fmt.Errorf("unknown token %q: use a known symbol or a mint address", s)
+	}
+
+	dec, err := t.decimals.Decimals(ctx, s)
+	if err != nil {
+		return token{}, fmt.Errorf("could not resolve token %s: %w", s, err)
+	}
+
+	return token{Symbol: shortMint(s), Mint: s, Decimals: dec}, nil
+}
+
+func shortMint(mint string) string {
+	if len(mint) <= 8 {
+		return mint
+	}
+
+	return mint[:4] + ".." + mint[len(mint)-4:]
+}
+
+// parseAmount converts a decimal amount of the token into raw units.
+func parseAmount(s string, decimals uint8) (uint64, error) {
+	amount, err := decimal.NewFromString(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+
+	raw := amount.Shift(int32(decimals))
+	if !raw.IsPositive() || !raw.Equal(raw.Truncate(0)) {
+		return 0, fmt.Errorf("amount %q must be positive with at most %d decimals", s, decimals)
+	}
+
+	if raw.BigInt().BitLen() > 64 {
+		return 0, fmt.Errorf("amount %q is too large", s)
+	}
+
+	return raw.BigInt().Uint64(), nil
+}
+
+// formatAmount converts a raw amount, as a base 10 string, into a decimal amount of the token.
+func formatAmount(raw string, decimals uint8) string {
+	amount, err := decimal.NewFromString(raw)
+	if err != nil {
+		return raw
+	}
+
+	return amount.Shift(-int32(decimals)).String()
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		amount   string
+		decimals uint8
+		want     uint64
+		err      string
+	}{
+		{amount: "1", decimals: 9, want: 1_000_000_000},
+		{amount: "0.5", decimals: 6, want: 500_000},
+		{amount: "12.345678", decimals: 6, want: 12_345_678},
+		{amount: "100", decimals: 0, want: 100},
+		{amount: "0.1234567", decimals: 6, err: `amount "0.1234567" must be positive with at most 6 decimals`},
+		{amount: "0", decimals: 6, err: `amount "0" must be positive with at most 6 decimals`},
+		{amount: "-1", decimals: 6, err: `amount "-1" must be positive with at most 6 decimals`},
+		{amount: "one", decimals: 6, err: `invalid amount "one"`},
+		{amount: "18446744073709551616", decimals: 0, err: `amount "18446744073709551616" is too large`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.amount, func(t *testing.T) {
+			got, err := parseAmount(tt.amount, tt.decimals)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	require.Equal(t, "0.024266", formatAmount("24266", 6))
+	require.Equal(t, "1.5", formatAmount("1500000000", 9))
+	require.Equal(t, "100", formatAmount("100", 0))
+	require.Equal(t, "n/a", formatAmount("n/a", 6))
+}
+
+func TestTokens_Resolve(t *testing.T) {
+	tokens, err := newTokens("http://127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Run("symbol", func(t *testing.T) {
+		tok, err := tokens.resolve(context.TODO(), "usdc")
+		require.NoError(t, err)
+		require.Equal(t, "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", tok.Mint)
+		require.Equal(t, uint8(6), tok.Decimals)
+	})
+
+	t.Run("known mint", func(t *testing.T) {
+		tok, err := tokens.resolve(context.TODO(), "So11111111111111111111111111111111111111112")
+		require.NoError(t, err)
+		require.Equal(t, "SOL", tok.Symbol)
+		require.Equal(t, uint8(9), tok.Decimals)
+	})
+
+	t.Run("unknown symbol", func(t *testing.T) {
+		_, err := tokens.resolve(context.TODO(), "DOGE")
+		require.EqualError(t, err, `unknown token "DOGE": use a known symbol or a mint address`)
+	})
+}
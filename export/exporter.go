@@ -0,0 +1,228 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/ilkamo/jupiter-go/history"
+)
+
+// Record is a trade with decimal amounts, as written by the CSV and NDJSON formats.
+// Fees are in SOL, the fee including the priority fee.
+type Record struct {
+	Timestamp        string   `json:"timestamp"`
+	Signature        string   `json:"signature"`
+	Slot             uint64   `json:"slot"`
+	InstructionIndex int      `json:"instruction_index"`
+	Wallet           string   `json:"wallet"`
+	InputMint        string   `json:"input_mint"`
+	InputAmount      string   `json:"input_amount"`
+	OutputMint       string   `json:"output_mint"`
+	OutputAmount     string   `json:"output_amount"`
+	Fee              string   `json:"fee"`
+	PriorityFee      string   `json:"priority_fee"`
+	Tip              string   `json:"tip"`
+	Route            []string `json:"route"`
+	RouteIndex       int      `json:"route_index"`
+}
+
+func (r Record) row() []string {
+	return []string{
+		r.Timestamp,
+		r.Signature,
+		strconv.FormatUint(r.Slot, 10),
+		strconv.Itoa(r.InstructionIndex),
+		r.Wallet,
+		r.InputMint,
+		r.InputAmount,
+		r.OutputMint,
+		r.OutputAmount,
+		r.Fee,
+		r.PriorityFee,
+		r.Tip,
+		strings.Join(r.Route, routeSeparator),
+		strconv.Itoa(r.RouteIndex),
+	}
+}
+
+type exporter struct {
+	format   Format
+	decimals Decimals
+	symbols  map[string]string
+}
+
+// NewExporter creates an exporter writing the given format, with amounts scaled by the decimals of their mint.
+func NewExporter(format Format, decimals Decimals, opts ...ExporterOption) (Exporter, error) {
+	if _, err := ParseFormat(format.String()); err != nil {
+		return nil, err
+	}
+
+	if decimals == nil {
+		return nil, fmt.Errorf("decimals resolver is required")
+	}
+
+	e := &exporter{
+		format:   format,
+		decimals: decimals,
+		symbols:  map[string]string{wrappedSolMint: "SOL"},
+	}
+
+	for _, opt := range opts {
+		if err := opt(e); err != nil {
+			return nil, fmt.Errorf("could not apply option: %w", err)
+		}
+	}
+
+	return e, nil
+}
+
+// Export writes the trades oldest first. The CSV formats always write their header.
+func (e exporter) Export(ctx context.Context, w io.Writer, trades []history.Trade) error {
+	trades = slices.Clone(trades)
+	history.SortTrades(trades)
+
+	records := make([]Record, 0, len(trades))
+
+	for _, t := range trades {
+		r, err := e.record(ctx, t)
+		if err != nil {
+			return fmt.Errorf("could not convert trade %s: %w", t.Signature, err)
+		}
+
+		records = append(records, r)
+	}
+
+	if e.format == NDJSON {
+		enc := json.NewEncoder(w)
+
+		for _, r := range records {
+			if err := enc.Encode(r); err != nil {
+				return fmt.Errorf("could not write record: %w", err)
+			}
+		}
+
+		return nil
+	}
+
+	header, row := Columns, func(_ history.Trade, r Record) []string { return r.row() }
+
+	switch e.format {
+	case Koinly:
+		header, row = koinlyColumns, e.koinlyRow
+	case CoinTracker:
+		header, row = coinTrackerColumns, e.coinTrackerRow
+	}
+
+	rows := make([][]string, 0, len(records))
+	for i, r := range records {
+		rows = append(rows, row(trades[i], r))
+	}
+
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("could not write header: %w", err)
+	}
+
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("could not write rows: %w", err)
+	}
+
+	return nil
+}
+
+func (e exporter) record(ctx context.Context, t history.Trade) (Record, error) {
+	inputDecimals, err := e.decimals.Decimals(ctx, t.InputMint)
+	if err != nil {
+		return Record{}, fmt.Errorf("could not get input mint decimals: %w", err)
+	}
+
+	outputDecimals, err := e.decimals.Decimals(ctx, t.OutputMint)
+	if err != nil {
+		return Record{}, fmt.Errorf("could not get output mint decimals: %w", err)
+	}
+
+	route := t.Labels()
+	if route == nil {
+		route = []string{}
+	}
+
+	return Record{
+		Timestamp:        formatTime(t.BlockTime, time.RFC3339),
+		Signature:        t.Signature,
+		Slot:             t.Slot,
+		InstructionIndex: t.InstructionIndex,
+		Wallet:           t.Wallet,
+		InputMint:        t.InputMint,
+		InputAmount:      scale(t.InputAmount, inputDecimals),
+		OutputMint:       t.OutputMint,
+		OutputAmount:     scale(t.OutputAmount, outputDecimals),
+		Fee:              scale(t.Fee, solDecimals),
+		PriorityFee:      scale(t.PriorityFee, solDecimals),
+		Tip:              scale(t.Tip, solDecimals),
+		Route:            route,
+		RouteIndex:       t.RouteIndex,
+	}, nil
+}
+
+// koinlyRow and coinTrackerRow include the tip in the fee, since the tools accept a single fee.
+func (e exporter) koinlyRow(t history.Trade, r Record) []string {
+	return []string{
+		formatTime(t.BlockTime, koinlyTimeLayout),
+		r.InputAmount,
+		e.symbol(t.InputMint),
+		r.OutputAmount,
+		e.symbol(t.OutputMint),
+		scale(t.Fee+t.Tip, solDecimals),
+		e.symbol(wrappedSolMint),
+		"",
+		"",
+		"",
+		strings.Join(r.Route, routeSeparator),
+		t.Signature,
+	}
+}
+
+func (e exporter) coinTrackerRow(t history.Trade, r Record) []string {
+	return []string{
+		formatTime(t.BlockTime, coinTrackerTimeLayout),
+		r.OutputAmount,
+		e.symbol(t.OutputMint),
+		r.InputAmount,
+		e.symbol(t.InputMint),
+		scale(t.Fee+t.Tip, solDecimals),
+		e.symbol(wrappedSolMint),
+		"",
+	}
+}
+
+func (e exporter) symbol(mint string) string {
+	if symbol, ok := e.symbols[mint]; ok {
+		return symbol
+	}
+
+	return mint
+}
+
+// formatTime formats t in UTC, or returns an empty string for trades without a block time.
+func formatTime(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(layout)
+}
+
+func scale(amount uint64, decimals uint8) string {
+	return decimal.NewFromBigInt(new(big.Int).SetUint64(amount), -int32(decimals)).String()
+}
@@ -0,0 +1,163 @@
+package export_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilkamo/jupiter-go/export"
+	"github.com/ilkamo/jupiter-go/history"
+)
+
+const (
+	testWallet    = "BXzmfHxfEMcMj8hDccUNdrwXVNeybyfb2iV2nktE1VnJ"
+	testQuoteMint = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	testTokenMint = "JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN"
+	testSolMint   = "So11111111111111111111111111111111111111112"
+)
+
+func testTrades() []history.Trade {
+	return []history.Trade{
+		{
+			Wallet:           testWallet,
+			Signature:        "sig-2",
+			InstructionIndex: 1,
+			RouteIndex:       1,
+			Slot:             200,
+			BlockTime:        time.Date(2025, time.February, 5, 12, 30, 0, 0, time.UTC),
+			InputMint:        testTokenMint,
+			InputAmount:      24_266_000,
+			OutputMint:       testQuoteMint,
+			OutputAmount:     15_500_000,
+			Fee:              5000,
+		},
+		{
+			Wallet:       testWallet,
+			Signature:    "sig-1",
+			Slot:         100,
+			BlockTime:    time.Date(2025, time.January, 10, 8, 0, 0, 0, time.UTC),
+			InputMint:    testSolMint,
+			InputAmount:  100_000_000,
+			OutputMint:   testTokenMint,
+			OutputAmount: 24_266_000,
+			Fee:          15_000,
+			PriorityFee:  10_000,
+			Tip:          1_000,
+			Hops: []history.Hop{
+				{Label: "Meteora DLMM"},
+				{Label: "Whirlpool"},
+			},
+		},
+	}
+}
+
+// decimalsMock resolves the decimals of the mints it holds.
+type decimalsMock map[string]uint8
+
+func (d decimalsMock) Decimals(_ context.Context, mint string) (uint8, error) {
+	dec, ok := d[mint]
+	if !ok {
+		return 0, fmt.Errorf("unknown mint %s", mint)
+	}
+
+	return dec, nil
+}
+
+func testDecimals() export.Decimals {
+	return decimalsMock{testSolMint: 9, testTokenMint: 6, testQuoteMint: 6}
+}
+
+func TestExporter_Export(t *testing.T) {
+	symbols := export.WithSymbols(map[string]string{testQuoteMint: "USDC", testTokenMint: "JUP"})
+
+	tests := []struct {
+		format   export.Format
+		expected string
+	}{
+		{
+			format: export.CSV,
+			expected: "timestamp,signature,slot,instruction_index,wallet,input_mint,input_amount,output_mint," +
+				"output_amount,fee,priority_fee,tip,route,route_index\n" +
+				"2025-01-10T08:00:00Z,sig-1,100,0," + testWallet + "," + testSolMint + ",0.1," + testTokenMint +
+				",24.266,0.000015,0.00001,0.000001,Meteora DLMM > Whirlpool,0\n" +
+				"2025-02-05T12:30:00Z,sig-2,200,1," + testWallet + "," + testTokenMint + ",24.266," + testQuoteMint +
+				",15.5,0.000005,0,0,,1\n",
+		},
+		{
+			format: export.NDJSON,
+			expected: `{"timestamp":"2025-01-10T08:00:00Z","signature":"sig-1","slot":100,"instruction_index":0,` +
+				`"wallet":"` + testWallet + `","input_mint":"` + testSolMint + `","input_amount":"0.1",` +
+				`"output_mint":"` + testTokenMint + `","output_amount":"24.266","fee":"0.000015",` +
+				`"priority_fee":"0.00001","tip":"0.000001","route":["Meteora DLMM","Whirlpool"],"route_index":0}` + "\n" +
+				`{"timestamp":"2025-02-05T12:30:00Z","signature":"sig-2","slot":200,"instruction_index":1,` +
+				`"wallet":"` + testWallet + `","input_mint":"` + testTokenMint + `","input_amount":"24.266",` +
+				`"output_mint":"` + testQuoteMint + `","output_amount":"15.5","fee":"0.000005",` +
+				`"priority_fee":"0","tip":"0","route":[],"route_index":1}` + "\n",
+		},
+		{
+			format: export.Koinly,
+			expected: "Date,Sent Amount,Sent Currency,Received Amount,Received Currency,Fee Amount,Fee Currency," +
+				"Net Worth Amount,Net Worth Currency,Label,Description,TxHash\n" +
+				"2025-01-10 08:00:00 UTC,0.1,SOL,24.266,JUP,0.000016,SOL,,,,Meteora DLMM > Whirlpool,sig-1\n" +
+				"2025-02-05 12:30:00 UTC,24.266,JUP,15.5,USDC,0.000005,SOL,,,,,sig-2\n",
+		},
+		{
+			format: export.CoinTracker,
+			expected: "Date,Received Quantity,Received Currency,Sent Quantity,Sent Currency,Fee Amount,Fee Currency,Tag\n" +
+				"01/10/2025 08:00:00,24.266,JUP,0.1,SOL,0.000016,SOL,\n" +
+				"02/05/2025 12:30:00,15.5,USDC,24.266,JUP,0.000005,SOL,\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format.String(), func(t *testing.T) {
+			e, err := export.NewExporter(tt.format, testDecimals(), symbols)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			require.NoError(t, e.Export(context.TODO(), &buf, testTrades()))
+			require.Equal(t, tt.expected, buf.String())
+		})
+	}
+
+	t.Run("csv header without trades", func(t *testing.T) {
+		e, err := export.NewExporter(export.CSV, testDecimals())
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, e.Export(context.TODO(), &buf, nil))
+		require.Equal(t, "timestamp,signature,slot,instruction_index,wallet,input_mint,input_amount,output_mint,"+
+			"output_amount,fee,priority_fee,tip,route,route_index\n", buf.String())
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := export.NewExporter(export.Format(9), testDecimals())
+		require.EqualError(t, err, `unknown format "Format(9)"`)
+	})
+
+	t.Run("missing decimals", func(t *testing.T) {
+		_, err := export.NewExporter(export.CSV, nil)
+		require.EqualError(t, err, "decimals resolver is required")
+	})
+
+	t.Run("unknown mint decimals", func(t *testing.T) {
+		e, err := export.NewExporter(export.CSV, decimalsMock{testSolMint: 9})
+		require.NoError(t, err)
+
+		err = e.Export(context.TODO(), &bytes.Buffer{}, testTrades())
+		require.ErrorContains(t, err, "could not convert trade sig-1: could not get output mint decimals")
+	})
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := export.ParseFormat("Koinly")
+	require.NoError(t, err)
+	require.Equal(t, export.Koinly, f)
+
+	_, err = export.ParseFormat("xlsx")
+	require.EqualError(t, err, `unknown format "xlsx"`)
+}
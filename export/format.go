@@ -0,0 +1,99 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Format is the file format written by an exporter.
+type Format int
+
+const (
+	// CSV writes one row per trade with the Columns header.
+	CSV Format = iota
+	// NDJSON writes one Record per line.
+	NDJSON
+	// Koinly writes the Koinly universal CSV import format.
+	Koinly
+	// CoinTracker writes the CoinTracker CSV import format.
+	CoinTracker
+)
+
+func (f Format) String() string {
+	switch f {
+	case CSV:
+		return "csv"
+	case NDJSON:
+		return "ndjson"
+	case Koinly:
+		return "koinly"
+	case CoinTracker:
+		return "cointracker"
+	}
+
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+// ParseFormat returns the format with the given name, as returned by Format.String.
+func ParseFormat(name string) (Format, error) {
+	for _, f := range []Format{CSV, NDJSON, Koinly, CoinTracker} {
+		if strings.EqualFold(name, f.String()) {
+			return f, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown format %q", name)
+}
+
+// Columns is the header of the CSV format, in order. Columns are only ever appended.
+var Columns = []string{
+	"timestamp",
+	"signature",
+	"slot",
+	"instruction_index",
+	"wallet",
+	"input_mint",
+	"input_amount",
+	"output_mint",
+	"output_amount",
+	"fee",
+	"priority_fee",
+	"tip",
+	"route",
+	"route_index",
+}
+
+var koinlyColumns = []string{
+	"Date",
+	"Sent Amount",
+	"Sent Currency",
+	"Received Amount",
+	"Received Currency",
+	"Fee Amount",
+	"Fee Currency",
+	"Net Worth Amount",
+	"Net Worth Currency",
+	"Label",
+	"Description",
+	"TxHash",
+}
+
+var coinTrackerColumns = []string{
+	"Date",
+	"Received Quantity",
+	"Received Currency",
+	"Sent Quantity",
+	"Sent Currency",
+	"Fee Amount",
+	"Fee Currency",
+	"Tag",
+}
+
+const (
+	koinlyTimeLayout      = "2006-01-02 15:04:05 UTC"
+	coinTrackerTimeLayout = "01/02/2006 15:04:05"
+	routeSeparator        = " > "
+
+	wrappedSolMint = "So11111111111111111111111111111111111111112"
+	solDecimals    = 9
+)
@@ -0,0 +1,18 @@
+package export
+
+import (
+	"context"
+	"io"
+
+	"github.com/ilkamo/jupiter-go/history"
+)
+
+// Decimals resolves the number of decimals of a mint, like the resolver of solana.NewDecimals.
+type Decimals interface {
+	Decimals(ctx context.Context, mint string) (uint8, error)
+}
+
+// Exporter writes trades in a file format.
+type Exporter interface {
+	Export(ctx context.Context, w io.Writer, trades []history.Trade) error
+}
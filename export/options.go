@@ -0,0 +1,15 @@
+package export
+
+// ExporterOption is a function that allows to specify options for the exporter.
+type ExporterOption func(*exporter) error
+
+// WithSymbols sets the currency symbols written for mints by the tax tool formats, e.g. "USDC".
+// Mints without a symbol are written as their address.
+func WithSymbols(symbols map[string]string) ExporterOption {
+	return func(e *exporter) error {
+		for mint, symbol := range symbols {
+			e.symbols[mint] = symbol
+		}
+		return nil
+	}
+}
@@ -0,0 +1,174 @@
+package history
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"github.com/ilkamo/jupiter-go/jupiter/aggregator"
+)
+
+const (
+	maxPageSize     = 1000
+	defaultPageSize = maxPageSize
+)
+
+type history struct {
+	clientRPC rpcService
+	store     Store
+	parser    aggregator.Parser
+	wallet    solana.PublicKey
+	pageSize  int
+}
+
+// NewHistory creates the trade history of a wallet, indexed from the given RPC endpoint into the store.
+func NewHistory(wallet string, rpcEndpoint string, store Store, opts ...HistoryOption) (History, error) {
+	walletPk, err := solana.PublicKeyFromBase58(wallet)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse wallet public key: %w", err)
+	}
+
+	if store == nil {
+		return nil, fmt.Errorf("store is required")
+	}
+
+	h := &history{
+		store:    store,
+		wallet:   walletPk,
+		pageSize: defaultPageSize,
+	}
+
+	for _, opt := range opts {
+		if err := opt(h); err != nil {
+			return nil, fmt.Errorf("could not apply option: %w", err)
+		}
+	}
+
+	if h.clientRPC == nil {
+		if rpcEndpoint == "" {
+			return nil, fmt.Errorf("rpcEndpoint is required when no RPC service is provided")
+		}
+
+		h.clientRPC = rpc.New(rpcEndpoint)
+	}
+
+	if h.parser == nil {
+		h.parser, err = aggregator.NewParser()
+		if err != nil {
+			return nil, fmt.Errorf("could not create parser: %w", err)
+		}
+	}
+
+	return h, nil
+}
+
+// Sync indexes the finalized transactions of the wallet newer than the last synced signature
+// and returns the number of trades stored. Transactions are processed oldest first and the last
+// synced signature is saved after each of them, so an interrupted sync resumes where it stopped.
+func (h history) Sync(ctx context.Context) (int, error) {
+	signatures, err := h.newSignatures(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+
+	for i := len(signatures) - 1; i >= 0; i-- {
+		sig := signatures[i]
+
+		// Failed transactions did not swap.
+		if sig.Err == nil {
+			trades, err := h.trades(ctx, sig.Signature)
+			if err != nil {
+				return count, err
+			}
+
+			if len(trades) > 0 {
+				if err := h.store.Save(ctx, trades); err != nil {
+					return count, fmt.Errorf("could not save trades of %s: %w", sig.Signature, err)
+				}
+			}
+
+			count += len(trades)
+		}
+
+		if err := h.store.SetLastSignature(ctx, h.wallet.String(), sig.Signature.String()); err != nil {
+			return count, fmt.Errorf("could not save last signature: %w", err)
+		}
+	}
+
+	return count, nil
+}
+
+// Trades returns the stored trades of the wallet, oldest first.
+func (h history) Trades(ctx context.Context) ([]Trade, error) {
+	return h.store.Trades(ctx, h.wallet.String())
+}
+
+// newSignatures pages through the signatures of the wallet, newest first, until the last synced one.
+func (h history) newSignatures(ctx context.Context) ([]*rpc.TransactionSignature, error) {
+	last, err := h.store.LastSignature(ctx, h.wallet.String())
+	if err != nil {
+		return nil, fmt.Errorf("could not get last signature: %w", err)
+	}
+
+	opts := &rpc.GetSignaturesForAddressOpts{
+		Limit:      &h.pageSize,
+		Commitment: rpc.CommitmentFinalized,
+	}
+
+	if last != "" {
+		opts.Until, err = solana.SignatureFromBase58(last)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse last signature: %w", err)
+		}
+	}
+
+	var signatures []*rpc.TransactionSignature
+
+	for {
+		page, err := h.clientRPC.GetSignaturesForAddressWithOpts(ctx, h.wallet, opts)
+		if err != nil {
+			return nil, fmt.Errorf("could not get signatures: %w", err)
+		}
+
+		signatures = append(signatures, page...)
+
+		if len(page) < h.pageSize {
+			return signatures, nil
+		}
+
+		opts.Before = page[len(page)-1].Signature
+	}
+}
+
+func (h history) trades(ctx context.Context, signature solana.Signature) ([]Trade, error) {
+	maxVersion := uint64(0)
+
+	result, err := h.clientRPC.GetTransaction(ctx, signature, &rpc.GetTransactionOpts{
+		Encoding:                       solana.EncodingBase64,
+		Commitment:                     rpc.CommitmentFinalized,
+		MaxSupportedTransactionVersion: &maxVersion,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get transaction %s: %w", signature, err)
+	}
+
+	parsed, err := h.parser.ParseTransaction(result)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse transaction %s: %w", signature, err)
+	}
+
+	if len(parsed) == 0 {
+		return nil, nil
+	}
+
+	tx, err := result.Transaction.GetTransaction()
+	if err != nil {
+		return nil, fmt.Errorf("could not decode transaction %s: %w", signature, err)
+	}
+
+	return newTrades(h.wallet, result, tx, parsed), nil
+}
@@ -0,0 +1,357 @@
+package history_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilkamo/jupiter-go/history"
+	"github.com/ilkamo/jupiter-go/jupiter/aggregator"
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+	"github.com/ilkamo/jupiter-go/swap"
+)
+
+const testWallet = "BXzmfHxfEMcMj8hDccUNdrwXVNeybyfb2iV2nktE1VnJ"
+
+var (
+	testWalletPk  = solana.MustPublicKeyFromBase58(testWallet)
+	testInputMint = solana.MustPublicKeyFromBase58(swap.WrappedSolMint)
+	testMidMint   = solana.MustPublicKeyFromBase58("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+	testOutMint   = solana.MustPublicKeyFromBase58("JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN")
+	testDLMM      = solana.MustPublicKeyFromBase58("LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo")
+	testWhirlpool = solana.MustPublicKeyFromBase58("whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc")
+)
+
+type testTx struct {
+	signature solana.Signature
+	slot      uint64
+	failed    bool
+	result    *rpc.GetTransactionResult
+}
+
+type rpcMock struct {
+	// txs are ordered oldest first, as they happened on-chain.
+	txs              []testTx
+	signatureCalls   int
+	fetchedTxs       []solana.Signature
+	failAfterFetches int
+}
+
+func (r *rpcMock) GetSignaturesForAddressWithOpts(
+	_ context.Context,
+	_ solana.PublicKey,
+	opts *rpc.GetSignaturesForAddressOpts,
+) ([]*rpc.TransactionSignature, error) {
+	r.signatureCalls++
+
+	var page []*rpc.TransactionSignature
+
+	// Walk newest first, starting before opts.Before and stopping at opts.Until.
+	started := opts.Before.IsZero()
+	for i := len(r.txs) - 1; i >= 0; i-- {
+		tx := r.txs[i]
+
+		if !started {
+			started = tx.signature == opts.Before
+			continue
+		}
+
+		if tx.signature == opts.Until || len(page) == *opts.Limit {
+			break
+		}
+
+		sig := &rpc.TransactionSignature{Signature: tx.signature, Slot: tx.slot}
+		if tx.failed {
+			sig.Err = map[string]any{"InstructionError": []any{0, "Custom"}}
+		}
+
+		page = append(page, sig)
+	}
+
+	return page, nil
+}
+
+func (r *rpcMock) GetTransaction(
+	_ context.Context,
+	signature solana.Signature,
+	_ *rpc.GetTransactionOpts,
+) (*rpc.GetTransactionResult, error) {
+	if r.failAfterFetches > 0 && len(r.fetchedTxs) == r.failAfterFetches {
+		return nil, fmt.Errorf("rpc unavailable")
+	}
+
+	r.fetchedTxs = append(r.fetchedTxs, signature)
+
+	for _, tx := range r.txs {
+		if tx.signature == signature {
+			return tx.result, nil
+		}
+	}
+
+	return nil, fmt.Errorf("transaction not found")
+}
+
+func swapEventCPI(amm, inputMint solana.PublicKey, in uint64, outputMint solana.PublicKey, out uint64) []byte {
+	sum := sha256.Sum256([]byte("event:SwapEvent"))
+
+	data := []byte{0xe4, 0x45, 0xa5, 0x2e, 0x51, 0xcb, 0x9a, 0x1d}
+	data = append(data, sum[:8]...)
+	data = append(data, amm[:]...)
+	data = append(data, inputMint[:]...)
+	data = binary.LittleEndian.AppendUint64(data, in)
+	data = append(data, outputMint[:]...)
+	data = binary.LittleEndian.AppendUint64(data, out)
+
+	return data
+}
+
+// newTestTx builds a transaction swapping 1000 WSOL lamports through two hops with a Jito tip,
+// or a plain transfer when isSwap is false.
+func newTestTx(t *testing.T, n int, isSwap bool) testTx {
+	t.Helper()
+
+	signature := solana.Signature{byte(n), 1}
+	slot := uint64(100 + n)
+
+	instructions := []solana.Instruction{
+		system.NewTransferInstruction(5000, testWalletPk, jupSolana.JitoTipAccounts[0]).Build(),
+	}
+
+	if isSwap {
+		sum := sha256.Sum256([]byte("global:shared_accounts_route"))
+
+		data := append([]byte{}, sum[:8]...)
+		data = append(data, 0, 2, 0, 0, 0, 38, 100, 0, 1, 17, 0, 100, 1, 2)
+		data = binary.LittleEndian.AppendUint64(data, 1000)
+		data = binary.LittleEndian.AppendUint64(data, 2000)
+		data = binary.LittleEndian.AppendUint16(data, 50)
+		data = append(data, 0)
+
+		accounts := solana.AccountMetaSlice{
+			solana.Meta(solana.TokenProgramID),
+			solana.Meta(solana.NewWallet().PublicKey()),
+			solana.Meta(testWalletPk).SIGNER().WRITE(),
+			solana.Meta(solana.NewWallet().PublicKey()).WRITE(),
+			solana.Meta(solana.NewWallet().PublicKey()).WRITE(),
+			solana.Meta(solana.NewWallet().PublicKey()).WRITE(),
+			solana.Meta(solana.NewWallet().PublicKey()).WRITE(),
+			solana.Meta(testInputMint),
+			solana.Meta(testOutMint),
+			solana.Meta(aggregator.ProgramID),
+			solana.Meta(aggregator.ProgramID),
+			solana.Meta(aggregator.EventAuthority),
+			solana.Meta(aggregator.ProgramID),
+		}
+
+		instructions = append(instructions, solana.NewInstruction(aggregator.ProgramID, accounts, data))
+	}
+
+	tx, err := solana.NewTransaction(instructions, solana.Hash{}, solana.TransactionPayer(testWalletPk))
+	require.NoError(t, err)
+
+	tx.Signatures = []solana.Signature{signature}
+
+	txBytes, err := tx.MarshalBinary()
+	require.NoError(t, err)
+
+	envelope, err := json.Marshal([]string{base64.StdEncoding.EncodeToString(txBytes), "base64"})
+	require.NoError(t, err)
+
+	var result rpc.GetTransactionResult
+	require.NoError(t, json.Unmarshal([]byte(fmt.Sprintf(`{"transaction":%s,"meta":{}}`, envelope)), &result))
+
+	blockTime := solana.UnixTimeSeconds(1_700_000_000 + n)
+	result.Slot = slot
+	result.BlockTime = &blockTime
+	result.Meta.Fee = 15000
+
+	if isSwap {
+		index := func(key solana.PublicKey) uint16 {
+			for i, k := range tx.Message.AccountKeys {
+				if k.Equals(key) {
+					return uint16(i)
+				}
+			}
+			require.FailNow(t, "key not found", key.String())
+			return 0
+		}
+
+		event := func(data []byte) rpc.CompiledInstruction {
+			return rpc.CompiledInstruction{
+				ProgramIDIndex: index(aggregator.ProgramID),
+				Accounts:       []uint16{index(aggregator.EventAuthority)},
+				Data:           data,
+				StackHeight:    2,
+			}
+		}
+
+		result.Meta.InnerInstructions = []rpc.InnerInstruction{{
+			Index: 1,
+			Instructions: []rpc.CompiledInstruction{
+				event(swapEventCPI(testDLMM, testInputMint, 1000, testMidMint, 300)),
+				event(swapEventCPI(testWhirlpool, testMidMint, 300, testOutMint, 2010)),
+			},
+		}}
+	}
+
+	return testTx{signature: signature, slot: slot, result: &result}
+}
+
+func expectedTrade(tx testTx) history.Trade {
+	return history.Trade{
+		Wallet:           testWallet,
+		Signature:        tx.signature.String(),
+		InstructionIndex: 1,
+		Slot:             tx.slot,
+		BlockTime:        tx.result.BlockTime.Time().UTC(),
+		InputMint:        testInputMint.String(),
+		InputAmount:      1000,
+		OutputMint:       testOutMint.String(),
+		OutputAmount:     2010,
+		Fee:              15000,
+		PriorityFee:      10000,
+		Tip:              5000,
+		Hops: []history.Hop{
+			{
+				AMM: testDLMM.String(), Label: "Meteora DLMM",
+				InputMint: testInputMint.String(), InputAmount: 1000, OutputMint: testMidMint.String(), OutputAmount: 300,
+			},
+			{
+				AMM: testWhirlpool.String(), Label: "Whirlpool",
+				InputMint: testMidMint.String(), InputAmount: 300, OutputMint: testOutMint.String(), OutputAmount: 2010,
+			},
+		},
+	}
+}
+
+func TestHistory_Sync(t *testing.T) {
+	t.Run("history without rpc endpoint", func(t *testing.T) {
+		_, err := history.NewHistory(testWallet, "", history.NewMemoryStore())
+		require.EqualError(t, err, "rpcEndpoint is required when no RPC service is provided")
+	})
+
+	t.Run("invalid page size", func(t *testing.T) {
+		_, err := history.NewHistory(testWallet, "", history.NewMemoryStore(), history.WithPageSize(0))
+		require.EqualError(t, err, "could not apply option: page size must be between 1 and 1000")
+	})
+
+	t.Run("pages through signatures and resumes from the last one", func(t *testing.T) {
+		first := newTestTx(t, 1, true)
+		transfer := newTestTx(t, 2, false)
+		failed := newTestTx(t, 3, true)
+		failed.failed = true
+		second := newTestTx(t, 4, true)
+
+		mock := &rpcMock{txs: []testTx{first, transfer, failed, second}}
+		store := history.NewMemoryStore()
+
+		h, err := history.NewHistory(testWallet, "", store, history.WithHistoryRPC(mock), history.WithPageSize(2))
+		require.NoError(t, err)
+
+		count, err := h.Sync(context.TODO())
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+		require.Equal(t, 3, mock.signatureCalls)
+		// Failed transactions are not fetched, the others are fetched oldest first.
+		require.Equal(t, []solana.Signature{first.signature, transfer.signature, second.signature}, mock.fetchedTxs)
+
+		trades, err := h.Trades(context.TODO())
+		require.NoError(t, err)
+		require.Equal(t, []history.Trade{expectedTrade(first), expectedTrade(second)}, trades)
+		require.Equal(t, []string{"Meteora DLMM", "Whirlpool"}, trades[0].Labels())
+
+		last, err := store.LastSignature(context.TODO(), testWallet)
+		require.NoError(t, err)
+		require.Equal(t, second.signature.String(), last)
+
+		third := newTestTx(t, 5, true)
+		mock.txs = append(mock.txs, third)
+		mock.fetchedTxs = nil
+
+		count, err = h.Sync(context.TODO())
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		require.Equal(t, []solana.Signature{third.signature}, mock.fetchedTxs)
+
+		trades, err = h.Trades(context.TODO())
+		require.NoError(t, err)
+		require.Len(t, trades, 3)
+	})
+
+	t.Run("interrupted sync resumes after the last stored transaction", func(t *testing.T) {
+		first := newTestTx(t, 1, true)
+		second := newTestTx(t, 2, true)
+
+		mock := &rpcMock{txs: []testTx{first, second}, failAfterFetches: 1}
+		store := history.NewMemoryStore()
+
+		h, err := history.NewHistory(testWallet, "", store, history.WithHistoryRPC(mock))
+		require.NoError(t, err)
+
+		count, err := h.Sync(context.TODO())
+		require.ErrorContains(t, err, "rpc unavailable")
+		require.Equal(t, 1, count)
+
+		last, err := store.LastSignature(context.TODO(), testWallet)
+		require.NoError(t, err)
+		require.Equal(t, first.signature.String(), last)
+
+		mock.failAfterFetches = 0
+		mock.fetchedTxs = nil
+
+		count, err = h.Sync(context.TODO())
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		require.Equal(t, []solana.Signature{second.signature}, mock.fetchedTxs)
+	})
+
+	t.Run("trades of other wallets are ignored", func(t *testing.T) {
+		mock := &rpcMock{txs: []testTx{newTestTx(t, 1, true)}}
+
+		other := solana.NewWallet().PublicKey().String()
+
+		h, err := history.NewHistory(other, "", history.NewMemoryStore(), history.WithHistoryRPC(mock))
+		require.NoError(t, err)
+
+		count, err := h.Sync(context.TODO())
+		require.NoError(t, err)
+		require.Zero(t, count)
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := history.NewMemoryStore()
+
+	newer := history.Trade{Wallet: testWallet, Signature: "b", Slot: 2, BlockTime: time.Unix(2, 0)}
+	older := history.Trade{Wallet: testWallet, Signature: "a", Slot: 1, BlockTime: time.Unix(1, 0)}
+	other := history.Trade{Wallet: "other", Signature: "c", Slot: 1}
+
+	require.NoError(t, store.Save(context.TODO(), []history.Trade{newer, older, other}))
+
+	// Saving the same trade again replaces it.
+	older.OutputAmount = 10
+	require.NoError(t, store.Save(context.TODO(), []history.Trade{older}))
+
+	// A second route of the same instruction is another trade.
+	secondRoute := newer
+	secondRoute.RouteIndex = 1
+	require.NoError(t, store.Save(context.TODO(), []history.Trade{secondRoute}))
+
+	trades, err := store.Trades(context.TODO(), testWallet)
+	require.NoError(t, err)
+	require.Equal(t, []history.Trade{older, newer, secondRoute}, trades)
+
+	last, err := store.LastSignature(context.TODO(), testWallet)
+	require.NoError(t, err)
+	require.Empty(t, last)
+}
@@ -0,0 +1,37 @@
+package history
+
+import (
+	"context"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+type rpcService interface {
+	GetSignaturesForAddressWithOpts(
+		ctx context.Context,
+		account solana.PublicKey,
+		opts *rpc.GetSignaturesForAddressOpts,
+	) ([]*rpc.TransactionSignature, error)
+	GetTransaction(
+		ctx context.Context,
+		txSig solana.Signature,
+		opts *rpc.GetTransactionOpts,
+	) (*rpc.GetTransactionResult, error)
+}
+
+// Store persists the trades of wallets and the last signature synced for each of them.
+type Store interface {
+	// LastSignature returns the newest signature synced for the wallet, empty if none.
+	LastSignature(ctx context.Context, wallet string) (string, error)
+	SetLastSignature(ctx context.Context, wallet string, signature string) error
+	// Save inserts the trades, replacing the ones with the same wallet, signature, instruction index and route index.
+	Save(ctx context.Context, trades []Trade) error
+	// Trades returns the trades of the wallet, oldest first.
+	Trades(ctx context.Context, wallet string) ([]Trade, error)
+}
+
+type History interface {
+	Sync(context.Context) (int, error)
+	Trades(context.Context) ([]Trade, error)
+}
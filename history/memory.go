@@ -0,0 +1,81 @@
+package history
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+type tradeKey struct {
+	wallet           string
+	signature        string
+	instructionIndex int
+	routeIndex       int
+}
+
+type memoryStore struct {
+	mu             sync.RWMutex
+	trades         map[tradeKey]Trade
+	lastSignatures map[string]string
+}
+
+// NewMemoryStore creates a store that keeps trades in memory.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		trades:         make(map[tradeKey]Trade),
+		lastSignatures: make(map[string]string),
+	}
+}
+
+func (m *memoryStore) LastSignature(_ context.Context, wallet string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.lastSignatures[wallet], nil
+}
+
+func (m *memoryStore) SetLastSignature(_ context.Context, wallet string, signature string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lastSignatures[wallet] = signature
+
+	return nil
+}
+
+func (m *memoryStore) Save(_ context.Context, trades []Trade) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, t := range trades {
+		t.Hops = slices.Clone(t.Hops)
+		key := tradeKey{
+			wallet:           t.Wallet,
+			signature:        t.Signature,
+			instructionIndex: t.InstructionIndex,
+			routeIndex:       t.RouteIndex,
+		}
+
+		m.trades[key] = t
+	}
+
+	return nil
+}
+
+func (m *memoryStore) Trades(_ context.Context, wallet string) ([]Trade, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var trades []Trade
+
+	for key, t := range m.trades {
+		if key.wallet == wallet {
+			t.Hops = slices.Clone(t.Hops)
+			trades = append(trades, t)
+		}
+	}
+
+	SortTrades(trades)
+
+	return trades, nil
+}
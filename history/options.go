@@ -0,0 +1,38 @@
+package history
+
+import (
+	"fmt"
+
+	"github.com/ilkamo/jupiter-go/jupiter/aggregator"
+)
+
+// HistoryOption is a function that allows to specify options for the history.
+type HistoryOption func(*history) error
+
+// WithHistoryRPC sets the RPC service used to fetch signatures and transactions.
+func WithHistoryRPC(clientRPC rpcService) HistoryOption {
+	return func(h *history) error {
+		h.clientRPC = clientRPC
+		return nil
+	}
+}
+
+// WithParser sets the parser used to find the swaps of a transaction, e.g. one configured with DEX labels.
+func WithParser(parser aggregator.Parser) HistoryOption {
+	return func(h *history) error {
+		h.parser = parser
+		return nil
+	}
+}
+
+// WithPageSize sets the number of signatures requested per getSignaturesForAddress call, between 1 and 1000.
+func WithPageSize(size int) HistoryOption {
+	return func(h *history) error {
+		if size < 1 || size > maxPageSize {
+			return fmt.Errorf("page size must be between 1 and %d", maxPageSize)
+		}
+
+		h.pageSize = size
+		return nil
+	}
+}
@@ -0,0 +1,204 @@
+// Package sqlite provides a history.Store backed by a SQLite file.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	// Registers the pure Go "sqlite" database/sql driver.
+	_ "modernc.org/sqlite"
+
+	"github.com/ilkamo/jupiter-go/history"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS trades (
+	wallet            TEXT    NOT NULL,
+	signature         TEXT    NOT NULL,
+	instruction_index INTEGER NOT NULL,
+	route_index       INTEGER NOT NULL,
+	slot              INTEGER NOT NULL,
+	block_time        INTEGER NOT NULL,
+	input_mint        TEXT    NOT NULL,
+	input_amount      TEXT    NOT NULL,
+	output_mint       TEXT    NOT NULL,
+	output_amount     TEXT    NOT NULL,
+	fee               INTEGER NOT NULL,
+	priority_fee      INTEGER NOT NULL,
+	tip               INTEGER NOT NULL,
+	hops              TEXT    NOT NULL,
+	PRIMARY KEY (wallet, signature, instruction_index, route_index)
+);
+
+CREATE TABLE IF NOT EXISTS last_signatures (
+	wallet    TEXT NOT NULL PRIMARY KEY,
+	signature TEXT NOT NULL
+);
+`
+
+// Store is a history.Store that must be closed after use.
+type Store interface {
+	history.Store
+	Close() error
+}
+
+type store struct {
+	db *sql.DB
+}
+
+// NewStore opens the SQLite file at path, creating it and its tables if needed.
+func NewStore(path string) (Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open database: %w", err)
+	}
+
+	// A single connection avoids "database is locked" errors on concurrent writes.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("could not create tables: %w", err)
+	}
+
+	return &store{db: db}, nil
+}
+
+func (s *store) Close() error {
+	return s.db.Close()
+}
+
+func (s *store) LastSignature(ctx context.Context, wallet string) (string, error) {
+	var signature string
+
+	err := s.db.QueryRowContext(ctx, `SELECT signature FROM last_signatures WHERE wallet = ?`, wallet).Scan(&signature)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("could not query last signature: %w", err)
+	}
+
+	return signature, nil
+}
+
+func (s *store) SetLastSignature(ctx context.Context, wallet string, signature string) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO last_signatures (wallet, signature) VALUES (?, ?)`,
+		wallet, signature,
+	)
+	if err != nil {
+		return fmt.Errorf("could not save last signature: %w", err)
+	}
+
+	return nil
+}
+
+func (s *store) Save(ctx context.Context, trades []history.Trade) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	for _, t := range trades {
+		hops, err := json.Marshal(t.Hops)
+		if err != nil {
+			return fmt.Errorf("could not marshal hops: %w", err)
+		}
+
+		var blockTime int64
+		if !t.BlockTime.IsZero() {
+			blockTime = t.BlockTime.Unix()
+		}
+
+		// Amounts are stored as text since they do not fit a signed 64-bit integer.
+		_, err = tx.ExecContext(ctx, `INSERT OR REPLACE INTO trades (
+				wallet, signature, instruction_index, route_index, slot, block_time,
+				input_mint, input_amount, output_mint, output_amount,
+				fee, priority_fee, tip, hops
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			t.Wallet, t.Signature, t.InstructionIndex, t.RouteIndex, int64(t.Slot), blockTime,
+			t.InputMint, strconv.FormatUint(t.InputAmount, 10), t.OutputMint, strconv.FormatUint(t.OutputAmount, 10),
+			int64(t.Fee), int64(t.PriorityFee), int64(t.Tip), string(hops),
+		)
+		if err != nil {
+			return fmt.Errorf("could not insert trade %s: %w", t.Signature, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (s *store) Trades(ctx context.Context, wallet string) ([]history.Trade, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT
+			signature, instruction_index, route_index, slot, block_time,
+			input_mint, input_amount, output_mint, output_amount,
+			fee, priority_fee, tip, hops
+		FROM trades WHERE wallet = ?`, wallet)
+	if err != nil {
+		return nil, fmt.Errorf("could not query trades: %w", err)
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	var trades []history.Trade
+
+	for rows.Next() {
+		var (
+			t                         = history.Trade{Wallet: wallet}
+			slot, blockTime           int64
+			fee, priorityFee, tip     int64
+			inputAmount, outputAmount string
+			hops                      string
+		)
+
+		err := rows.Scan(
+			&t.Signature, &t.InstructionIndex, &t.RouteIndex, &slot, &blockTime,
+			&t.InputMint, &inputAmount, &t.OutputMint, &outputAmount,
+			&fee, &priorityFee, &tip, &hops,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("could not scan trade: %w", err)
+		}
+
+		t.Slot, t.Fee, t.PriorityFee, t.Tip = uint64(slot), uint64(fee), uint64(priorityFee), uint64(tip)
+
+		if blockTime != 0 {
+			t.BlockTime = time.Unix(blockTime, 0).UTC()
+		}
+
+		if t.InputAmount, err = strconv.ParseUint(inputAmount, 10, 64); err != nil {
+			return nil, fmt.Errorf("could not parse input amount of %s: %w", t.Signature, err)
+		}
+
+		if t.OutputAmount, err = strconv.ParseUint(outputAmount, 10, 64); err != nil {
+			return nil, fmt.Errorf("could not parse output amount of %s: %w", t.Signature, err)
+		}
+
+		if err := json.Unmarshal([]byte(hops), &t.Hops); err != nil {
+			return nil, fmt.Errorf("could not unmarshal hops of %s: %w", t.Signature, err)
+		}
+
+		trades = append(trades, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not read trades: %w", err)
+	}
+
+	history.SortTrades(trades)
+
+	return trades, nil
+}
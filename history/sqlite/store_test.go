@@ -0,0 +1,80 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilkamo/jupiter-go/history"
+	"github.com/ilkamo/jupiter-go/history/sqlite"
+)
+
+const testWallet = "BXzmfHxfEMcMj8hDccUNdrwXVNeybyfb2iV2nktE1VnJ"
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	store, err := sqlite.NewStore(path)
+	require.NoError(t, err)
+
+	last, err := store.LastSignature(context.TODO(), testWallet)
+	require.NoError(t, err)
+	require.Empty(t, last)
+
+	newer := history.Trade{
+		Wallet:           testWallet,
+		Signature:        "b",
+		InstructionIndex: 2,
+		Slot:             2,
+		BlockTime:        time.Unix(1_700_000_002, 0).UTC(),
+		InputMint:        "So11111111111111111111111111111111111111112",
+		InputAmount:      18_000_000_000_000_000_000,
+		OutputMint:       "JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN",
+		OutputAmount:     2010,
+		Fee:              15000,
+		PriorityFee:      10000,
+		Tip:              5000,
+		Hops: []history.Hop{{
+			AMM:          "LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo",
+			Label:        "Meteora DLMM",
+			InputMint:    "So11111111111111111111111111111111111111112",
+			InputAmount:  1000,
+			OutputMint:   "JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN",
+			OutputAmount: 2010,
+		}},
+	}
+	older := history.Trade{Wallet: testWallet, Signature: "a", Slot: 1}
+	other := history.Trade{Wallet: "other", Signature: "c", Slot: 1}
+
+	// Another route of the same instruction, invoked through CPI.
+	secondRoute := newer
+	secondRoute.RouteIndex = 1
+	secondRoute.Hops = nil
+	secondRoute.Fee, secondRoute.PriorityFee, secondRoute.Tip = 0, 0, 0
+
+	require.NoError(t, store.Save(context.TODO(), []history.Trade{secondRoute, newer, older, other}))
+	require.NoError(t, store.SetLastSignature(context.TODO(), testWallet, "b"))
+
+	// Saving the same trade again replaces it.
+	older.OutputAmount = 10
+	require.NoError(t, store.Save(context.TODO(), []history.Trade{older}))
+
+	require.NoError(t, store.Close())
+
+	// The trades and the last signature survive reopening the file.
+	store, err = sqlite.NewStore(path)
+	require.NoError(t, err)
+
+	defer func() { require.NoError(t, store.Close()) }()
+
+	trades, err := store.Trades(context.TODO(), testWallet)
+	require.NoError(t, err)
+	require.Equal(t, []history.Trade{older, newer, secondRoute}, trades)
+
+	last, err = store.LastSignature(context.TODO(), testWallet)
+	require.NoError(t, err)
+	require.Equal(t, "b", last)
+}
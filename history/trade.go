@@ -0,0 +1,221 @@
+package history
+
+import (
+	"cmp"
+	"encoding/binary"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"github.com/ilkamo/jupiter-go/jupiter/aggregator"
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+)
+
+const (
+	lamportsPerSignature = 5000
+
+	systemInstructionTransfer = 2
+)
+
+// Trade is a Jupiter swap executed by a wallet. Amounts are raw token amounts.
+type Trade struct {
+	Wallet           string
+	Signature        string
+	InstructionIndex int
+	// RouteIndex tells apart the routes a top-level instruction invoked through CPI.
+	RouteIndex int
+	Slot       uint64
+	// BlockTime is zero if the cluster did not report it.
+	BlockTime    time.Time
+	InputMint    string
+	InputAmount  uint64
+	OutputMint   string
+	OutputAmount uint64
+	// Fee is the transaction fee in lamports, PriorityFee the part of it above the base fee
+	// and Tip the lamports sent to Jito tip accounts.
+	// They are only set on the first trade of a transaction.
+	Fee         uint64
+	PriorityFee uint64
+	Tip         uint64
+	Hops        []Hop
+}
+
+// Hop is a swap through a single AMM.
+type Hop struct {
+	AMM          string
+	Label        string
+	InputMint    string
+	InputAmount  uint64
+	OutputMint   string
+	OutputAmount uint64
+}
+
+// Labels returns the DEX labels of the hops, without duplicates.
+func (t Trade) Labels() []string {
+	var labels []string
+
+	seen := make(map[string]struct{}, len(t.Hops))
+
+	for _, hop := range t.Hops {
+		if hop.Label == "" {
+			continue
+		}
+
+		if _, ok := seen[hop.Label]; ok {
+			continue
+		}
+
+		seen[hop.Label] = struct{}{}
+		labels = append(labels, hop.Label)
+	}
+
+	return labels
+}
+
+// SortTrades sorts trades oldest first, by slot then by signature, instruction index and route index.
+func SortTrades(trades []Trade) {
+	slices.SortStableFunc(trades, func(a, b Trade) int {
+		return cmp.Or(
+			cmp.Compare(a.Slot, b.Slot),
+			strings.Compare(a.Signature, b.Signature),
+			cmp.Compare(a.InstructionIndex, b.InstructionIndex),
+			cmp.Compare(a.RouteIndex, b.RouteIndex),
+		)
+	})
+}
+
+// newTrades normalizes the routes of a transaction the wallet swapped with.
+func newTrades(
+	wallet solana.PublicKey,
+	result *rpc.GetTransactionResult,
+	tx *solana.Transaction,
+	parsed []aggregator.Trade,
+) []Trade {
+	var trades []Trade
+
+	for _, p := range parsed {
+		if !p.Route.Accounts.UserTransferAuthority.Equals(wallet) {
+			continue
+		}
+
+		trade := Trade{
+			Wallet:           wallet.String(),
+			Signature:        p.Signature.String(),
+			InstructionIndex: p.InstructionIndex,
+			RouteIndex:       p.RouteIndex,
+			Slot:             p.Slot,
+			OutputMint:       p.Route.Accounts.DestinationMint.String(),
+		}
+
+		if p.BlockTime != nil {
+			trade.BlockTime = p.BlockTime.UTC()
+		}
+
+		switch {
+		case p.Route.Accounts.SourceMint != nil:
+			trade.InputMint = p.Route.Accounts.SourceMint.String()
+		case len(p.Hops) > 0:
+			trade.InputMint = p.Hops[0].InputMint.String()
+		default:
+			trade.InputMint = tokenAccountMint(result, tx, p.Route.Accounts.SourceTokenAccount)
+		}
+
+		for _, hop := range p.Hops {
+			trade.Hops = append(trade.Hops, Hop{
+				AMM:          hop.AMM.String(),
+				Label:        hop.Label,
+				InputMint:    hop.InputMint.String(),
+				InputAmount:  hop.InputAmount,
+				OutputMint:   hop.OutputMint.String(),
+				OutputAmount: hop.OutputAmount,
+			})
+
+			if hop.InputMint.String() == trade.InputMint {
+				trade.InputAmount += hop.InputAmount
+			}
+
+			if hop.OutputMint.String() == trade.OutputMint {
+				trade.OutputAmount += hop.OutputAmount
+			}
+		}
+
+		// Without events, fall back to the amounts of the route instruction.
+		if len(p.Hops) == 0 {
+			if p.Route.Kind.ExactOut() {
+				trade.InputAmount, trade.OutputAmount = p.Route.QuotedInAmount, p.Route.OutAmount
+			} else {
+				trade.InputAmount, trade.OutputAmount = p.Route.InAmount, p.Route.QuotedOutAmount
+			}
+		}
+
+		if len(trades) == 0 {
+			trade.Fee = result.Meta.Fee
+			trade.PriorityFee = priorityFee(result.Meta.Fee, tx)
+			trade.Tip = tips(wallet, tx, allKeys(result, tx))
+		}
+
+		trades = append(trades, trade)
+	}
+
+	return trades
+}
+
+func priorityFee(fee uint64, tx *solana.Transaction) uint64 {
+	baseFee := uint64(tx.Message.Header.NumRequiredSignatures) * lamportsPerSignature
+	if fee < baseFee {
+		return 0
+	}
+
+	return fee - baseFee
+}
+
+// tips sums the system transfers from the wallet to Jito tip accounts.
+func tips(wallet solana.PublicKey, tx *solana.Transaction, keys solana.PublicKeySlice) uint64 {
+	var total uint64
+
+	for _, ix := range tx.Message.Instructions {
+		if int(ix.ProgramIDIndex) >= len(keys) || !keys[ix.ProgramIDIndex].Equals(solana.SystemProgramID) {
+			continue
+		}
+
+		if len(ix.Data) < 12 || binary.LittleEndian.Uint32(ix.Data[0:4]) != systemInstructionTransfer {
+			continue
+		}
+
+		if len(ix.Accounts) < 2 || int(ix.Accounts[0]) >= len(keys) || int(ix.Accounts[1]) >= len(keys) {
+			continue
+		}
+
+		from, to := keys[ix.Accounts[0]], keys[ix.Accounts[1]]
+		if from.Equals(wallet) && to.IsAnyOf(jupSolana.JitoTipAccounts...) {
+			total += binary.LittleEndian.Uint64(ix.Data[4:12])
+		}
+	}
+
+	return total
+}
+
+// tokenAccountMint returns the mint of a token account from the token balances of the transaction.
+func tokenAccountMint(result *rpc.GetTransactionResult, tx *solana.Transaction, account solana.PublicKey) string {
+	keys := allKeys(result, tx)
+
+	for _, balance := range slices.Concat(result.Meta.PreTokenBalances, result.Meta.PostTokenBalances) {
+		if int(balance.AccountIndex) < len(keys) && keys[balance.AccountIndex].Equals(account) {
+			return balance.Mint.String()
+		}
+	}
+
+	return ""
+}
+
+// allKeys returns the static keys of the message followed by the keys loaded from address lookup tables.
+func allKeys(result *rpc.GetTransactionResult, tx *solana.Transaction) solana.PublicKeySlice {
+	return slices.Concat(
+		tx.Message.AccountKeys,
+		result.Meta.LoadedAddresses.Writable,
+		result.Meta.LoadedAddresses.ReadOnly,
+	)
+}
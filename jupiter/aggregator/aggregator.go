@@ -0,0 +1,395 @@
+// Package aggregator decodes instructions of the Jupiter v6 aggregator program.
+package aggregator
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+
+	"github.com/ilkamo/jupiter-go/jupiter"
+)
+
+var (
+	// ProgramID is the Jupiter v6 aggregator program.
+	ProgramID = solana.MustPublicKeyFromBase58("JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4")
+	// EventAuthority is the account the aggregator program emits its events with.
+	EventAuthority = solana.MustPublicKeyFromBase58("D8cy77BBepLMngZx6ZukaTff5hCt1HrWyKk3Hnd9oitf")
+)
+
+var (
+	// ErrNotJupiterInstruction is returned when an instruction is not for the aggregator program.
+	ErrNotJupiterInstruction = errors.New("not a jupiter aggregator instruction")
+	// ErrUnknownInstruction is returned when the instruction is not one of the route instructions.
+	ErrUnknownInstruction = errors.New("unknown jupiter aggregator instruction")
+	// ErrUnknownSwap is returned when the route plan contains a swap variant the decoder does not know.
+	// The route is still returned, with the route plan decoded up to the unknown step.
+	ErrUnknownSwap = errors.New("unknown swap variant")
+
+	errUnexpectedEnd = errors.New("unexpected end of data")
+)
+
+// Kind is the route instruction of the aggregator program.
+type Kind uint8
+
+const (
+	KindRoute Kind = iota
+	KindSharedAccountsRoute
+	KindExactOutRoute
+	KindSharedAccountsExactOutRoute
+	KindRouteWithTokenLedger
+	KindSharedAccountsRouteWithTokenLedger
+)
+
+// String returns the IDL name of the instruction.
+func (k Kind) String() string {
+	if int(k) < len(layouts) {
+		return layouts[k].name
+	}
+
+	return fmt.Sprintf("Kind(%d)", k)
+}
+
+// ExactOut reports whether the instruction swaps into a fixed out amount.
+func (k Kind) ExactOut() bool {
+	return k == KindExactOutRoute || k == KindSharedAccountsExactOutRoute
+}
+
+// Shared reports whether the instruction routes through the program token accounts and has an id argument.
+func (k Kind) Shared() bool {
+	return k == KindSharedAccountsRoute || k == KindSharedAccountsExactOutRoute ||
+		k == KindSharedAccountsRouteWithTokenLedger
+}
+
+// TokenLedger reports whether the in amount is read from a token ledger account instead of the arguments.
+func (k Kind) TokenLedger() bool {
+	return k == KindRouteWithTokenLedger || k == KindSharedAccountsRouteWithTokenLedger
+}
+
+// layout describes where the accounts of a route instruction live, -1 if the account is not part of it.
+type layout struct {
+	name                    string
+	discriminator           [8]byte
+	tokenProgram            int
+	userTransferAuthority   int
+	sourceTokenAccount      int
+	destinationTokenAccount int
+	recipientTokenAccount   int
+	sourceMint              int
+	destinationMint         int
+	platformFeeAccount      int
+	tokenLedger             int
+}
+
+var layouts = []layout{
+	KindRoute: {
+		name: "route", tokenProgram: 0, userTransferAuthority: 1, sourceTokenAccount: 2,
+		destinationTokenAccount: 3, recipientTokenAccount: 4, sourceMint: -1, destinationMint: 5, platformFeeAccount: 6,
+		tokenLedger: -1,
+	},
+	KindSharedAccountsRoute: {
+		name: "shared_accounts_route", tokenProgram: 0, userTransferAuthority: 2, sourceTokenAccount: 3,
+		destinationTokenAccount: 6, recipientTokenAccount: -1, sourceMint: 7, destinationMint: 8, platformFeeAccount: 9,
+		tokenLedger: -1,
+	},
+	KindExactOutRoute: {
+		name: "exact_out_route", tokenProgram: 0, userTransferAuthority: 1, sourceTokenAccount: 2,
+		destinationTokenAccount: 3, recipientTokenAccount: 4, sourceMint: 5, destinationMint: 6, platformFeeAccount: 7,
+		tokenLedger: -1,
+	},
+	KindSharedAccountsExactOutRoute: {
+		name: "shared_accounts_exact_out_route", tokenProgram: 0, userTransferAuthority: 2, sourceTokenAccount: 3,
+		destinationTokenAccount: 6, recipientTokenAccount: -1, sourceMint: 7, destinationMint: 8, platformFeeAccount: 9,
+		tokenLedger: -1,
+	},
+	KindRouteWithTokenLedger: {
+		name: "route_with_token_ledger", tokenProgram: 0, userTransferAuthority: 1, sourceTokenAccount: 2,
+		destinationTokenAccount: 3, recipientTokenAccount: 4, sourceMint: -1, destinationMint: 5, platformFeeAccount: 6,
+		tokenLedger: 7,
+	},
+	KindSharedAccountsRouteWithTokenLedger: {
+		name: "shared_accounts_route_with_token_ledger", tokenProgram: 0, userTransferAuthority: 2, sourceTokenAccount: 3,
+		destinationTokenAccount: 6, recipientTokenAccount: -1, sourceMint: 7, destinationMint: 8, platformFeeAccount: 9,
+		tokenLedger: 11,
+	},
+}
+
+func init() {
+	for i := range layouts {
+		layouts[i].discriminator = discriminator("global:" + layouts[i].name)
+	}
+}
+
+func discriminator(preimage string) [8]byte {
+	sum := sha256.Sum256([]byte(preimage))
+
+	var d [8]byte
+	copy(d[:], sum[:8])
+
+	return d
+}
+
+func (l layout) accountCount() int {
+	return max(l.tokenProgram, l.userTransferAuthority, l.sourceTokenAccount, l.destinationTokenAccount,
+		l.recipientTokenAccount, l.sourceMint, l.destinationMint, l.platformFeeAccount, l.tokenLedger) + 1
+}
+
+// Swap is a step of the route plan. Data holds the raw payload of the variant, if any.
+type Swap struct {
+	Variant uint8
+	Name    string
+	Data    []byte
+}
+
+// RoutePlanStep is one hop of the route plan.
+type RoutePlanStep struct {
+	Swap Swap
+	// Percent is the share of the input of this step, across steps with the same input index.
+	Percent     uint8
+	InputIndex  uint8
+	OutputIndex uint8
+}
+
+// Accounts holds the accounts of a route instruction.
+// Optional accounts are nil when absent, which the program encodes with its own ID.
+type Accounts struct {
+	TokenProgram            solana.PublicKey
+	UserTransferAuthority   solana.PublicKey
+	SourceTokenAccount      solana.PublicKey
+	DestinationTokenAccount solana.PublicKey
+	// RecipientTokenAccount receives the output instead of DestinationTokenAccount when set. It is only part of the
+	// route, exact_out_route and route_with_token_ledger instructions.
+	RecipientTokenAccount *solana.PublicKey
+	// SourceMint is nil for the route and route_with_token_ledger instructions.
+	SourceMint         *solana.PublicKey
+	DestinationMint    solana.PublicKey
+	PlatformFeeAccount *solana.PublicKey
+	TokenLedger        *solana.PublicKey
+}
+
+// Route is a decoded route instruction.
+type Route struct {
+	Kind Kind
+	// ID is only set by the shared accounts instructions.
+	ID        uint8
+	RoutePlan []RoutePlanStep
+	// InAmount and QuotedOutAmount are set by the exact in instructions.
+	// InAmount is zero for the token ledger instructions, which read it on-chain.
+	InAmount        uint64
+	QuotedOutAmount uint64
+	// OutAmount and QuotedInAmount are set by the exact out instructions.
+	OutAmount      uint64
+	QuotedInAmount uint64
+	SlippageBps    uint16
+	PlatformFeeBps uint8
+	Accounts       Accounts
+}
+
+// DecodeJupiterInstruction decodes an instruction as returned by the /swap-instructions endpoint.
+func DecodeJupiterInstruction(ix jupiter.Instruction) (Route, error) {
+	if ix.ProgramId != ProgramID.String() {
+		return Route{}, fmt.Errorf("%w: program %s", ErrNotJupiterInstruction, ix.ProgramId)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(ix.Data)
+	if err != nil {
+		return Route{}, fmt.Errorf("could not decode instruction data: %w", err)
+	}
+
+	accounts := make([]solana.PublicKey, len(ix.Accounts))
+	for i, account := range ix.Accounts {
+		accounts[i], err = solana.PublicKeyFromBase58(account.Pubkey)
+		if err != nil {
+			return Route{}, fmt.Errorf("could not parse account %d: %w", i, err)
+		}
+	}
+
+	return DecodeInstruction(data, accounts)
+}
+
+// DecodeCompiledInstruction decodes an instruction of a transaction message.
+// Keys are the account keys of the message, including the ones loaded from address lookup tables.
+func DecodeCompiledInstruction(ix solana.CompiledInstruction, keys solana.PublicKeySlice) (Route, error) {
+	if int(ix.ProgramIDIndex) >= len(keys) {
+		return Route{}, fmt.Errorf("invalid program index %d", ix.ProgramIDIndex)
+	}
+
+	if !keys[ix.ProgramIDIndex].Equals(ProgramID) {
+		return Route{}, fmt.Errorf("%w: program %s", ErrNotJupiterInstruction, keys[ix.ProgramIDIndex])
+	}
+
+	accounts := make([]solana.PublicKey, len(ix.Accounts))
+	for i, idx := range ix.Accounts {
+		if int(idx) >= len(keys) {
+			return Route{}, fmt.Errorf("invalid account index %d", idx)
+		}
+		accounts[i] = keys[idx]
+	}
+
+	return DecodeInstruction(ix.Data, accounts)
+}
+
+// DecodeInstruction decodes the data and accounts of an aggregator route instruction.
+// If the route plan contains an unknown swap variant, the route is returned with an error
+// wrapping ErrUnknownSwap: the amounts and accounts are still decoded.
+func DecodeInstruction(data []byte, accounts []solana.PublicKey) (Route, error) {
+	if len(data) < 8 {
+		return Route{}, fmt.Errorf("could not read discriminator: %w", errUnexpectedEnd)
+	}
+
+	kind, ok := kindOf(data[:8])
+	if !ok {
+		return Route{}, fmt.Errorf("%w: discriminator %x", ErrUnknownInstruction, data[:8])
+	}
+
+	l := layouts[kind]
+
+	if len(accounts) < l.accountCount() {
+		return Route{}, fmt.Errorf("%s: expected at least %d accounts, got %d", kind, l.accountCount(), len(accounts))
+	}
+
+	route := Route{
+		Kind:     kind,
+		Accounts: decodeAccounts(l, accounts),
+	}
+
+	b := data[8:]
+
+	if kind.Shared() {
+		if len(b) < 1 {
+			return Route{}, fmt.Errorf("%s: could not read id: %w", kind, errUnexpectedEnd)
+		}
+
+		route.ID = b[0]
+		b = b[1:]
+	}
+
+	argsSize := 8 + 8 + 2 + 1
+	if kind.TokenLedger() {
+		argsSize = 8 + 2 + 1
+	}
+
+	if len(b) < 4+argsSize {
+		return Route{}, fmt.Errorf("%s: could not read arguments: %w", kind, errUnexpectedEnd)
+	}
+
+	// The arguments have a fixed size and end the instruction data, which keeps them
+	// readable when the route plan cannot be decoded.
+	route.decodeArgs(b[len(b)-argsSize:])
+
+	plan, n, planErr := decodeRoutePlan(b[:len(b)-argsSize])
+	route.RoutePlan = plan
+
+	if planErr != nil {
+		if errors.Is(planErr, ErrUnknownSwap) {
+			return route, fmt.Errorf("%s: %w", kind, planErr)
+		}
+
+		return Route{}, fmt.Errorf("%s: %w", kind, planErr)
+	}
+
+	if n != len(b)-argsSize {
+		return Route{}, fmt.Errorf("%s: %d unexpected bytes after the route plan", kind, len(b)-argsSize-n)
+	}
+
+	return route, nil
+}
+
+func kindOf(d []byte) (Kind, bool) {
+	for i, l := range layouts {
+		if string(l.discriminator[:]) == string(d) {
+			return Kind(i), true
+		}
+	}
+
+	return 0, false
+}
+
+func (r *Route) decodeArgs(b []byte) {
+	if r.Kind.TokenLedger() {
+		r.QuotedOutAmount = binary.LittleEndian.Uint64(b[0:8])
+		r.SlippageBps = binary.LittleEndian.Uint16(b[8:10])
+		r.PlatformFeeBps = b[10]
+
+		return
+	}
+
+	amount := binary.LittleEndian.Uint64(b[0:8])
+	quoted := binary.LittleEndian.Uint64(b[8:16])
+
+	if r.Kind.ExactOut() {
+		r.OutAmount, r.QuotedInAmount = amount, quoted
+	} else {
+		r.InAmount, r.QuotedOutAmount = amount, quoted
+	}
+
+	r.SlippageBps = binary.LittleEndian.Uint16(b[16:18])
+	r.PlatformFeeBps = b[18]
+}
+
+func decodeRoutePlan(b []byte) ([]RoutePlanStep, int, error) {
+	if len(b) < 4 {
+		return nil, 0, fmt.Errorf("could not read route plan length: %w", errUnexpectedEnd)
+	}
+
+	count := binary.LittleEndian.Uint32(b[0:4])
+	pos := 4
+
+	// Every step takes at least 4 bytes, this also bounds the allocation.
+	if uint64(count)*4 > uint64(len(b)-pos) {
+		return nil, 0, fmt.Errorf("route plan of %d steps: %w", count, errUnexpectedEnd)
+	}
+
+	plan := make([]RoutePlanStep, 0, count)
+
+	for i := range int(count) {
+		swap, n, err := decodeSwap(b[pos:])
+		if err != nil {
+			return plan, 0, fmt.Errorf("route plan step %d: %w", i, err)
+		}
+
+		pos += n
+
+		if len(b)-pos < 3 {
+			return plan, 0, fmt.Errorf("route plan step %d: %w", i, errUnexpectedEnd)
+		}
+
+		plan = append(plan, RoutePlanStep{
+			Swap:        swap,
+			Percent:     b[pos],
+			InputIndex:  b[pos+1],
+			OutputIndex: b[pos+2],
+		})
+
+		pos += 3
+	}
+
+	return plan, pos, nil
+}
+
+func decodeAccounts(l layout, accounts []solana.PublicKey) Accounts {
+	optional := func(idx int) *solana.PublicKey {
+		if idx < 0 || accounts[idx].Equals(ProgramID) {
+			return nil
+		}
+
+		key := accounts[idx]
+
+		return &key
+	}
+
+	return Accounts{
+		TokenProgram:            accounts[l.tokenProgram],
+		UserTransferAuthority:   accounts[l.userTransferAuthority],
+		SourceTokenAccount:      accounts[l.sourceTokenAccount],
+		DestinationTokenAccount: accounts[l.destinationTokenAccount],
+		RecipientTokenAccount:   optional(l.recipientTokenAccount),
+		SourceMint:              optional(l.sourceMint),
+		DestinationMint:         accounts[l.destinationMint],
+		PlatformFeeAccount:      optional(l.platformFeeAccount),
+		TokenLedger:             optional(l.tokenLedger),
+	}
+}
@@ -0,0 +1,263 @@
+package aggregator_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilkamo/jupiter-go/jupiter"
+	"github.com/ilkamo/jupiter-go/jupiter/aggregator"
+)
+
+const (
+	testWallet     = "BXzmfHxfEMcMj8hDccUNdrwXVNeybyfb2iV2nktE1VnJ"
+	testSourceMint = "So11111111111111111111111111111111111111112"
+	testDestMint   = "JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN"
+)
+
+func loadSwapInstruction(t *testing.T, name string) jupiter.Instruction {
+	t.Helper()
+
+	data, err := os.ReadFile("../testdata/" + name)
+	require.NoError(t, err)
+
+	var resp jupiter.SwapInstructionsResponse
+	require.NoError(t, json.Unmarshal(data, &resp))
+
+	return resp.SwapInstruction
+}
+
+func testData(name string, args ...[]byte) []byte {
+	sum := sha256.Sum256([]byte("global:" + name))
+
+	data := append([]byte{}, sum[:8]...)
+	for _, a := range args {
+		data = append(data, a...)
+	}
+
+	return data
+}
+
+func u64(v uint64) []byte { return binary.LittleEndian.AppendUint64(nil, v) }
+
+func u16(v uint16) []byte { return binary.LittleEndian.AppendUint16(nil, v) }
+
+func testAccounts(n int) []solana.PublicKey {
+	accounts := make([]solana.PublicKey, n)
+	for i := range accounts {
+		accounts[i] = solana.NewWallet().PublicKey()
+	}
+
+	return accounts
+}
+
+func TestDecodeJupiterInstruction(t *testing.T) {
+	t.Run("shared accounts route with jito", func(t *testing.T) {
+		route, err := aggregator.DecodeJupiterInstruction(loadSwapInstruction(t, "swapInstructionsWithJito.json"))
+		require.NoError(t, err)
+
+		require.Equal(t, aggregator.KindSharedAccountsRoute, route.Kind)
+		require.Equal(t, "shared_accounts_route", route.Kind.String())
+		require.EqualValues(t, 5, route.ID)
+		require.EqualValues(t, 100000, route.InAmount)
+		require.EqualValues(t, 24266, route.QuotedOutAmount)
+		require.EqualValues(t, 250, route.SlippageBps)
+		require.EqualValues(t, 0, route.PlatformFeeBps)
+		require.Equal(t, []aggregator.RoutePlanStep{
+			{Swap: aggregator.Swap{Variant: 38, Name: "MeteoraDlmm"}, Percent: 100, InputIndex: 0, OutputIndex: 1},
+			{Swap: aggregator.Swap{Variant: 38, Name: "MeteoraDlmm"}, Percent: 100, InputIndex: 1, OutputIndex: 2},
+		}, route.RoutePlan)
+
+		require.Equal(t, testWallet, route.Accounts.UserTransferAuthority.String())
+		require.Equal(t, solana.TokenProgramID, route.Accounts.TokenProgram)
+		require.Equal(t, testSourceMint, route.Accounts.SourceMint.String())
+		require.Equal(t, testDestMint, route.Accounts.DestinationMint.String())
+		require.Nil(t, route.Accounts.PlatformFeeAccount)
+		require.Nil(t, route.Accounts.TokenLedger)
+	})
+
+	t.Run("shared accounts route without jito", func(t *testing.T) {
+		route, err := aggregator.DecodeJupiterInstruction(loadSwapInstruction(t, "swapInstructionsWithoutJito.json"))
+		require.NoError(t, err)
+
+		require.Len(t, route.RoutePlan, 3)
+		require.EqualValues(t, 2, route.RoutePlan[2].InputIndex)
+		require.EqualValues(t, 3, route.RoutePlan[2].OutputIndex)
+		require.EqualValues(t, 24256, route.QuotedOutAmount)
+	})
+
+	t.Run("not a jupiter instruction", func(t *testing.T) {
+		ix := loadSwapInstruction(t, "swapInstructionsWithJito.json")
+		ix.ProgramId = solana.SystemProgramID.String()
+
+		_, err := aggregator.DecodeJupiterInstruction(ix)
+		require.ErrorIs(t, err, aggregator.ErrNotJupiterInstruction)
+	})
+}
+
+func TestDecodeCompiledInstruction(t *testing.T) {
+	jupIx := loadSwapInstruction(t, "swapInstructionsWithJito.json")
+
+	var metas solana.AccountMetaSlice
+	for _, account := range jupIx.Accounts {
+		metas = append(metas, &solana.AccountMeta{
+			PublicKey:  solana.MustPublicKeyFromBase58(account.Pubkey),
+			IsSigner:   account.IsSigner,
+			IsWritable: account.IsWritable,
+		})
+	}
+
+	expected, err := aggregator.DecodeJupiterInstruction(jupIx)
+	require.NoError(t, err)
+
+	ixData, err := base64.StdEncoding.DecodeString(jupIx.Data)
+	require.NoError(t, err)
+
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{solana.NewInstruction(aggregator.ProgramID, metas, ixData)},
+		solana.Hash{},
+		solana.TransactionPayer(solana.MustPublicKeyFromBase58(testWallet)),
+	)
+	require.NoError(t, err)
+
+	route, err := aggregator.DecodeCompiledInstruction(tx.Message.Instructions[0], tx.Message.AccountKeys)
+	require.NoError(t, err)
+	require.Equal(t, expected, route)
+
+	t.Run("not a jupiter program", func(t *testing.T) {
+		ix := tx.Message.Instructions[0]
+		ix.ProgramIDIndex = 0
+
+		_, err := aggregator.DecodeCompiledInstruction(ix, tx.Message.AccountKeys)
+		require.ErrorIs(t, err, aggregator.ErrNotJupiterInstruction)
+	})
+
+	t.Run("invalid account index", func(t *testing.T) {
+		ix := tx.Message.Instructions[0]
+		ix.Accounts = []uint16{255}
+
+		_, err := aggregator.DecodeCompiledInstruction(ix, tx.Message.AccountKeys)
+		require.EqualError(t, err, "invalid account index 255")
+	})
+}
+
+func TestDecodeInstruction(t *testing.T) {
+	// Whirlpool (17) with a side, then WhirlpoolSwapV2 (47) with remaining accounts info.
+	routePlan := []byte{
+		2, 0, 0, 0,
+		17, 1, 100, 0, 1,
+		47, 0, 1, 1, 0, 0, 0, 3, 2, 100, 1, 2,
+	}
+
+	t.Run("route", func(t *testing.T) {
+		accounts := testAccounts(9)
+		accounts[4] = aggregator.ProgramID
+		accounts[6] = aggregator.ProgramID
+
+		route, err := aggregator.DecodeInstruction(
+			testData("route", routePlan, u64(1_000), u64(2_000), u16(50), []byte{10}),
+			accounts,
+		)
+		require.NoError(t, err)
+
+		require.Equal(t, aggregator.KindRoute, route.Kind)
+		require.EqualValues(t, 1_000, route.InAmount)
+		require.EqualValues(t, 2_000, route.QuotedOutAmount)
+		require.EqualValues(t, 50, route.SlippageBps)
+		require.EqualValues(t, 10, route.PlatformFeeBps)
+		require.Equal(t, []aggregator.RoutePlanStep{
+			{Swap: aggregator.Swap{Variant: 17, Name: "Whirlpool", Data: []byte{1}}, Percent: 100, InputIndex: 0, OutputIndex: 1},
+			{
+				Swap:    aggregator.Swap{Variant: 47, Name: "WhirlpoolSwapV2", Data: []byte{0, 1, 1, 0, 0, 0, 3, 2}},
+				Percent: 100, InputIndex: 1, OutputIndex: 2,
+			},
+		}, route.RoutePlan)
+
+		require.Equal(t, accounts[1], route.Accounts.UserTransferAuthority)
+		require.Equal(t, accounts[2], route.Accounts.SourceTokenAccount)
+		require.Equal(t, accounts[3], route.Accounts.DestinationTokenAccount)
+		require.Nil(t, route.Accounts.RecipientTokenAccount)
+		require.Nil(t, route.Accounts.SourceMint)
+		require.Equal(t, accounts[5], route.Accounts.DestinationMint)
+		require.Nil(t, route.Accounts.PlatformFeeAccount)
+	})
+
+	t.Run("exact out route", func(t *testing.T) {
+		accounts := testAccounts(11)
+
+		route, err := aggregator.DecodeInstruction(
+			testData("exact_out_route", routePlan, u64(500), u64(700), u16(100), []byte{0}),
+			accounts,
+		)
+		require.NoError(t, err)
+
+		require.True(t, route.Kind.ExactOut())
+		require.EqualValues(t, 500, route.OutAmount)
+		require.EqualValues(t, 700, route.QuotedInAmount)
+		require.Zero(t, route.InAmount)
+		require.Equal(t, accounts[4], *route.Accounts.RecipientTokenAccount)
+		require.Equal(t, accounts[5], *route.Accounts.SourceMint)
+		require.Equal(t, accounts[6], route.Accounts.DestinationMint)
+		require.Equal(t, accounts[7], *route.Accounts.PlatformFeeAccount)
+	})
+
+	t.Run("shared accounts route with token ledger", func(t *testing.T) {
+		accounts := testAccounts(14)
+
+		route, err := aggregator.DecodeInstruction(
+			testData("shared_accounts_route_with_token_ledger", []byte{3}, routePlan, u64(900), u16(30), []byte{0}),
+			accounts,
+		)
+		require.NoError(t, err)
+
+		require.True(t, route.Kind.TokenLedger())
+		require.EqualValues(t, 3, route.ID)
+		require.Zero(t, route.InAmount)
+		require.EqualValues(t, 900, route.QuotedOutAmount)
+		require.EqualValues(t, 30, route.SlippageBps)
+		require.Equal(t, accounts[11], *route.Accounts.TokenLedger)
+	})
+
+	t.Run("unknown swap variant", func(t *testing.T) {
+		plan := []byte{2, 0, 0, 0, 38, 100, 0, 1, 250, 100, 1, 2}
+
+		route, err := aggregator.DecodeInstruction(
+			testData("route", plan, u64(1_000), u64(2_000), u16(50), []byte{0}),
+			testAccounts(9),
+		)
+		require.ErrorIs(t, err, aggregator.ErrUnknownSwap)
+		require.Len(t, route.RoutePlan, 1)
+		require.EqualValues(t, 1_000, route.InAmount)
+		require.EqualValues(t, 2_000, route.QuotedOutAmount)
+	})
+
+	t.Run("unknown instruction", func(t *testing.T) {
+		_, err := aggregator.DecodeInstruction(testData("claim", []byte{0}), testAccounts(9))
+		require.ErrorIs(t, err, aggregator.ErrUnknownInstruction)
+	})
+
+	t.Run("not enough accounts", func(t *testing.T) {
+		_, err := aggregator.DecodeInstruction(
+			testData("route", routePlan, u64(1_000), u64(2_000), u16(50), []byte{0}),
+			testAccounts(5),
+		)
+		require.EqualError(t, err, "route: expected at least 7 accounts, got 5")
+	})
+
+	t.Run("truncated data", func(t *testing.T) {
+		_, err := aggregator.DecodeInstruction(testData("route", routePlan[:6]), testAccounts(9))
+		require.Error(t, err)
+
+		_, err = aggregator.DecodeInstruction(
+			testData("route", routePlan[:10], u64(1_000), u64(2_000), u16(50), []byte{0}),
+			testAccounts(9),
+		)
+		require.ErrorContains(t, err, "unexpected end of data")
+	})
+}
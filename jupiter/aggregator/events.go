@@ -0,0 +1,73 @@
+package aggregator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// ErrUnknownEvent is returned when data does not hold an event the decoder knows.
+var ErrUnknownEvent = errors.New("unknown jupiter aggregator event")
+
+var (
+	// eventCPITag prefixes the data of the self CPI the program emits events with,
+	// it is the anchor EVENT_IX_TAG in little endian.
+	eventCPITag = [8]byte{0xe4, 0x45, 0xa5, 0x2e, 0x51, 0xcb, 0x9a, 0x1d}
+
+	swapEventDiscriminator = discriminator("event:SwapEvent")
+)
+
+const swapEventSize = 32 + 32 + 8 + 32 + 8
+
+// SwapEvent is emitted by the aggregator program for every hop of a route.
+type SwapEvent struct {
+	// AMM is the program of the AMM the hop was swapped through.
+	AMM          solana.PublicKey
+	InputMint    solana.PublicKey
+	InputAmount  uint64
+	OutputMint   solana.PublicKey
+	OutputAmount uint64
+}
+
+// DecodeSwapEvent decodes a SwapEvent from the data of an event CPI instruction or of a "Program data:" log.
+func DecodeSwapEvent(data []byte) (SwapEvent, error) {
+	data = bytes.TrimPrefix(data, eventCPITag[:])
+
+	if len(data) < 8 || !bytes.Equal(data[:8], swapEventDiscriminator[:]) {
+		return SwapEvent{}, ErrUnknownEvent
+	}
+
+	data = data[8:]
+	if len(data) < swapEventSize {
+		return SwapEvent{}, fmt.Errorf("could not read swap event: %w", errUnexpectedEnd)
+	}
+
+	return SwapEvent{
+		AMM:          solana.PublicKeyFromBytes(data[0:32]),
+		InputMint:    solana.PublicKeyFromBytes(data[32:64]),
+		InputAmount:  binary.LittleEndian.Uint64(data[64:72]),
+		OutputMint:   solana.PublicKeyFromBytes(data[72:104]),
+		OutputAmount: binary.LittleEndian.Uint64(data[104:112]),
+	}, nil
+}
+
+func isEventCPI(data []byte) bool {
+	return bytes.HasPrefix(data, eventCPITag[:])
+}
+
+// DefaultLabels maps the programs of common AMMs to their Jupiter label.
+// The complete list is returned by the /program-id-to-label endpoint and can be set with WithLabels.
+var DefaultLabels = map[string]string{
+	"whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc":  "Whirlpool",
+	"675kPX9MHTjS2zt1qfr1NYHuzeLXfQM9H24wFSUt1Mp8": "Raydium",
+	"CAMMCzo5YL8w4VFF8KVHrK22GGUsp5VTaW7grrKgrWqK": "Raydium CLMM",
+	"CPMMoo8L3F4NbTegBCKVNunggL7H1ZpdTHKxQB5qKP1C": "Raydium CP",
+	"LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo":  "Meteora DLMM",
+	"Eo7WjKq67rjJQSZxS6z3YkapzY3eMj6Xy8X5EQVn5UaB": "Meteora",
+	"pAMMBay6oceH9fJKBRHGP5D4bD4sWpmSwMn52FMfXEA":  "Pump.fun Amm",
+	"2wT8Yq49kHgDzXuPxZSaeLaH1qbmGXtEyPy64bL7aD3c": "Lifinity V2",
+	"PhoeNiXZ8ByJGLkxNfZRnkUfjvmuYqLR89jjFHGqdXY":  "Phoenix",
+}
@@ -0,0 +1,9 @@
+package aggregator
+
+import (
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+type Parser interface {
+	ParseTransaction(*rpc.GetTransactionResult) ([]Trade, error)
+}
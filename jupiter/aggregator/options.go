@@ -0,0 +1,15 @@
+package aggregator
+
+// ParserOption is a function that allows to specify options for the parser.
+type ParserOption func(*parser) error
+
+// WithLabels adds DEX labels by AMM program ID, as returned by the /program-id-to-label endpoint.
+// They take precedence over DefaultLabels.
+func WithLabels(labels map[string]string) ParserOption {
+	return func(p *parser) error {
+		for programID, label := range labels {
+			p.labels[programID] = label
+		}
+		return nil
+	}
+}
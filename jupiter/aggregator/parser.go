@@ -0,0 +1,320 @@
+package aggregator
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// Hop is a swap through a single AMM, as reported by a SwapEvent.
+type Hop struct {
+	SwapEvent
+	// Label is the DEX label of the AMM program, empty if unknown.
+	Label string
+}
+
+// Trade is an invocation of a route instruction found in a transaction.
+type Trade struct {
+	Signature solana.Signature
+	Slot      uint64
+	BlockTime *time.Time
+	// InstructionIndex is the index of the top-level instruction the route was invoked from.
+	InstructionIndex int
+	// RouteIndex is the ordinal of the route within its top-level instruction, which can invoke several
+	// routes through CPI.
+	RouteIndex int
+	// CPI is true when the route was invoked by another program.
+	CPI   bool
+	Route Route
+	Hops  []Hop
+}
+
+type parser struct {
+	labels map[string]string
+}
+
+// NewParser creates a parser of Jupiter swaps executed by on-chain transactions.
+func NewParser(opts ...ParserOption) (Parser, error) {
+	p := &parser{
+		labels: make(map[string]string, len(DefaultLabels)),
+	}
+
+	for programID, label := range DefaultLabels {
+		p.labels[programID] = label
+	}
+
+	for _, opt := range opts {
+		if err := opt(p); err != nil {
+			return nil, fmt.Errorf("could not apply option: %w", err)
+		}
+	}
+
+	return p, nil
+}
+
+// ParseTransaction returns the Jupiter routes invoked by the transaction, directly or through CPI,
+// with their hops. The transaction must be fetched with the base64 or json encoding.
+// Hops are read from the event CPI instructions and, for older transactions, from the logs.
+// A failed transaction has no trades.
+func (p parser) ParseTransaction(result *rpc.GetTransactionResult) ([]Trade, error) {
+	if result == nil || result.Transaction == nil || result.Meta == nil {
+		return nil, fmt.Errorf("transaction and meta are required")
+	}
+
+	if result.Meta.Err != nil {
+		return nil, nil
+	}
+
+	tx, err := result.Transaction.GetTransaction()
+	if err != nil {
+		return nil, fmt.Errorf("could not decode transaction: %w", err)
+	}
+
+	keys := append(solana.PublicKeySlice{}, tx.Message.AccountKeys...)
+	keys = append(keys, result.Meta.LoadedAddresses.Writable...)
+	keys = append(keys, result.Meta.LoadedAddresses.ReadOnly...)
+
+	inner := make(map[int][]rpc.CompiledInstruction, len(result.Meta.InnerInstructions))
+	for _, ii := range result.Meta.InnerInstructions {
+		inner[int(ii.Index)] = ii.Instructions
+	}
+
+	base := Trade{Slot: result.Slot}
+
+	if len(tx.Signatures) > 0 {
+		base.Signature = tx.Signatures[0]
+	}
+
+	if result.BlockTime != nil {
+		blockTime := result.BlockTime.Time()
+		base.BlockTime = &blockTime
+	}
+
+	logEvents := logSwapEvents(result.Meta.LogMessages)
+
+	var trades []Trade
+
+	for i, ix := range tx.Message.Instructions {
+		found, err := p.parseInstruction(base, i, ix, inner[i], keys)
+		if err != nil {
+			return nil, fmt.Errorf("instruction %d: %w", i, err)
+		}
+
+		// Older versions of the program emit events in the logs only.
+		for j := range found {
+			if len(found[j].trade.Hops) > 0 {
+				continue
+			}
+
+			for _, event := range logEvents[frameKey{instruction: i, frame: found[j].frame}] {
+				found[j].trade.Hops = append(found[j].trade.Hops, p.hop(event))
+			}
+		}
+
+		for _, f := range found {
+			trades = append(trades, f.trade)
+		}
+	}
+
+	return trades, nil
+}
+
+// frameKey identifies an invocation of the program that is not nested in another invocation of the program:
+// frame is its ordinal within the top-level instruction.
+type frameKey struct {
+	instruction int
+	frame       int
+}
+
+type foundTrade struct {
+	trade Trade
+	frame int
+}
+
+func (p parser) parseInstruction(
+	base Trade,
+	index int,
+	ix solana.CompiledInstruction,
+	innerInstructions []rpc.CompiledInstruction,
+	keys solana.PublicKeySlice,
+) ([]foundTrade, error) {
+	if int(ix.ProgramIDIndex) >= len(keys) {
+		return nil, fmt.Errorf("invalid program index %d", ix.ProgramIDIndex)
+	}
+
+	var (
+		found []foundTrade
+		frame = -1
+		// stack holds the programs of the current invocation chain, by stack height.
+		stack = []solana.PublicKey{keys[ix.ProgramIDIndex]}
+	)
+
+	addRoute := func(compiled solana.CompiledInstruction, cpi bool) error {
+		frame++
+
+		route, err := DecodeCompiledInstruction(compiled, keys)
+		if errors.Is(err, ErrUnknownInstruction) {
+			return nil
+		}
+
+		if err != nil && !errors.Is(err, ErrUnknownSwap) {
+			return fmt.Errorf("could not decode route: %w", err)
+		}
+
+		trade := base
+		trade.InstructionIndex = index
+		trade.RouteIndex = len(found)
+		trade.CPI = cpi
+		trade.Route = route
+
+		found = append(found, foundTrade{trade: trade, frame: frame})
+
+		return nil
+	}
+
+	if stack[0].Equals(ProgramID) {
+		if err := addRoute(ix, false); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, innerIx := range innerInstructions {
+		if int(innerIx.ProgramIDIndex) >= len(keys) {
+			return nil, fmt.Errorf("invalid inner program index %d", innerIx.ProgramIDIndex)
+		}
+
+		programID := keys[innerIx.ProgramIDIndex]
+
+		// Without stack heights, only the top-level program is known to enclose the instruction.
+		enclosing := stack[:1]
+		if height := int(innerIx.StackHeight); height >= 2 && height <= len(stack)+1 {
+			stack = append(stack[:height-1], programID)
+			enclosing = stack[:height-1]
+		}
+
+		if !programID.Equals(ProgramID) {
+			continue
+		}
+
+		if isEventCPI(innerIx.Data) {
+			event, err := DecodeSwapEvent(innerIx.Data)
+			if errors.Is(err, ErrUnknownEvent) {
+				continue
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			if len(found) > 0 {
+				last := &found[len(found)-1].trade
+				last.Hops = append(last.Hops, p.hop(event))
+			}
+
+			continue
+		}
+
+		if solana.PublicKeySlice(enclosing).Contains(ProgramID) {
+			continue
+		}
+
+		compiled := solana.CompiledInstruction{
+			ProgramIDIndex: innerIx.ProgramIDIndex,
+			Accounts:       innerIx.Accounts,
+			Data:           innerIx.Data,
+		}
+
+		if err := addRoute(compiled, true); err != nil {
+			return nil, err
+		}
+	}
+
+	return found, nil
+}
+
+func (p parser) hop(event SwapEvent) Hop {
+	return Hop{SwapEvent: event, Label: p.labels[event.AMM.String()]}
+}
+
+// logSwapEvents reads the swap events emitted in the "Program data:" logs of the program,
+// grouped by the invocation that emitted them.
+func logSwapEvents(logs []string) map[frameKey][]SwapEvent {
+	events := make(map[frameKey][]SwapEvent)
+
+	var (
+		stack       []solana.PublicKey
+		instruction = -1
+		frame       = -1
+	)
+
+	for _, line := range logs {
+		rest, ok := strings.CutPrefix(line, "Program ")
+		if !ok {
+			continue
+		}
+
+		if data, ok := strings.CutPrefix(rest, "data: "); ok {
+			if len(stack) == 0 || !stack[len(stack)-1].Equals(ProgramID) {
+				continue
+			}
+
+			raw, err := base64.StdEncoding.DecodeString(data)
+			if err != nil {
+				continue
+			}
+
+			event, err := DecodeSwapEvent(raw)
+			if err != nil {
+				continue
+			}
+
+			key := frameKey{instruction: instruction, frame: frame}
+			events[key] = append(events[key], event)
+
+			continue
+		}
+
+		fields := strings.Fields(rest)
+		if len(fields) < 2 {
+			continue
+		}
+
+		programID, err := solana.PublicKeyFromBase58(fields[0])
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case fields[1] == "invoke" && len(fields) == 3:
+			height, err := strconv.Atoi(strings.Trim(fields[2], "[]"))
+			if err != nil || height < 1 || height > len(stack)+1 {
+				continue
+			}
+
+			stack = stack[:height-1]
+
+			if height == 1 {
+				instruction++
+				frame = -1
+			}
+
+			if programID.Equals(ProgramID) && !solana.PublicKeySlice(stack).Contains(ProgramID) {
+				frame++
+			}
+
+			stack = append(stack, programID)
+		case fields[1] == "success" || strings.HasPrefix(fields[1], "failed"):
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	return events
+}
@@ -0,0 +1,307 @@
+package aggregator_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilkamo/jupiter-go/jupiter/aggregator"
+)
+
+var (
+	testDLMM      = solana.MustPublicKeyFromBase58("LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo")
+	testWhirlpool = solana.MustPublicKeyFromBase58("whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc")
+	testMidMint   = solana.MustPublicKeyFromBase58("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+	testBlockTime = solana.UnixTimeSeconds(1_700_000_000)
+)
+
+func swapEventData(amm, inputMint solana.PublicKey, in uint64, outputMint solana.PublicKey, out uint64) []byte {
+	sum := sha256.Sum256([]byte("event:SwapEvent"))
+
+	data := append([]byte{}, sum[:8]...)
+	data = append(data, amm[:]...)
+	data = append(data, inputMint[:]...)
+	data = binary.LittleEndian.AppendUint64(data, in)
+	data = append(data, outputMint[:]...)
+	data = binary.LittleEndian.AppendUint64(data, out)
+
+	return data
+}
+
+func eventCPIData(event []byte) []byte {
+	return append([]byte{0xe4, 0x45, 0xa5, 0x2e, 0x51, 0xcb, 0x9a, 0x1d}, event...)
+}
+
+// testTransaction builds a getTransaction result with the instructions of the fixture: the Jupiter
+// route is invoked at index 1, either directly or by the caller program if set.
+func testTransaction(t *testing.T, caller *solana.PublicKey) (*rpc.GetTransactionResult, solana.PublicKeySlice) {
+	t.Helper()
+
+	jupIx := loadSwapInstruction(t, "swapInstructionsWithJito.json")
+
+	var metas solana.AccountMetaSlice
+	for _, account := range jupIx.Accounts {
+		metas = append(metas, &solana.AccountMeta{
+			PublicKey:  solana.MustPublicKeyFromBase58(account.Pubkey),
+			IsSigner:   account.IsSigner,
+			IsWritable: account.IsWritable,
+		})
+	}
+
+	ixData, err := base64.StdEncoding.DecodeString(jupIx.Data)
+	require.NoError(t, err)
+
+	programID := aggregator.ProgramID
+	if caller != nil {
+		programID = *caller
+		metas = append(metas, solana.Meta(aggregator.ProgramID))
+	}
+
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{
+			solana.NewInstruction(solana.ComputeBudget, solana.AccountMetaSlice{}, []byte{2, 0x40, 0x0d, 0x03, 0x00}),
+			solana.NewInstruction(programID, metas, ixData),
+		},
+		solana.Hash{},
+		solana.TransactionPayer(solana.MustPublicKeyFromBase58(testWallet)),
+	)
+	require.NoError(t, err)
+
+	tx.Signatures = []solana.Signature{{1, 2, 3}}
+
+	txBytes, err := tx.MarshalBinary()
+	require.NoError(t, err)
+
+	envelope, err := json.Marshal([]string{base64.StdEncoding.EncodeToString(txBytes), "base64"})
+	require.NoError(t, err)
+
+	var result rpc.GetTransactionResult
+	require.NoError(t, json.Unmarshal([]byte(fmt.Sprintf(`{"slot":42,"transaction":%s,"meta":{}}`, envelope)), &result))
+
+	result.BlockTime = &testBlockTime
+
+	return &result, tx.Message.AccountKeys
+}
+
+func compiled(
+	t *testing.T,
+	keys solana.PublicKeySlice,
+	programID solana.PublicKey,
+	data []byte,
+	stackHeight uint16,
+	accounts ...solana.PublicKey,
+) rpc.CompiledInstruction {
+	t.Helper()
+
+	idx := func(key solana.PublicKey) uint16 {
+		for i, k := range keys {
+			if k.Equals(key) {
+				return uint16(i)
+			}
+		}
+		require.FailNow(t, "key not found", key.String())
+		return 0
+	}
+
+	ix := rpc.CompiledInstruction{ProgramIDIndex: idx(programID), Data: data, StackHeight: stackHeight}
+	for _, account := range accounts {
+		ix.Accounts = append(ix.Accounts, idx(account))
+	}
+
+	return ix
+}
+
+func TestParser_ParseTransaction(t *testing.T) {
+	p, err := aggregator.NewParser()
+	require.NoError(t, err)
+
+	source := solana.MustPublicKeyFromBase58(testSourceMint)
+	dest := solana.MustPublicKeyFromBase58(testDestMint)
+
+	firstHop := swapEventData(testDLMM, source, 100000, testMidMint, 500)
+	secondHop := swapEventData(testWhirlpool, testMidMint, 500, dest, 24300)
+
+	expectedHops := []aggregator.Hop{
+		{
+			SwapEvent: aggregator.SwapEvent{
+				AMM: testDLMM, InputMint: source, InputAmount: 100000, OutputMint: testMidMint, OutputAmount: 500,
+			},
+			Label: "Meteora DLMM",
+		},
+		{
+			SwapEvent: aggregator.SwapEvent{
+				AMM: testWhirlpool, InputMint: testMidMint, InputAmount: 500, OutputMint: dest, OutputAmount: 24300,
+			},
+			Label: "Whirlpool",
+		},
+	}
+
+	t.Run("route with event cpi", func(t *testing.T) {
+		result, keys := testTransaction(t, nil)
+		result.Meta.InnerInstructions = []rpc.InnerInstruction{{
+			Index: 1,
+			Instructions: []rpc.CompiledInstruction{
+				compiled(t, keys, solana.TokenProgramID, []byte{3}, 2),
+				compiled(t, keys, aggregator.ProgramID, eventCPIData(firstHop), 2, aggregator.EventAuthority),
+				compiled(t, keys, aggregator.ProgramID, eventCPIData(secondHop), 2, aggregator.EventAuthority),
+			},
+		}}
+
+		trades, err := p.ParseTransaction(result)
+		require.NoError(t, err)
+		require.Len(t, trades, 1)
+
+		trade := trades[0]
+		require.Equal(t, solana.Signature{1, 2, 3}, trade.Signature)
+		require.EqualValues(t, 42, trade.Slot)
+		require.Equal(t, testBlockTime.Time(), *trade.BlockTime)
+		require.Equal(t, 1, trade.InstructionIndex)
+		require.False(t, trade.CPI)
+		require.Equal(t, aggregator.KindSharedAccountsRoute, trade.Route.Kind)
+		require.EqualValues(t, 100000, trade.Route.InAmount)
+		require.Equal(t, expectedHops, trade.Hops)
+	})
+
+	t.Run("route invoked through cpi", func(t *testing.T) {
+		caller := solana.NewWallet().PublicKey()
+		result, keys := testTransaction(t, &caller)
+
+		jupIx := loadSwapInstruction(t, "swapInstructionsWithJito.json")
+		ixData, err := base64.StdEncoding.DecodeString(jupIx.Data)
+		require.NoError(t, err)
+
+		var accounts []solana.PublicKey
+		for _, account := range jupIx.Accounts {
+			accounts = append(accounts, solana.MustPublicKeyFromBase58(account.Pubkey))
+		}
+
+		result.Meta.InnerInstructions = []rpc.InnerInstruction{{
+			Index: 1,
+			Instructions: []rpc.CompiledInstruction{
+				compiled(t, keys, aggregator.ProgramID, ixData, 2, accounts...),
+				compiled(t, keys, aggregator.ProgramID, eventCPIData(firstHop), 3, aggregator.EventAuthority),
+				compiled(t, keys, aggregator.ProgramID, eventCPIData(secondHop), 3, aggregator.EventAuthority),
+			},
+		}}
+
+		trades, err := p.ParseTransaction(result)
+		require.NoError(t, err)
+		require.Len(t, trades, 1)
+		require.True(t, trades[0].CPI)
+		require.Equal(t, 1, trades[0].InstructionIndex)
+		require.Equal(t, expectedHops, trades[0].Hops)
+	})
+
+	t.Run("several routes in one instruction", func(t *testing.T) {
+		caller := solana.NewWallet().PublicKey()
+		result, keys := testTransaction(t, &caller)
+
+		jupIx := loadSwapInstruction(t, "swapInstructionsWithJito.json")
+		ixData, err := base64.StdEncoding.DecodeString(jupIx.Data)
+		require.NoError(t, err)
+
+		var accounts []solana.PublicKey
+		for _, account := range jupIx.Accounts {
+			accounts = append(accounts, solana.MustPublicKeyFromBase58(account.Pubkey))
+		}
+
+		result.Meta.InnerInstructions = []rpc.InnerInstruction{{
+			Index: 1,
+			Instructions: []rpc.CompiledInstruction{
+				compiled(t, keys, aggregator.ProgramID, ixData, 2, accounts...),
+				compiled(t, keys, aggregator.ProgramID, eventCPIData(firstHop), 3, aggregator.EventAuthority),
+				compiled(t, keys, aggregator.ProgramID, ixData, 2, accounts...),
+				compiled(t, keys, aggregator.ProgramID, eventCPIData(secondHop), 3, aggregator.EventAuthority),
+			},
+		}}
+
+		trades, err := p.ParseTransaction(result)
+		require.NoError(t, err)
+		require.Len(t, trades, 2)
+		require.Equal(t, []int{1, 1}, []int{trades[0].InstructionIndex, trades[1].InstructionIndex})
+		require.Equal(t, []int{0, 1}, []int{trades[0].RouteIndex, trades[1].RouteIndex})
+		require.Equal(t, expectedHops[:1], trades[0].Hops)
+		require.Equal(t, expectedHops[1:], trades[1].Hops)
+	})
+
+	t.Run("events in logs", func(t *testing.T) {
+		result, _ := testTransaction(t, nil)
+		result.Meta.LogMessages = []string{
+			"Program ComputeBudget111111111111111111111111111111 invoke [1]",
+			"Program ComputeBudget111111111111111111111111111111 success",
+			"Program JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4 invoke [1]",
+			"Program log: Instruction: SharedAccountsRoute",
+			"Program LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo invoke [2]",
+			"Program data: " + base64.StdEncoding.EncodeToString(swapEventData(solana.SystemProgramID, source, 1, dest, 1)),
+			"Program LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo success",
+			"Program data: " + base64.StdEncoding.EncodeToString(firstHop),
+			"Program data: " + base64.StdEncoding.EncodeToString(secondHop),
+			"Program JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4 success",
+		}
+
+		trades, err := p.ParseTransaction(result)
+		require.NoError(t, err)
+		require.Len(t, trades, 1)
+		require.Equal(t, expectedHops, trades[0].Hops)
+	})
+
+	t.Run("custom labels", func(t *testing.T) {
+		withLabels, err := aggregator.NewParser(aggregator.WithLabels(map[string]string{
+			testDLMM.String(): "DLMM",
+		}))
+		require.NoError(t, err)
+
+		result, keys := testTransaction(t, nil)
+		result.Meta.InnerInstructions = []rpc.InnerInstruction{{
+			Index: 1,
+			Instructions: []rpc.CompiledInstruction{
+				compiled(t, keys, aggregator.ProgramID, eventCPIData(firstHop), 2, aggregator.EventAuthority),
+			},
+		}}
+
+		trades, err := withLabels.ParseTransaction(result)
+		require.NoError(t, err)
+		require.Equal(t, "DLMM", trades[0].Hops[0].Label)
+	})
+
+	t.Run("failed transaction", func(t *testing.T) {
+		result, _ := testTransaction(t, nil)
+		result.Meta.Err = map[string]any{"InstructionError": []any{1, map[string]any{"Custom": 6001}}}
+
+		trades, err := p.ParseTransaction(result)
+		require.NoError(t, err)
+		require.Empty(t, trades)
+	})
+
+	t.Run("missing meta", func(t *testing.T) {
+		result, _ := testTransaction(t, nil)
+		result.Meta = nil
+
+		_, err := p.ParseTransaction(result)
+		require.EqualError(t, err, "transaction and meta are required")
+	})
+}
+
+func TestDecodeSwapEvent(t *testing.T) {
+	source := solana.MustPublicKeyFromBase58(testSourceMint)
+	data := swapEventData(testDLMM, source, 1, testMidMint, 2)
+
+	event, err := aggregator.DecodeSwapEvent(eventCPIData(data))
+	require.NoError(t, err)
+	require.Equal(t, aggregator.SwapEvent{
+		AMM: testDLMM, InputMint: source, InputAmount: 1, OutputMint: testMidMint, OutputAmount: 2,
+	}, event)
+
+	_, err = aggregator.DecodeSwapEvent(data[:50])
+	require.ErrorContains(t, err, "unexpected end of data")
+
+	_, err = aggregator.DecodeSwapEvent([]byte("not an event"))
+	require.ErrorIs(t, err, aggregator.ErrUnknownEvent)
+}
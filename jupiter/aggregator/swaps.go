@@ -0,0 +1,177 @@
+package aggregator
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// swapPayload returns the size in bytes of the payload of a Swap enum variant starting at b.
+type swapPayload func(b []byte) (int, error)
+
+func fixed(size int) swapPayload {
+	return func(b []byte) (int, error) {
+		if len(b) < size {
+			return 0, errUnexpectedEnd
+		}
+		return size, nil
+	}
+}
+
+var (
+	none = fixed(0)
+	// side, bool and u8 arguments are a single byte.
+	side = fixed(1)
+)
+
+// remainingAccountsInfo reads a RemainingAccountsInfo struct: a vector of (accounts type, length) pairs.
+func remainingAccountsInfo(b []byte) (int, error) {
+	if len(b) < 4 {
+		return 0, errUnexpectedEnd
+	}
+
+	size := 4 + int(binary.LittleEndian.Uint32(b[0:4]))*2
+	if len(b) < size {
+		return 0, errUnexpectedEnd
+	}
+
+	return size, nil
+}
+
+// optionalRemainingAccountsInfo reads a bool followed by an Option<RemainingAccountsInfo>.
+func optionalRemainingAccountsInfo(b []byte) (int, error) {
+	if len(b) < 2 {
+		return 0, errUnexpectedEnd
+	}
+
+	if b[1] == 0 {
+		return 2, nil
+	}
+
+	n, err := remainingAccountsInfo(b[2:])
+
+	return 2 + n, err
+}
+
+type swapVariant struct {
+	name    string
+	payload swapPayload
+}
+
+// swapVariants follows the order of the Swap enum of the Jupiter v6 IDL.
+var swapVariants = []swapVariant{
+	{"Saber", none},
+	{"SaberAddDecimalsDeposit", none},
+	{"SaberAddDecimalsWithdraw", none},
+	{"TokenSwap", none},
+	{"Sencha", none},
+	{"Step", none},
+	{"Cropper", none},
+	{"Raydium", none},
+	{"Crema", side},
+	{"Lifinity", none},
+	{"Mercurial", none},
+	{"Cykura", none},
+	{"Serum", side},
+	{"MarinadeDeposit", none},
+	{"MarinadeUnstake", none},
+	{"Aldrin", side},
+	{"AldrinV2", side},
+	{"Whirlpool", side},
+	{"Invariant", side},
+	{"Meteora", none},
+	{"GooseFX", none},
+	{"DeltaFi", side},
+	{"Balansol", none},
+	{"MarcoPolo", side},
+	{"Dradex", side},
+	{"LifinityV2", none},
+	{"RaydiumClmm", none},
+	{"Openbook", side},
+	{"Phoenix", side},
+	{"Symmetry", fixed(16)},
+	{"TokenSwapV2", none},
+	{"HeliumTreasuryManagementRedeemV0", none},
+	{"StakeDexStakeWrappedSol", none},
+	{"StakeDexSwapViaStake", fixed(4)},
+	{"GooseFXV2", none},
+	{"Perps", none},
+	{"PerpsAddLiquidity", none},
+	{"PerpsRemoveLiquidity", none},
+	{"MeteoraDlmm", none},
+	{"OpenBookV2", side},
+	{"RaydiumClmmV2", none},
+	{"StakeDexPrefundWithdrawStakeAndDepositStake", fixed(4)},
+	{"Clone", fixed(3)},
+	{"SanctumS", fixed(10)},
+	{"SanctumSAddLiquidity", fixed(5)},
+	{"SanctumSRemoveLiquidity", fixed(5)},
+	{"RaydiumCP", none},
+	{"WhirlpoolSwapV2", optionalRemainingAccountsInfo},
+	{"OneIntro", none},
+	{"PumpdotfunWrappedBuy", none},
+	{"PumpdotfunWrappedSell", none},
+	{"PerpsV2", none},
+	{"PerpsV2AddLiquidity", none},
+	{"PerpsV2RemoveLiquidity", none},
+	{"MoonshotWrappedBuy", none},
+	{"MoonshotWrappedSell", none},
+	{"StabbleStableSwap", none},
+	{"StabbleWeightedSwap", none},
+	{"Obric", side},
+	{"FoxBuyFromEstimatedCost", none},
+	{"FoxClaimPartial", side},
+	{"SolFi", side},
+	{"SolayerDelegateNoInit", none},
+	{"SolayerUndelegateNoInit", none},
+	{"TokenMill", side},
+	{"DaosFunBuy", none},
+	{"DaosFunSell", none},
+	{"ZeroFi", none},
+	{"StakeDexWithdrawWrappedSol", none},
+	{"VirtualsBuy", none},
+	{"VirtualsSell", none},
+	{"Perena", fixed(2)},
+	{"PumpdotfunAmmBuy", none},
+	{"PumpdotfunAmmSell", none},
+	{"Gamma", none},
+	{"MeteoraDlmmSwapV2", remainingAccountsInfo},
+	{"Woofi", none},
+	{"MeteoraDammV2", none},
+	{"MeteoraDynamicBondingCurveSwap", none},
+	{"StabbleStableSwapV2", none},
+	{"StabbleWeightedSwapV2", none},
+	{"RaydiumLaunchlabBuy", fixed(8)},
+	{"RaydiumLaunchlabSell", fixed(8)},
+	{"BoopdotfunWrappedBuy", none},
+	{"BoopdotfunWrappedSell", none},
+	{"Plasma", side},
+	{"GoonFi", fixed(2)},
+	{"HumidiFi", fixed(9)},
+	{"MeteoraDynamicBondingCurveSwapWithRemainingAccounts", none},
+	{"TesseraV", side},
+}
+
+func decodeSwap(b []byte) (Swap, int, error) {
+	if len(b) == 0 {
+		return Swap{}, 0, fmt.Errorf("could not read swap variant: %w", errUnexpectedEnd)
+	}
+
+	idx := b[0]
+	if int(idx) >= len(swapVariants) {
+		return Swap{Variant: idx}, 0, fmt.Errorf("%w: variant %d", ErrUnknownSwap, idx)
+	}
+
+	variant := swapVariants[idx]
+
+	n, err := variant.payload(b[1:])
+	if err != nil {
+		return Swap{}, 0, fmt.Errorf("could not read %s swap: %w", variant.name, err)
+	}
+
+	swap := Swap{Variant: idx, Name: variant.name}
+	if n > 0 {
+		swap.Data = append([]byte{}, b[1:1+n]...)
+	}
+
+	return swap, 1 + n, nil
+}
@@ -0,0 +1,40 @@
+package jupitertest
+
+import (
+	"net/http"
+	"time"
+)
+
+// Failure is a scripted response of a Server that replaces the fixture.
+type Failure struct {
+	// StatusCode and Body are the response. The fixture is served if StatusCode is zero.
+	StatusCode int
+	Body       string
+	// Delay is waited before responding, or until the request is cancelled.
+	Delay time.Duration
+	// Times is the number of requests the failure applies to, every following request if zero.
+	Times int
+}
+
+// RateLimited fails the next request with a 429 Too Many Requests.
+func RateLimited() Failure {
+	return Failure{
+		StatusCode: http.StatusTooManyRequests,
+		Body:       `{"message":"Rate limit exceeded"}`,
+		Times:      1,
+	}
+}
+
+// NoRoute fails the next request with the 400 Bad Request returned by /quote when no route is found.
+func NoRoute() Failure {
+	return Failure{
+		StatusCode: http.StatusBadRequest,
+		Body:       `{"error":"Could not find any route","errorCode":"COULD_NOT_FIND_ANY_ROUTE"}`,
+		Times:      1,
+	}
+}
+
+// Slow delays the next request before serving the fixture.
+func Slow(delay time.Duration) Failure {
+	return Failure{Delay: delay, Times: 1}
+}
@@ -0,0 +1,47 @@
+package jupitertest
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ilkamo/jupiter-go/jupiter"
+)
+
+//go:embed testdata/*.json
+var testdata embed.FS
+
+// Fixtures are the responses served by a Server.
+//
+// The default fixtures describe the same swap of 100000 lamports of wrapped SOL into JUP through two
+// Meteora DLMM pools, for the wallet BXzmfHxfEMcMj8hDccUNdrwXVNeybyfb2iV2nktE1VnJ. The /swap transaction
+// is a legacy transaction built from the /swap-instructions fixture, without a signature.
+type Fixtures struct {
+	Quote            jupiter.QuoteResponse
+	Swap             jupiter.SwapResponse
+	SwapInstructions jupiter.SwapInstructionsResponse
+	ProgramIDToLabel map[string]string
+}
+
+// DefaultFixtures returns a copy of the default fixtures, which tests can modify.
+func DefaultFixtures() Fixtures {
+	var f Fixtures
+
+	mustLoad("quote.json", &f.Quote)
+	mustLoad("swap.json", &f.Swap)
+	mustLoad("swapInstructions.json", &f.SwapInstructions)
+	mustLoad("programIdToLabel.json", &f.ProgramIDToLabel)
+
+	return f
+}
+
+func mustLoad(name string, v any) {
+	data, err := testdata.ReadFile("testdata/" + name)
+	if err != nil {
+		panic(fmt.Sprintf("could not read fixture %s: %v", name, err))
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		panic(fmt.Sprintf("could not unmarshal fixture %s: %v", name, err))
+	}
+}
@@ -0,0 +1,258 @@
+// Package jupitertest provides an in-process fake of the Jupiter Swap API serving fixtures,
+// with scripted failures and recorded requests, to test code built on the jupiter client.
+package jupitertest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/oapi-codegen/runtime"
+
+	"github.com/ilkamo/jupiter-go/jupiter"
+)
+
+const (
+	PathQuote            = "/quote"
+	PathSwap             = "/swap"
+	PathSwapInstructions = "/swap-instructions"
+	PathProgramIDToLabel = "/program-id-to-label"
+)
+
+// Request is a request received by a Server.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+	// Time is when the request was received.
+	Time time.Time
+}
+
+// QuoteGetParams decodes the query of a /quote request.
+func (r Request) QuoteGetParams() (jupiter.QuoteGetParams, error) {
+	var p jupiter.QuoteGetParams
+
+	params := []struct {
+		name     string
+		required bool
+		dest     any
+	}{
+		{"inputMint", true, &p.InputMint},
+		{"outputMint", true, &p.OutputMint},
+		{"amount", true, &p.Amount},
+		{"slippageBps", false, &p.SlippageBps},
+		{"swapMode", false, &p.SwapMode},
+		{"dexes", false, &p.Dexes},
+		{"excludeDexes", false, &p.ExcludeDexes},
+		{"restrictIntermediateTokens", false, &p.RestrictIntermediateTokens},
+		{"onlyDirectRoutes", false, &p.OnlyDirectRoutes},
+		{"asLegacyTransaction", false, &p.AsLegacyTransaction},
+		{"platformFeeBps", false, &p.PlatformFeeBps},
+		{"maxAccounts", false, &p.MaxAccounts},
+		{"dynamicSlippage", false, &p.DynamicSlippage},
+	}
+
+	for _, param := range params {
+		if err := runtime.BindQueryParameter("form", true, param.required, param.name, r.Query, param.dest); err != nil {
+			return jupiter.QuoteGetParams{}, err
+		}
+	}
+
+	return p, nil
+}
+
+// SwapRequest decodes the body of a /swap or /swap-instructions request.
+func (r Request) SwapRequest() (jupiter.SwapRequest, error) {
+	var body jupiter.SwapRequest
+	err := json.Unmarshal(r.Body, &body)
+
+	return body, err
+}
+
+// Server is a fake Jupiter Swap API listening on a local HTTP server.
+type Server struct {
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	fixtures Fixtures
+	failures map[string][]Failure
+	requests []Request
+}
+
+// NewServer starts a server serving the default fixtures. It must be closed after use.
+func NewServer() *Server {
+	s := &Server{
+		fixtures: DefaultFixtures(),
+		failures: make(map[string][]Failure),
+	}
+
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// URL is the base URL of the API, to be used instead of jupiter.DefaultAPIURL.
+func (s *Server) URL() string {
+	return s.srv.URL
+}
+
+// Client returns a jupiter client for the server.
+func (s *Server) Client(opts ...jupiter.ClientOption) (*jupiter.ClientWithResponses, error) {
+	return jupiter.NewClientWithResponses(s.srv.URL, opts...)
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// SetFixtures replaces the responses served from now on.
+func (s *Server) SetFixtures(f Fixtures) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fixtures = f
+}
+
+// Fail queues a failure for the requests to the path, e.g. PathQuote. Failures apply in the order they are queued.
+func (s *Server) Fail(path string, f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures[path] = append(s.failures[path], f)
+}
+
+// Requests returns the requests received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// RequestsTo returns the requests received so far for the path, in order.
+func (s *Server) RequestsTo(path string) []Request {
+	var requests []Request
+
+	for _, r := range s.Requests() {
+		if r.Path == path {
+			requests = append(requests, r)
+		}
+	}
+
+	return requests
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "could not read body", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   body,
+		Time:   time.Now(),
+	})
+	failure, failed := s.nextFailure(r.URL.Path)
+	fixtures := s.fixtures
+	s.mu.Unlock()
+
+	if failed && failure.Delay > 0 {
+		select {
+		case <-time.After(failure.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if failed && failure.StatusCode != 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(failure.StatusCode)
+		_, _ = io.WriteString(w, failure.Body)
+		return
+	}
+
+	switch {
+	case r.URL.Path == PathQuote && r.Method == http.MethodGet:
+		serveQuote(w, r, fixtures.Quote)
+	case r.URL.Path == PathSwap && r.Method == http.MethodPost:
+		serveSwap(w, body, fixtures.Swap)
+	case r.URL.Path == PathSwapInstructions && r.Method == http.MethodPost:
+		serveSwap(w, body, fixtures.SwapInstructions)
+	case r.URL.Path == PathProgramIDToLabel && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, fixtures.ProgramIDToLabel)
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+// nextFailure returns the failure applying to the next request to the path. The caller must hold s.mu.
+func (s *Server) nextFailure(path string) (Failure, bool) {
+	queue := s.failures[path]
+	if len(queue) == 0 {
+		return Failure{}, false
+	}
+
+	f := queue[0]
+
+	switch {
+	case f.Times == 1:
+		s.failures[path] = queue[1:]
+	case f.Times > 1:
+		queue[0].Times--
+	}
+
+	return f, true
+}
+
+func serveQuote(w http.ResponseWriter, r *http.Request, quote jupiter.QuoteResponse) {
+	params, err := (Request{Query: r.URL.Query()}).QuoteGetParams()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if params.InputMint == "" || params.OutputMint == "" || params.Amount == 0 {
+		writeError(w, http.StatusBadRequest, "inputMint, outputMint and a positive amount are required")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, quote)
+}
+
+func serveSwap(w http.ResponseWriter, body []byte, response any) {
+	var req jupiter.SwapRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "Failed to deserialize the JSON body")
+		return
+	}
+
+	if req.UserPublicKey == "" || req.QuoteResponse.InputMint == "" {
+		writeError(w, http.StatusBadRequest, "missing field userPublicKey or quoteResponse")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,226 @@
+package jupitertest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilkamo/jupiter-go/jupiter"
+	"github.com/ilkamo/jupiter-go/jupiter/jupitertest"
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+	"github.com/ilkamo/jupiter-go/solana/solanatest"
+	"github.com/ilkamo/jupiter-go/swap"
+)
+
+const testUserPublicKey = "BXzmfHxfEMcMj8hDccUNdrwXVNeybyfb2iV2nktE1VnJ"
+
+func newTestServer(t *testing.T) (*jupitertest.Server, *jupiter.ClientWithResponses) {
+	t.Helper()
+
+	srv := jupitertest.NewServer()
+	t.Cleanup(srv.Close)
+
+	client, err := srv.Client()
+	require.NoError(t, err)
+
+	return srv, client
+}
+
+func testQuoteParams() *jupiter.QuoteGetParams {
+	slippageBps := jupiter.SlippageParameter(250)
+	maxAccounts := jupiter.MaxAccountsParameter(32)
+	swapMode := jupiter.QuoteGetParamsSwapMode("ExactIn")
+	dexes := []string{"Meteora DLMM", "Whirlpool"}
+
+	return &jupiter.QuoteGetParams{
+		InputMint:   swap.WrappedSolMint,
+		OutputMint:  "JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN",
+		Amount:      100000,
+		SlippageBps: &slippageBps,
+		SwapMode:    &swapMode,
+		Dexes:       &dexes,
+		MaxAccounts: &maxAccounts,
+	}
+}
+
+func TestServer_Fixtures(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	fixtures := jupitertest.DefaultFixtures()
+
+	t.Run("quote", func(t *testing.T) {
+		srv, client := newTestServer(t)
+
+		params := testQuoteParams()
+
+		resp, err := client.QuoteGetWithResponse(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+		require.Equal(t, fixtures.Quote, *resp.JSON200)
+
+		requests := srv.RequestsTo(jupitertest.PathQuote)
+		require.Len(t, requests, 1)
+		require.Equal(t, http.MethodGet, requests[0].Method)
+		require.Equal(t, []string{"Meteora DLMM", "Whirlpool"}, requests[0].Query["dexes"])
+
+		decoded, err := requests[0].QuoteGetParams()
+		require.NoError(t, err)
+		require.Equal(t, *params, decoded)
+	})
+
+	t.Run("quote without required parameters", func(t *testing.T) {
+		_, client := newTestServer(t)
+
+		resp, err := client.QuoteGetWithResponse(ctx, &jupiter.QuoteGetParams{InputMint: swap.WrappedSolMint})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode())
+	})
+
+	t.Run("swap and swap instructions", func(t *testing.T) {
+		srv, client := newTestServer(t)
+
+		body := jupiter.SwapRequest{UserPublicKey: testUserPublicKey, QuoteResponse: fixtures.Quote}
+
+		swapResp, err := client.SwapPostWithResponse(ctx, body)
+		require.NoError(t, err)
+		require.Equal(t, fixtures.Swap, *swapResp.JSON200)
+
+		instructionsResp, err := client.SwapInstructionsPostWithResponse(ctx, body)
+		require.NoError(t, err)
+		require.Equal(t, fixtures.SwapInstructions, *instructionsResp.JSON200)
+
+		requests := srv.Requests()
+		require.Len(t, requests, 2)
+		require.Equal(t, jupitertest.PathSwapInstructions, requests[1].Path)
+
+		recorded, err := requests[0].SwapRequest()
+		require.NoError(t, err)
+		require.Equal(t, body, recorded)
+	})
+
+	t.Run("swap without quote", func(t *testing.T) {
+		_, client := newTestServer(t)
+
+		resp, err := client.SwapPostWithResponse(ctx, jupiter.SwapRequest{UserPublicKey: testUserPublicKey})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode())
+	})
+
+	t.Run("swap transaction matches the quote", func(t *testing.T) {
+		node := solanatest.NewServer()
+		defer node.Close()
+
+		node.SetAccount(solana.MustPublicKeyFromBase58(fixtures.Quote.OutputMint), solanatest.Account{
+			Owner: solana.TokenProgramID,
+		})
+
+		verifier, err := swap.NewVerifier(testUserPublicKey, node.URL())
+		require.NoError(t, err)
+
+		tx, err := jupSolana.NewTransactionFromBase64(fixtures.Swap.SwapTransaction)
+		require.NoError(t, err)
+		require.NoError(t, verifier.Verify(ctx, tx, fixtures.Quote))
+	})
+
+	t.Run("program id to label", func(t *testing.T) {
+		_, client := newTestServer(t)
+
+		resp, err := client.ProgramIdToLabelGetWithResponse(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "Meteora DLMM", (*resp.JSON200)["LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo"])
+	})
+
+	t.Run("custom fixtures", func(t *testing.T) {
+		srv, client := newTestServer(t)
+
+		custom := jupitertest.DefaultFixtures()
+		custom.Quote.OutAmount = "1"
+		srv.SetFixtures(custom)
+
+		resp, err := client.QuoteGetWithResponse(ctx, testQuoteParams())
+		require.NoError(t, err)
+		require.Equal(t, "1", resp.JSON200.OutAmount)
+		require.Equal(t, "24266", jupitertest.DefaultFixtures().Quote.OutAmount)
+	})
+}
+
+func TestServer_Fail(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	t.Run("rate limited once", func(t *testing.T) {
+		srv, client := newTestServer(t)
+
+		srv.Fail(jupitertest.PathQuote, jupitertest.RateLimited())
+
+		resp, err := client.QuoteGetWithResponse(ctx, testQuoteParams())
+		require.NoError(t, err)
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode())
+		require.Nil(t, resp.JSON200)
+
+		resp, err = client.QuoteGetWithResponse(ctx, testQuoteParams())
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+	})
+
+	t.Run("no route", func(t *testing.T) {
+		srv, client := newTestServer(t)
+
+		srv.Fail(jupitertest.PathQuote, jupitertest.NoRoute())
+
+		resp, err := client.QuoteGetWithResponse(ctx, testQuoteParams())
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode())
+		require.Contains(t, string(resp.Body), "COULD_NOT_FIND_ANY_ROUTE")
+	})
+
+	t.Run("failures apply in order and only to their path", func(t *testing.T) {
+		srv, client := newTestServer(t)
+
+		srv.Fail(jupitertest.PathSwap, jupitertest.Failure{StatusCode: http.StatusInternalServerError, Times: 2})
+		srv.Fail(jupitertest.PathSwap, jupitertest.RateLimited())
+
+		body := jupiter.SwapRequest{UserPublicKey: testUserPublicKey, QuoteResponse: jupitertest.DefaultFixtures().Quote}
+
+		var statuses []int
+
+		for range 4 {
+			resp, err := client.SwapPostWithResponse(ctx, body)
+			require.NoError(t, err)
+
+			statuses = append(statuses, resp.StatusCode())
+		}
+
+		require.Equal(t, []int{500, 500, 429, 200}, statuses)
+
+		resp, err := client.QuoteGetWithResponse(ctx, testQuoteParams())
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+	})
+
+	t.Run("slow response", func(t *testing.T) {
+		srv, client := newTestServer(t)
+
+		srv.Fail(jupitertest.PathQuote, jupitertest.Slow(time.Second))
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		_, err := client.QuoteGetWithResponse(timeoutCtx, testQuoteParams())
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		srv.Fail(jupitertest.PathQuote, jupitertest.Slow(50*time.Millisecond))
+
+		start := time.Now()
+
+		resp, err := client.QuoteGetWithResponse(ctx, testQuoteParams())
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+		require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	})
+}
@@ -0,0 +1,279 @@
+package jupiter
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+const (
+	// MaxBps is the upper bound of the slippage and platform fee, 100%.
+	MaxBps = 10_000
+
+	defaultDexLabelsMaxAge = time.Hour
+)
+
+// FieldError describes an invalid field of a request, named as in the JSON of the API.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationError lists the invalid fields of a request.
+type ValidationError []FieldError
+
+func (e ValidationError) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Error()
+	}
+
+	return "invalid request: " + strings.Join(messages, "; ")
+}
+
+// Unwrap returns the field errors, so that errors.As can find a FieldError.
+func (e ValidationError) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, fieldErr := range e {
+		errs[i] = fieldErr
+	}
+
+	return errs
+}
+
+type validation ValidationError
+
+func (v *validation) add(field, format string, args ...any) {
+	*v = append(*v, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validation) publicKey(field, value string) {
+	if value == "" {
+		v.add(field, "is required")
+		return
+	}
+
+	if _, err := solana.PublicKeyFromBase58(value); err != nil {
+		v.add(field, "invalid public key %q", value)
+	}
+}
+
+func (v *validation) optionalPublicKey(field string, value *string) {
+	if value != nil {
+		v.publicKey(field, *value)
+	}
+}
+
+func (v *validation) bps(field string, value uint64) {
+	if value > MaxBps {
+		v.add(field, "%d bps is above %d", value, MaxBps)
+	}
+}
+
+func (v *validation) amount(field, value string) {
+	amount, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		v.add(field, "invalid amount %q", value)
+		return
+	}
+
+	if amount == 0 {
+		v.add(field, "must be positive")
+	}
+}
+
+func (v *validation) err() error {
+	if len(*v) == 0 {
+		return nil
+	}
+
+	return ValidationError(*v)
+}
+
+// Validate checks the parameters before calling /quote: public keys, amount, bps bounds, swap mode
+// and mutually exclusive fields. Use DexLabels.Validate to check the DEX names too.
+func (p QuoteGetParams) Validate() error {
+	v := p.validate()
+	return v.err()
+}
+
+func (p QuoteGetParams) validate() validation {
+	var v validation
+
+	v.publicKey("inputMint", p.InputMint)
+	v.publicKey("outputMint", p.OutputMint)
+
+	if p.InputMint != "" && p.InputMint == p.OutputMint {
+		v.add("outputMint", "must differ from inputMint")
+	}
+
+	if p.Amount == 0 {
+		v.add("amount", "must be positive")
+	}
+
+	if p.SlippageBps != nil {
+		v.bps("slippageBps", *p.SlippageBps)
+	}
+
+	if p.PlatformFeeBps != nil {
+		v.bps("platformFeeBps", *p.PlatformFeeBps)
+	}
+
+	if p.SwapMode != nil && *p.SwapMode != ExactIn && *p.SwapMode != ExactOut {
+		v.add("swapMode", "unknown swap mode %q", *p.SwapMode)
+	}
+
+	if p.MaxAccounts != nil && *p.MaxAccounts == 0 {
+		v.add("maxAccounts", "must be positive")
+	}
+
+	if p.Dexes != nil && p.ExcludeDexes != nil {
+		v.add("excludeDexes", "cannot be combined with dexes")
+	}
+
+	if p.Dexes != nil && slices.Contains(*p.Dexes, "") {
+		v.add("dexes", "empty DEX name")
+	}
+
+	if p.ExcludeDexes != nil && slices.Contains(*p.ExcludeDexes, "") {
+		v.add("excludeDexes", "empty DEX name")
+	}
+
+	return v
+}
+
+// Validate checks the request before calling /swap or /swap-instructions: public keys, the quote response,
+// and the prioritization fee, which is either a Jito tip or a priority fee.
+func (r SwapRequest) Validate() error {
+	var v validation
+
+	v.publicKey("userPublicKey", r.UserPublicKey)
+	v.optionalPublicKey("payer", r.Payer)
+	v.optionalPublicKey("feeAccount", r.FeeAccount)
+	v.optionalPublicKey("trackingAccount", r.TrackingAccount)
+	v.optionalPublicKey("destinationTokenAccount", r.DestinationTokenAccount)
+
+	q := r.QuoteResponse
+	v.publicKey("quoteResponse.inputMint", q.InputMint)
+	v.publicKey("quoteResponse.outputMint", q.OutputMint)
+	v.amount("quoteResponse.inAmount", q.InAmount)
+	v.amount("quoteResponse.outAmount", q.OutAmount)
+	v.bps("quoteResponse.slippageBps", q.SlippageBps)
+
+	if q.SwapMode != SwapModeExactIn && q.SwapMode != SwapModeExactOut {
+		v.add("quoteResponse.swapMode", "unknown swap mode %q", q.SwapMode)
+	}
+
+	if fee := r.PrioritizationFeeLamports; fee != nil {
+		if fee.JitoTipLamports != nil && fee.PriorityLevelWithMaxLamports != nil {
+			v.add("prioritizationFeeLamports", "jitoTipLamports cannot be combined with priorityLevelWithMaxLamports")
+		}
+
+		if p := fee.PriorityLevelWithMaxLamports; p != nil && p.PriorityLevel != nil {
+			switch *p.PriorityLevel {
+			case Medium, High, VeryHigh:
+			default:
+				v.add("prioritizationFeeLamports.priorityLevelWithMaxLamports.priorityLevel",
+					"unknown priority level %q", *p.PriorityLevel)
+			}
+		}
+	}
+
+	return v.err()
+}
+
+// DexLabels caches the DEX labels of /program-id-to-label to validate the DEX names of quotes.
+type DexLabels struct {
+	client ClientWithResponsesInterface
+	maxAge time.Duration
+
+	mu        sync.Mutex
+	labels    map[string]struct{}
+	fetchedAt time.Time
+}
+
+// NewDexLabels creates a cache of the DEX labels, fetched again when older than maxAge, one hour if zero.
+func NewDexLabels(client ClientWithResponsesInterface, maxAge time.Duration) (*DexLabels, error) {
+	if client == nil {
+		return nil, fmt.Errorf("jupiter client is required")
+	}
+
+	if maxAge < 0 {
+		return nil, fmt.Errorf("max age cannot be negative")
+	}
+
+	if maxAge == 0 {
+		maxAge = defaultDexLabelsMaxAge
+	}
+
+	return &DexLabels{client: client, maxAge: maxAge}, nil
+}
+
+// Validate checks the parameters like QuoteGetParams.Validate and that their DEX names are known labels.
+// An error fetching the labels is returned as is, not as a ValidationError.
+func (d *DexLabels) Validate(ctx context.Context, p QuoteGetParams) error {
+	v := p.validate()
+
+	if p.Dexes == nil && p.ExcludeDexes == nil {
+		return v.err()
+	}
+
+	labels, err := d.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range []struct {
+		name  string
+		dexes *[]string
+	}{{"dexes", p.Dexes}, {"excludeDexes", p.ExcludeDexes}} {
+		if field.dexes == nil {
+			continue
+		}
+
+		for _, dex := range *field.dexes {
+			if _, ok := labels[dex]; !ok && dex != "" {
+				v.add(field.name, "unknown DEX %q", dex)
+			}
+		}
+	}
+
+	return v.err()
+}
+
+func (d *DexLabels) get(ctx context.Context) (map[string]struct{}, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.labels != nil && time.Since(d.fetchedAt) <= d.maxAge {
+		return d.labels, nil
+	}
+
+	resp, err := d.client.ProgramIdToLabelGetWithResponse(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get DEX labels: %w", err)
+	}
+
+	if resp.JSON200 == nil {
+		return nil, fmt.Errorf("could not get DEX labels: %s: %s", resp.Status(), resp.Body)
+	}
+
+	labels := make(map[string]struct{}, len(*resp.JSON200))
+	for _, label := range *resp.JSON200 {
+		labels[label] = struct{}{}
+	}
+
+	d.labels, d.fetchedAt = labels, time.Now()
+
+	return labels, nil
+}
@@ -0,0 +1,290 @@
+package jupiter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSolMint = "So11111111111111111111111111111111111111112"
+	testJupMint = "JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN"
+	testWallet  = "BXzmfHxfEMcMj8hDccUNdrwXVNeybyfb2iV2nktE1VnJ"
+)
+
+type labelsMock struct {
+	ClientWithResponsesInterface
+	calls      *int
+	statusCode int
+}
+
+func (m labelsMock) ProgramIdToLabelGetWithResponse(
+	_ context.Context,
+	_ ...RequestEditorFn,
+) (*ProgramIdToLabelGetResponse, error) {
+	*m.calls++
+
+	if m.statusCode != 0 {
+		return &ProgramIdToLabelGetResponse{
+			HTTPResponse: &http.Response{StatusCode: m.statusCode, Status: http.StatusText(m.statusCode)},
+		}, nil
+	}
+
+	return &ProgramIdToLabelGetResponse{
+		HTTPResponse: &http.Response{StatusCode: http.StatusOK},
+		JSON200: &map[string]string{
+			"LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo": "Meteora DLMM",
+			"whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc": "Whirlpool",
+		},
+	}, nil
+}
+
+// priorityLevel, priorityLevelWithMaxLamports and prioritizationFee name the anonymous types of
+// SwapRequest.PrioritizationFeeLamports.
+type priorityLevel = SwapRequestPrioritizationFeeLamportsPriorityLevelWithMaxLamportsPriorityLevel
+
+type priorityLevelWithMaxLamports = struct {
+	MaxLamports   *uint64        `json:"maxLamports,omitempty"`
+	PriorityLevel *priorityLevel `json:"priorityLevel,omitempty"`
+}
+
+type prioritizationFee = struct {
+	JitoTipLamports              *uint64                       `json:"jitoTipLamports,omitempty"`
+	PriorityLevelWithMaxLamports *priorityLevelWithMaxLamports `json:"priorityLevelWithMaxLamports,omitempty"`
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestQuoteGetParams_Validate(t *testing.T) {
+	valid := QuoteGetParams{InputMint: testSolMint, OutputMint: testJupMint, Amount: 100000}
+
+	t.Run("valid", func(t *testing.T) {
+		p := valid
+		p.SlippageBps = ptr(uint64(MaxBps))
+		p.SwapMode = ptr(ExactOut)
+		p.Dexes = &[]string{"Whirlpool"}
+
+		require.NoError(t, p.Validate())
+	})
+
+	tests := []struct {
+		name   string
+		modify func(*QuoteGetParams)
+		errs   []FieldError
+	}{
+		{
+			name:   "malformed mints",
+			modify: func(p *QuoteGetParams) { p.InputMint, p.OutputMint = "", "not-a-mint" },
+			errs: []FieldError{
+				{Field: "inputMint", Message: "is required"},
+				{Field: "outputMint", Message: `invalid public key "not-a-mint"`},
+			},
+		},
+		{
+			name:   "same mints",
+			modify: func(p *QuoteGetParams) { p.OutputMint = testSolMint },
+			errs:   []FieldError{{Field: "outputMint", Message: "must differ from inputMint"}},
+		},
+		{
+			name:   "zero amount",
+			modify: func(p *QuoteGetParams) { p.Amount = 0 },
+			errs:   []FieldError{{Field: "amount", Message: "must be positive"}},
+		},
+		{
+			name: "bps above 100%",
+			modify: func(p *QuoteGetParams) {
+				p.SlippageBps = ptr(uint64(10_001))
+				p.PlatformFeeBps = ptr(uint64(20_000))
+			},
+			errs: []FieldError{
+				{Field: "slippageBps", Message: "10001 bps is above 10000"},
+				{Field: "platformFeeBps", Message: "20000 bps is above 10000"},
+			},
+		},
+		{
+			name:   "unknown swap mode",
+			modify: func(p *QuoteGetParams) { p.SwapMode = ptr(QuoteGetParamsSwapMode("exactIn")) },
+			errs:   []FieldError{{Field: "swapMode", Message: `unknown swap mode "exactIn"`}},
+		},
+		{
+			name:   "zero max accounts",
+			modify: func(p *QuoteGetParams) { p.MaxAccounts = ptr(uint64(0)) },
+			errs:   []FieldError{{Field: "maxAccounts", Message: "must be positive"}},
+		},
+		{
+			name: "dexes and excluded dexes",
+			modify: func(p *QuoteGetParams) {
+				p.Dexes = &[]string{"Whirlpool"}
+				p.ExcludeDexes = &[]string{""}
+			},
+			errs: []FieldError{
+				{Field: "excludeDexes", Message: "cannot be combined with dexes"},
+				{Field: "excludeDexes", Message: "empty DEX name"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid
+			tt.modify(&p)
+
+			err := p.Validate()
+
+			var validationErr ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			require.Equal(t, tt.errs, []FieldError(validationErr))
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		p := valid
+		p.Amount = 0
+		p.SlippageBps = ptr(uint64(10_001))
+
+		err := p.Validate()
+		require.EqualError(t, err, "invalid request: amount: must be positive; slippageBps: 10001 bps is above 10000")
+
+		var fieldErr FieldError
+		require.ErrorAs(t, err, &fieldErr)
+		require.Equal(t, "amount", fieldErr.Field)
+	})
+}
+
+func TestSwapRequest_Validate(t *testing.T) {
+	valid := SwapRequest{
+		UserPublicKey: testWallet,
+		QuoteResponse: QuoteResponse{
+			InputMint:   testSolMint,
+			OutputMint:  testJupMint,
+			InAmount:    "100000",
+			OutAmount:   "24266",
+			SlippageBps: 50,
+			SwapMode:    SwapModeExactIn,
+		},
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		r := valid
+		r.Payer = ptr(testWallet)
+		r.PrioritizationFeeLamports = &prioritizationFee{JitoTipLamports: ptr(uint64(1_000))}
+
+		require.NoError(t, r.Validate())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		r := valid
+		r.UserPublicKey = ""
+		r.FeeAccount = ptr("invalid")
+		r.QuoteResponse.InAmount = "0"
+		r.QuoteResponse.OutAmount = "-1"
+		r.QuoteResponse.SlippageBps = 10_001
+		r.QuoteResponse.SwapMode = ""
+		r.PrioritizationFeeLamports = &prioritizationFee{
+			JitoTipLamports: ptr(uint64(1_000)),
+			PriorityLevelWithMaxLamports: &priorityLevelWithMaxLamports{
+				PriorityLevel: ptr(priorityLevel("max")),
+			},
+		}
+
+		var validationErr ValidationError
+		require.ErrorAs(t, r.Validate(), &validationErr)
+		require.Equal(t, []FieldError{
+			{Field: "userPublicKey", Message: "is required"},
+			{Field: "feeAccount", Message: `invalid public key "invalid"`},
+			{Field: "quoteResponse.inAmount", Message: "must be positive"},
+			{Field: "quoteResponse.outAmount", Message: `invalid amount "-1"`},
+			{Field: "quoteResponse.slippageBps", Message: "10001 bps is above 10000"},
+			{Field: "quoteResponse.swapMode", Message: `unknown swap mode ""`},
+			{
+				Field:   "prioritizationFeeLamports",
+				Message: "jitoTipLamports cannot be combined with priorityLevelWithMaxLamports",
+			},
+			{
+				Field:   "prioritizationFeeLamports.priorityLevelWithMaxLamports.priorityLevel",
+				Message: `unknown priority level "max"`,
+			},
+		}, []FieldError(validationErr))
+	})
+}
+
+func TestDexLabels_Validate(t *testing.T) {
+	valid := QuoteGetParams{InputMint: testSolMint, OutputMint: testJupMint, Amount: 100000}
+
+	t.Run("invalid params", func(t *testing.T) {
+		_, err := NewDexLabels(nil, 0)
+		require.EqualError(t, err, "jupiter client is required")
+
+		_, err = NewDexLabels(labelsMock{}, -time.Second)
+		require.EqualError(t, err, "max age cannot be negative")
+	})
+
+	t.Run("known and unknown dexes", func(t *testing.T) {
+		var calls int
+
+		labels, err := NewDexLabels(labelsMock{calls: &calls}, 0)
+		require.NoError(t, err)
+
+		p := valid
+		p.Dexes = &[]string{"Whirlpool", "Meteora DLMM"}
+		require.NoError(t, labels.Validate(context.TODO(), p))
+
+		p.Dexes = nil
+		p.ExcludeDexes = &[]string{"Orca V3"}
+		p.Amount = 0
+
+		var validationErr ValidationError
+		require.ErrorAs(t, labels.Validate(context.TODO(), p), &validationErr)
+		require.Equal(t, []FieldError{
+			{Field: "amount", Message: "must be positive"},
+			{Field: "excludeDexes", Message: `unknown DEX "Orca V3"`},
+		}, []FieldError(validationErr))
+
+		require.Equal(t, 1, calls, "the labels must be cached")
+	})
+
+	t.Run("without dexes", func(t *testing.T) {
+		var calls int
+
+		labels, err := NewDexLabels(labelsMock{calls: &calls}, 0)
+		require.NoError(t, err)
+
+		require.NoError(t, labels.Validate(context.TODO(), valid))
+		require.Zero(t, calls)
+	})
+
+	t.Run("refresh when too old", func(t *testing.T) {
+		var calls int
+
+		labels, err := NewDexLabels(labelsMock{calls: &calls}, time.Nanosecond)
+		require.NoError(t, err)
+
+		p := valid
+		p.Dexes = &[]string{"Whirlpool"}
+
+		require.NoError(t, labels.Validate(context.TODO(), p))
+		time.Sleep(time.Millisecond)
+		require.NoError(t, labels.Validate(context.TODO(), p))
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("labels error", func(t *testing.T) {
+		var calls int
+
+		labels, err := NewDexLabels(labelsMock{calls: &calls, statusCode: http.StatusInternalServerError}, 0)
+		require.NoError(t, err)
+
+		p := valid
+		p.Dexes = &[]string{"Whirlpool"}
+
+		err = labels.Validate(context.TODO(), p)
+		require.EqualError(t, err, "could not get DEX labels: Internal Server Error: ")
+		require.False(t, errors.As(err, new(ValidationError)))
+	})
+}
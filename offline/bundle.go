@@ -0,0 +1,334 @@
+// Package offline carries swap transactions to an air-gapped machine to be signed, and back to be broadcast.
+//
+// The online machine builds the swap and saves it with its context in a bundle file. The offline machine
+// signs the bundle without any network access, and the online machine broadcasts the signed transaction.
+// The transaction expires with its blockhash, after about a minute: the round trip must be quick, or the
+// transaction must use a durable nonce, see solana.SetDurableNonce.
+package offline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+
+	"github.com/ilkamo/jupiter-go/jupiter"
+	"github.com/ilkamo/jupiter-go/jupiter/aggregator"
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+)
+
+const bundleVersion = 1
+
+var (
+	// ErrMissingSignatures is returned when a bundle is not signed by all its required signers.
+	ErrMissingSignatures = errors.New("missing signatures")
+	// ErrQuoteMismatch is returned when the route instruction of a swap bundle does not match its quote.
+	ErrQuoteMismatch = errors.New("transaction does not match the quote")
+)
+
+// Bundle is an unsigned transaction with the context needed to review, sign and broadcast it.
+type Bundle struct {
+	Version int `json:"version"`
+	// Transaction is the base64 transaction, with empty signatures.
+	Transaction string `json:"transaction"`
+	// Quote is the quote the swap transaction was built from, for review before signing.
+	Quote *jupiter.QuoteResponse `json:"quote,omitempty"`
+	// Blockhash is the recent blockhash of the transaction, or the nonce of a durable nonce transaction.
+	Blockhash string `json:"blockhash"`
+	// LastValidBlockHeight is zero for a durable nonce transaction, which expires only when its nonce is advanced.
+	LastValidBlockHeight uint64 `json:"lastValidBlockHeight"`
+	// NonceAccount is the nonce account of a durable nonce transaction.
+	NonceAccount string `json:"nonceAccount,omitempty"`
+	// Signers are the public keys whose signatures the transaction requires, the fee payer first.
+	Signers []string `json:"signers"`
+	// Signatures are the base58 signatures added so far, by signer.
+	Signatures map[string]string `json:"signatures,omitempty"`
+	CreatedAt  time.Time         `json:"createdAt"`
+}
+
+// NewSwapBundle creates a bundle from a /swap response and the quote it was built from.
+func NewSwapBundle(quote jupiter.QuoteResponse, swapResp jupiter.SwapResponse) (Bundle, error) {
+	b, err := NewBundle(swapResp.SwapTransaction, swapResp.LastValidBlockHeight)
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	b.Quote = &quote
+
+	return b, nil
+}
+
+// NewBundle creates a bundle from a base64 transaction and the last block height at which its blockhash is valid,
+// which is ignored for a durable nonce transaction.
+func NewBundle(txBase64 string, lastValidBlockHeight uint64) (Bundle, error) {
+	tx, err := jupSolana.NewTransactionFromBase64(txBase64)
+	if err != nil {
+		return Bundle{}, fmt.Errorf("could not deserialize transaction: %w", err)
+	}
+
+	required := int(tx.Message.Header.NumRequiredSignatures)
+	if required == 0 || required > len(tx.Message.AccountKeys) {
+		return Bundle{}, fmt.Errorf("invalid transaction: %d required signatures", required)
+	}
+
+	tx.Signatures = make([]solana.Signature, required)
+
+	unsigned, err := tx.ToBase64()
+	if err != nil {
+		return Bundle{}, fmt.Errorf("could not serialize transaction: %w", err)
+	}
+
+	b := Bundle{
+		Version:              bundleVersion,
+		Transaction:          unsigned,
+		Blockhash:            tx.Message.RecentBlockhash.String(),
+		LastValidBlockHeight: lastValidBlockHeight,
+		CreatedAt:            time.Now().UTC().Truncate(time.Second),
+	}
+
+	if nonceAccount, ok := jupSolana.DurableNonce(tx); ok {
+		b.NonceAccount = nonceAccount.String()
+		b.LastValidBlockHeight = 0
+	}
+
+	for _, signer := range tx.Message.AccountKeys[:required] {
+		b.Signers = append(b.Signers, signer.String())
+	}
+
+	return b, nil
+}
+
+// Load reads a bundle file.
+func Load(path string) (Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Bundle{}, fmt.Errorf("could not read bundle: %w", err)
+	}
+
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return Bundle{}, fmt.Errorf("could not unmarshal bundle: %w", err)
+	}
+
+	if b.Version != bundleVersion {
+		return Bundle{}, fmt.Errorf("unsupported bundle version %d", b.Version)
+	}
+
+	return b, nil
+}
+
+// Save writes the bundle file, creating its directory if needed.
+func (b Bundle) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal bundle: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("could not create bundle directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("could not write bundle: %w", err)
+	}
+
+	return nil
+}
+
+// Sign signs the transaction with the wallet, which must be one of the required signers. It needs no network.
+// If the bundle has a quote, the Jupiter route instruction of the transaction must match its amounts and slippage.
+func (b Bundle) Sign(wallet jupSolana.Wallet) (Bundle, error) {
+	tx, err := b.transaction()
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	if b.Quote != nil {
+		if err := checkQuote(tx, *b.Quote); err != nil {
+			return Bundle{}, err
+		}
+	}
+
+	signer := wallet.PublicKey().String()
+	if !b.isSigner(signer) {
+		return Bundle{}, fmt.Errorf("%s is not a signer of the transaction", signer)
+	}
+
+	message, err := tx.Message.MarshalBinary()
+	if err != nil {
+		return Bundle{}, fmt.Errorf("could not serialize transaction message: %w", err)
+	}
+
+	signature, err := wallet.SignMessage(message)
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	signatures := make(map[string]string, len(b.Signatures)+1)
+	for k, v := range b.Signatures {
+		signatures[k] = v
+	}
+
+	signatures[signer] = signature.String()
+	b.Signatures = signatures
+
+	return b, nil
+}
+
+// Missing returns the signers that have not signed the bundle yet.
+func (b Bundle) Missing() []string {
+	var missing []string
+
+	for _, signer := range b.Signers {
+		if _, ok := b.Signatures[signer]; !ok {
+			missing = append(missing, signer)
+		}
+	}
+
+	return missing
+}
+
+// SignedTransaction attaches the signatures to the transaction, checks them, and returns it in base64.
+func (b Bundle) SignedTransaction() (string, error) {
+	if missing := b.Missing(); len(missing) > 0 {
+		return "", fmt.Errorf("%w: %v", ErrMissingSignatures, missing)
+	}
+
+	tx, err := b.transaction()
+	if err != nil {
+		return "", err
+	}
+
+	for i, signer := range b.Signers {
+		signature, err := solana.SignatureFromBase58(b.Signatures[signer])
+		if err != nil {
+			return "", fmt.Errorf("invalid signature of %s: %w", signer, err)
+		}
+
+		tx.Signatures[i] = signature
+	}
+
+	if err := tx.VerifySignatures(); err != nil {
+		return "", fmt.Errorf("could not verify signatures: %w", err)
+	}
+
+	signed, err := tx.ToBase64()
+	if err != nil {
+		return "", fmt.Errorf("could not serialize transaction: %w", err)
+	}
+
+	return signed, nil
+}
+
+// Broadcast sends the signed transaction of the bundle.
+func (b Bundle) Broadcast(ctx context.Context, sender Sender) (jupSolana.TxID, error) {
+	signed, err := b.SignedTransaction()
+	if err != nil {
+		return "", err
+	}
+
+	return sender.SendSignedTransaction(ctx, signed)
+}
+
+// transaction deserializes the transaction and checks it matches the signers and blockhash of the bundle.
+func (b Bundle) transaction() (solana.Transaction, error) {
+	tx, err := jupSolana.NewTransactionFromBase64(b.Transaction)
+	if err != nil {
+		return solana.Transaction{}, fmt.Errorf("could not deserialize transaction: %w", err)
+	}
+
+	required := int(tx.Message.Header.NumRequiredSignatures)
+	if required != len(b.Signers) || required > len(tx.Message.AccountKeys) {
+		return solana.Transaction{}, fmt.Errorf("transaction requires %d signatures, bundle lists %d signers",
+			required, len(b.Signers))
+	}
+
+	for i, signer := range b.Signers {
+		if tx.Message.AccountKeys[i].String() != signer {
+			return solana.Transaction{}, fmt.Errorf("signer %s does not match the transaction", signer)
+		}
+	}
+
+	if tx.Message.RecentBlockhash.String() != b.Blockhash {
+		return solana.Transaction{}, fmt.Errorf("blockhash %s does not match the transaction", b.Blockhash)
+	}
+
+	tx.Signatures = make([]solana.Signature, required)
+
+	return tx, nil
+}
+
+// checkQuote checks that the transaction has a single Jupiter route instruction, swapping the amounts of the quote
+// with its slippage.
+func checkQuote(tx solana.Transaction, quote jupiter.QuoteResponse) error {
+	// The accounts loaded from address lookup tables are unknown offline, but the amounts are in the data.
+	keys := append(slices.Clone(tx.Message.AccountKeys), make(solana.PublicKeySlice, tx.Message.NumLookups())...)
+
+	var routes []aggregator.Route
+
+	for i, ix := range tx.Message.Instructions {
+		if int(ix.ProgramIDIndex) >= len(keys) || !keys[ix.ProgramIDIndex].Equals(aggregator.ProgramID) {
+			continue
+		}
+
+		route, err := aggregator.DecodeCompiledInstruction(ix, keys)
+		if err != nil && !errors.Is(err, aggregator.ErrUnknownSwap) {
+			return fmt.Errorf("could not decode instruction %d: %w", i, err)
+		}
+
+		routes = append(routes, route)
+	}
+
+	if len(routes) != 1 {
+		return fmt.Errorf("%w: %d route instructions, expected 1", ErrQuoteMismatch, len(routes))
+	}
+
+	route := routes[0]
+
+	inAmount, err := strconv.ParseUint(quote.InAmount, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid quote in amount %q: %w", quote.InAmount, err)
+	}
+
+	outAmount, err := strconv.ParseUint(quote.OutAmount, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid quote out amount %q: %w", quote.OutAmount, err)
+	}
+
+	routeIn, routeOut := route.InAmount, route.QuotedOutAmount
+	if route.Kind.ExactOut() {
+		routeIn, routeOut = route.QuotedInAmount, route.OutAmount
+	}
+
+	switch {
+	case route.Kind.ExactOut() != (quote.SwapMode == jupiter.SwapModeExactOut):
+		return fmt.Errorf("%w: %s instruction for a %s quote", ErrQuoteMismatch, route.Kind, quote.SwapMode)
+	case route.Kind.TokenLedger():
+		return fmt.Errorf("%w: %s reads its in amount on-chain", ErrQuoteMismatch, route.Kind)
+	case routeIn != inAmount:
+		return fmt.Errorf("%w: in amount %d, quoted %d", ErrQuoteMismatch, routeIn, inAmount)
+	case routeOut != outAmount:
+		return fmt.Errorf("%w: out amount %d, quoted %d", ErrQuoteMismatch, routeOut, outAmount)
+	case uint64(route.SlippageBps) != quote.SlippageBps:
+		return fmt.Errorf("%w: slippage %d bps, quoted %d bps", ErrQuoteMismatch, route.SlippageBps, quote.SlippageBps)
+	}
+
+	return nil
+}
+
+func (b Bundle) isSigner(publicKey string) bool {
+	for _, signer := range b.Signers {
+		if signer == publicKey {
+			return true
+		}
+	}
+
+	return false
+}
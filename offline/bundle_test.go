@@ -0,0 +1,254 @@
+package offline_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilkamo/jupiter-go/jupiter"
+	"github.com/ilkamo/jupiter-go/jupiter/aggregator"
+	"github.com/ilkamo/jupiter-go/jupiter/jupitertest"
+	"github.com/ilkamo/jupiter-go/offline"
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+)
+
+var testBlockhash = solana.MustHashFromBase58("uiYzZ5PCq6C8BRSLSUGBScrXo62bBFbRFP9EkPcaWN9")
+
+type senderMock struct {
+	sent []string
+}
+
+func (s *senderMock) SendSignedTransaction(_ context.Context, txBase64 string) (jupSolana.TxID, error) {
+	s.sent = append(s.sent, txBase64)
+
+	tx, err := jupSolana.NewTransactionFromBase64(txBase64)
+	if err != nil {
+		return "", err
+	}
+
+	return jupSolana.TxID(tx.Signatures[0].String()), nil
+}
+
+// testTransaction builds a transaction paid by the payer, moving lamports from the treasury.
+func testTransaction(t *testing.T, payer, treasury solana.PublicKey) string {
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{
+			system.NewTransferInstruction(1000, treasury, solana.NewWallet().PublicKey()).Build(),
+		},
+		testBlockhash,
+		solana.TransactionPayer(payer),
+	)
+	require.NoError(t, err)
+
+	txBase64, err := tx.ToBase64()
+	require.NoError(t, err)
+
+	return txBase64
+}
+
+// testRouteInstruction copies the route instruction of the fixture swap, with accounts that need no signature.
+func testRouteInstruction(t *testing.T) solana.Instruction {
+	tx, err := jupSolana.NewTransactionFromBase64(jupitertest.DefaultFixtures().Swap.SwapTransaction)
+	require.NoError(t, err)
+
+	for _, ix := range tx.Message.Instructions {
+		if !tx.Message.AccountKeys[ix.ProgramIDIndex].Equals(aggregator.ProgramID) {
+			continue
+		}
+
+		accounts := make(solana.AccountMetaSlice, len(ix.Accounts))
+		for i := range accounts {
+			accounts[i] = solana.Meta(solana.NewWallet().PublicKey())
+		}
+
+		return solana.NewInstruction(aggregator.ProgramID, accounts, ix.Data)
+	}
+
+	require.FailNow(t, "no route instruction in the fixture swap")
+
+	return nil
+}
+
+func TestBundle(t *testing.T) {
+	payer := jupSolana.Wallet{Wallet: solana.NewWallet()}
+	treasury := jupSolana.Wallet{Wallet: solana.NewWallet()}
+
+	b, err := offline.NewBundle(testTransaction(t, payer.PublicKey(), treasury.PublicKey()), 1234)
+	require.NoError(t, err)
+	require.Equal(t, []string{payer.PublicKey().String(), treasury.PublicKey().String()}, b.Signers)
+	require.Equal(t, testBlockhash.String(), b.Blockhash)
+	require.Equal(t, uint64(1234), b.LastValidBlockHeight)
+
+	t.Run("save and load", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "swap.json")
+		require.NoError(t, b.Save(path))
+
+		loaded, err := offline.Load(path)
+		require.NoError(t, err)
+		require.Equal(t, b, loaded)
+	})
+
+	t.Run("sign by every signer", func(t *testing.T) {
+		signed, err := b.Sign(payer)
+		require.NoError(t, err)
+		require.Empty(t, b.Signatures, "signing returns a copy")
+		require.Equal(t, []string{treasury.PublicKey().String()}, signed.Missing())
+
+		_, err = signed.SignedTransaction()
+		require.ErrorIs(t, err, offline.ErrMissingSignatures)
+
+		signed, err = signed.Sign(treasury)
+		require.NoError(t, err)
+		require.Empty(t, signed.Missing())
+
+		sender := &senderMock{}
+
+		txID, err := signed.Broadcast(context.TODO(), sender)
+		require.NoError(t, err)
+		require.Equal(t, jupSolana.TxID(signed.Signatures[payer.PublicKey().String()]), txID)
+		require.Len(t, sender.sent, 1)
+
+		tx, err := jupSolana.NewTransactionFromBase64(sender.sent[0])
+		require.NoError(t, err)
+		require.NoError(t, tx.VerifySignatures())
+	})
+
+	t.Run("not a signer", func(t *testing.T) {
+		_, err := b.Sign(jupSolana.Wallet{Wallet: solana.NewWallet()})
+		require.ErrorContains(t, err, "is not a signer of the transaction")
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		signed, err := b.Sign(payer)
+		require.NoError(t, err)
+
+		signed, err = signed.Sign(treasury)
+		require.NoError(t, err)
+
+		signed.Signatures[payer.PublicKey().String()] = signed.Signatures[treasury.PublicKey().String()]
+
+		_, err = signed.SignedTransaction()
+		require.ErrorContains(t, err, "could not verify signatures")
+	})
+
+	t.Run("tampered signers", func(t *testing.T) {
+		tampered := b
+		tampered.Signers = []string{treasury.PublicKey().String(), payer.PublicKey().String()}
+
+		_, err := tampered.Sign(payer)
+		require.ErrorContains(t, err, "does not match the transaction")
+	})
+}
+
+func TestNewSwapBundle(t *testing.T) {
+	fixtures := jupitertest.DefaultFixtures()
+
+	b, err := offline.NewSwapBundle(fixtures.Quote, fixtures.Swap)
+	require.NoError(t, err)
+	require.Equal(t, "24266", b.Quote.OutAmount)
+	require.Equal(t, fixtures.Swap.LastValidBlockHeight, b.LastValidBlockHeight)
+	require.Equal(t, []string{"BXzmfHxfEMcMj8hDccUNdrwXVNeybyfb2iV2nktE1VnJ"}, b.Signers)
+
+	_, err = offline.NewSwapBundle(fixtures.Quote, jupiter.SwapResponse{SwapTransaction: "invalid"})
+	require.ErrorContains(t, err, "could not deserialize transaction")
+
+	_, err = offline.Load(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorContains(t, err, "could not read bundle")
+}
+
+func TestNewBundle_DurableNonce(t *testing.T) {
+	payer := jupSolana.Wallet{Wallet: solana.NewWallet()}
+	nonce := jupSolana.NonceAccount{
+		Address:   solana.NewWallet().PublicKey(),
+		Authority: payer.PublicKey(),
+		Nonce:     solana.Hash{1},
+	}
+
+	tx, err := jupSolana.NewTransactionFromBase64(testTransaction(t, payer.PublicKey(), payer.PublicKey()))
+	require.NoError(t, err)
+	require.NoError(t, jupSolana.SetDurableNonce(&tx, nonce))
+
+	txBase64, err := tx.ToBase64()
+	require.NoError(t, err)
+
+	b, err := offline.NewBundle(txBase64, 1234)
+	require.NoError(t, err)
+	require.Equal(t, nonce.Address.String(), b.NonceAccount)
+	require.Equal(t, nonce.Nonce.String(), b.Blockhash)
+	require.Zero(t, b.LastValidBlockHeight)
+	require.Equal(t, []string{payer.PublicKey().String()}, b.Signers)
+
+	signed, err := b.Sign(payer)
+	require.NoError(t, err)
+
+	_, err = signed.SignedTransaction()
+	require.NoError(t, err)
+}
+
+func TestBundle_SignChecksQuote(t *testing.T) {
+	payer := jupSolana.Wallet{Wallet: solana.NewWallet()}
+	quote := jupitertest.DefaultFixtures().Quote
+
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{testRouteInstruction(t)},
+		testBlockhash,
+		solana.TransactionPayer(payer.PublicKey()),
+	)
+	require.NoError(t, err)
+
+	txBase64, err := tx.ToBase64()
+	require.NoError(t, err)
+
+	swapResp := jupiter.SwapResponse{SwapTransaction: txBase64, LastValidBlockHeight: 1234}
+
+	t.Run("matching quote", func(t *testing.T) {
+		b, err := offline.NewSwapBundle(quote, swapResp)
+		require.NoError(t, err)
+
+		signed, err := b.Sign(payer)
+		require.NoError(t, err)
+		require.Empty(t, signed.Missing())
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			modify func(*jupiter.QuoteResponse)
+			err    string
+		}{
+			"in amount":  {func(q *jupiter.QuoteResponse) { q.InAmount = "200000" }, "in amount 100000, quoted 200000"},
+			"out amount": {func(q *jupiter.QuoteResponse) { q.OutAmount = "30000" }, "out amount 24266, quoted 30000"},
+			"slippage":   {func(q *jupiter.QuoteResponse) { q.SlippageBps = 50 }, "slippage 250 bps, quoted 50 bps"},
+			"swap mode": {
+				func(q *jupiter.QuoteResponse) { q.SwapMode = jupiter.SwapModeExactOut },
+				"shared_accounts_route instruction for a ExactOut quote",
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				q := quote
+				tc.modify(&q)
+
+				b, err := offline.NewSwapBundle(q, swapResp)
+				require.NoError(t, err)
+
+				_, err = b.Sign(payer)
+				require.ErrorIs(t, err, offline.ErrQuoteMismatch)
+				require.ErrorContains(t, err, tc.err)
+			})
+		}
+	})
+
+	t.Run("no route instruction", func(t *testing.T) {
+		b, err := offline.NewSwapBundle(quote, jupiter.SwapResponse{
+			SwapTransaction: testTransaction(t, payer.PublicKey(), payer.PublicKey()),
+		})
+		require.NoError(t, err)
+
+		_, err = b.Sign(payer)
+		require.ErrorIs(t, err, offline.ErrQuoteMismatch)
+		require.ErrorContains(t, err, "0 route instructions")
+	})
+}
@@ -0,0 +1,12 @@
+package offline
+
+import (
+	"context"
+
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+)
+
+// Sender broadcasts transactions signed elsewhere, e.g. a solana.WatchOnlyClient.
+type Sender interface {
+	SendSignedTransaction(context.Context, string) (jupSolana.TxID, error)
+}
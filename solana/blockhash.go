@@ -0,0 +1,160 @@
+package solana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+const (
+	defaultBlockhashRefreshInterval = 2 * time.Second
+	defaultBlockhashMaxAge          = 30 * time.Second
+)
+
+// ErrBlockhashExpired is returned when a transaction can no longer land because its blockhash expired.
+var ErrBlockhashExpired = errors.New("blockhash expired before the transaction landed")
+
+// BlockhashStrategy chooses the blockhash a client sends transactions with.
+type BlockhashStrategy int
+
+const (
+	// BlockhashRefresh fetches the latest blockhash for every transaction. It is the default.
+	BlockhashRefresh BlockhashStrategy = iota
+	// BlockhashKeep sends transactions with their own blockhash, e.g. the one Jupiter placed in a swap transaction,
+	// saving an RPC round trip.
+	BlockhashKeep
+	// BlockhashCached uses the latest blockhash of a BlockhashCache, set with WithBlockhashCache.
+	BlockhashCached
+)
+
+// Blockhash is a blockhash with the last block height at which transactions using it can land.
+type Blockhash struct {
+	Hash                 solana.Hash
+	LastValidBlockHeight uint64
+	// Slot is the slot the blockhash was fetched at, zero if unknown.
+	Slot uint64
+}
+
+type blockhashService interface {
+	GetLatestBlockhash(
+		ctx context.Context,
+		commitment rpc.CommitmentType,
+	) (out *rpc.GetLatestBlockhashResult, err error)
+}
+
+func latestBlockhash(ctx context.Context, rpcService blockhashService) (Blockhash, error) {
+	resp, err := rpcService.GetLatestBlockhash(ctx, "")
+	if err != nil {
+		return Blockhash{}, fmt.Errorf("could not get latest blockhash: %w", err)
+	}
+
+	if resp == nil || resp.Value == nil {
+		return Blockhash{}, fmt.Errorf("could not get latest blockhash: response value is nil")
+	}
+
+	return Blockhash{
+		Hash:                 resp.Value.Blockhash,
+		LastValidBlockHeight: resp.Value.LastValidBlockHeight,
+		Slot:                 resp.Context.Slot,
+	}, nil
+}
+
+type blockhashCache struct {
+	rpcService      blockhashService
+	refreshInterval time.Duration
+	maxAge          time.Duration
+
+	mu        sync.Mutex
+	latest    Blockhash
+	fetchedAt time.Time
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewBlockhashCache creates a cache of the latest blockhash, refreshed in the background, to be shared by the
+// clients of many goroutines with WithBlockhashCache. It must be closed after use.
+func NewBlockhashCache(rpcEndpoint string, opts ...BlockhashCacheOption) (BlockhashCache, error) {
+	c := &blockhashCache{
+		refreshInterval: defaultBlockhashRefreshInterval,
+		maxAge:          defaultBlockhashMaxAge,
+		stop:            make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, fmt.Errorf("could not apply option: %w", err)
+		}
+	}
+
+	if c.rpcService == nil {
+		if rpcEndpoint == "" {
+			return nil, fmt.Errorf("rpcEndpoint is required when no RPC service is provided")
+		}
+
+		c.rpcService = rpc.New(rpcEndpoint)
+	}
+
+	go c.refresh()
+
+	return c, nil
+}
+
+// Latest returns the cached blockhash. It is fetched right away if the cache is empty or older than its maximum
+// age, e.g. because the background refresh fails.
+func (c *blockhashCache) Latest(ctx context.Context) (Blockhash, error) {
+	c.mu.Lock()
+	latest, fetchedAt := c.latest, c.fetchedAt
+	c.mu.Unlock()
+
+	if !fetchedAt.IsZero() && time.Since(fetchedAt) <= c.maxAge {
+		return latest, nil
+	}
+
+	return c.fetch(ctx)
+}
+
+// Close stops the background refresh.
+func (c *blockhashCache) Close() {
+	c.once.Do(func() { close(c.stop) })
+}
+
+func (c *blockhashCache) refresh() {
+	ticker := time.NewTicker(c.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), c.refreshInterval)
+			// A failed refresh keeps the previous blockhash until it is too old.
+			_, _ = c.fetch(ctx)
+			cancel()
+		}
+	}
+}
+
+func (c *blockhashCache) fetch(ctx context.Context) (Blockhash, error) {
+	latest, err := latestBlockhash(ctx, c.rpcService)
+	if err != nil {
+		return Blockhash{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// A slower concurrent fetch must not replace a newer blockhash.
+	if latest.Slot >= c.latest.Slot {
+		c.latest = latest
+		c.fetchedAt = time.Now()
+	}
+
+	return c.latest, nil
+}
@@ -0,0 +1,219 @@
+package solana_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/require"
+
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+)
+
+// blockhashMock returns a new blockhash, one slot later, on every call.
+type blockhashMock struct {
+	mu         sync.Mutex
+	calls      int
+	shouldFail bool
+}
+
+func (b *blockhashMock) GetLatestBlockhash(
+	_ context.Context,
+	_ rpc.CommitmentType,
+) (*rpc.GetLatestBlockhashResult, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.shouldFail {
+		return nil, errors.New("mocked error")
+	}
+
+	b.calls++
+
+	return &rpc.GetLatestBlockhashResult{
+		RPCContext: rpc.RPCContext{Context: rpc.Context{Slot: uint64(1000 + b.calls)}},
+		Value: &rpc.LatestBlockhashResult{
+			Blockhash:            solana.Hash{byte(b.calls)},
+			LastValidBlockHeight: uint64(2000 + b.calls),
+		},
+	}, nil
+}
+
+func (b *blockhashMock) Calls() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.calls
+}
+
+func TestBlockhashCache(t *testing.T) {
+	t.Run("without rpc endpoint", func(t *testing.T) {
+		_, err := jupSolana.NewBlockhashCache("")
+		require.EqualError(t, err, "rpcEndpoint is required when no RPC service is provided")
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := jupSolana.NewBlockhashCache("", jupSolana.WithBlockhashRefreshInterval(0))
+		require.EqualError(t, err, "could not apply option: blockhash refresh interval must be positive")
+
+		_, err = jupSolana.NewBlockhashCache("", jupSolana.WithBlockhashMaxAge(-time.Second))
+		require.EqualError(t, err, "could not apply option: blockhash max age must be positive")
+	})
+
+	t.Run("cached until refreshed", func(t *testing.T) {
+		mock := &blockhashMock{}
+
+		cache, err := jupSolana.NewBlockhashCache(
+			"",
+			jupSolana.WithBlockhashCacheRPC(mock),
+			jupSolana.WithBlockhashRefreshInterval(time.Hour),
+		)
+		require.NoError(t, err)
+		defer cache.Close()
+
+		first, err := cache.Latest(context.TODO())
+		require.NoError(t, err)
+		require.Equal(t, jupSolana.Blockhash{Hash: solana.Hash{1}, LastValidBlockHeight: 2001, Slot: 1001}, first)
+
+		second, err := cache.Latest(context.TODO())
+		require.NoError(t, err)
+		require.Equal(t, first, second)
+		require.Equal(t, 1, mock.Calls())
+	})
+
+	t.Run("refreshed in the background", func(t *testing.T) {
+		mock := &blockhashMock{}
+
+		cache, err := jupSolana.NewBlockhashCache(
+			"",
+			jupSolana.WithBlockhashCacheRPC(mock),
+			jupSolana.WithBlockhashRefreshInterval(time.Millisecond),
+		)
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool { return mock.Calls() >= 3 }, time.Second, time.Millisecond)
+
+		cache.Close()
+		cache.Close()
+
+		latest, err := cache.Latest(context.TODO())
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, latest.Slot, uint64(1003))
+	})
+
+	t.Run("fetched again when too old", func(t *testing.T) {
+		mock := &blockhashMock{}
+
+		cache, err := jupSolana.NewBlockhashCache(
+			"",
+			jupSolana.WithBlockhashCacheRPC(mock),
+			jupSolana.WithBlockhashRefreshInterval(time.Hour),
+			jupSolana.WithBlockhashMaxAge(time.Nanosecond),
+		)
+		require.NoError(t, err)
+		defer cache.Close()
+
+		_, err = cache.Latest(context.TODO())
+		require.NoError(t, err)
+
+		time.Sleep(time.Millisecond)
+
+		latest, err := cache.Latest(context.TODO())
+		require.NoError(t, err)
+		require.Equal(t, solana.Hash{2}, latest.Hash)
+	})
+
+	t.Run("fetch error", func(t *testing.T) {
+		cache, err := jupSolana.NewBlockhashCache(
+			"",
+			jupSolana.WithBlockhashCacheRPC(&blockhashMock{shouldFail: true}),
+		)
+		require.NoError(t, err)
+		defer cache.Close()
+
+		_, err = cache.Latest(context.TODO())
+		require.EqualError(t, err, "could not get latest blockhash: mocked error")
+	})
+}
+
+func TestClient_BlockhashStrategy(t *testing.T) {
+	wallet := testWallet(t)
+
+	tx, err := jupSolana.NewTransactionFromBase64(testTx)
+	require.NoError(t, err)
+
+	t.Run("refresh", func(t *testing.T) {
+		c, err := jupSolana.NewClient(wallet, "", jupSolana.WithClientRPC(rpcMock{}))
+		require.NoError(t, err)
+
+		res, err := c.SendTransaction(context.TODO(), &tx, jupSolana.SendOptions{})
+		require.NoError(t, err)
+		require.Equal(t, jupSolana.TxID(testSignature), res.TxID)
+		require.Equal(t, "uiYzZ5PCq6C8BRSLSUGBScrXo62bBFbRFP9EkPcaWN9", res.Blockhash.String())
+		require.Equal(t, uint64(123), res.LastValidBlockHeight)
+	})
+
+	t.Run("keep", func(t *testing.T) {
+		c, err := jupSolana.NewClient(
+			wallet,
+			"",
+			jupSolana.WithClientRPC(rpcMock{shouldFailGetLatestBlockhash: true}),
+			jupSolana.WithBlockhashStrategy(jupSolana.BlockhashKeep),
+		)
+		require.NoError(t, err)
+
+		res, err := c.SendTransaction(context.TODO(), &tx, jupSolana.SendOptions{})
+		require.NoError(t, err)
+		require.Equal(t, tx.Message.RecentBlockhash, res.Blockhash)
+		require.Zero(t, res.LastValidBlockHeight)
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		mock := &blockhashMock{}
+
+		cache, err := jupSolana.NewBlockhashCache("", jupSolana.WithBlockhashCacheRPC(mock))
+		require.NoError(t, err)
+		defer cache.Close()
+
+		c, err := jupSolana.NewClient(
+			wallet,
+			"",
+			jupSolana.WithClientRPC(rpcMock{shouldFailGetLatestBlockhash: true}),
+			jupSolana.WithBlockhashCache(cache),
+		)
+		require.NoError(t, err)
+
+		for range 3 {
+			res, err := c.SendTransaction(context.TODO(), &tx, jupSolana.SendOptions{})
+			require.NoError(t, err)
+			require.Equal(t, solana.Hash{1}, res.Blockhash)
+			require.Equal(t, uint64(2001), res.LastValidBlockHeight)
+		}
+
+		require.Equal(t, 1, mock.Calls())
+	})
+
+	t.Run("cached without a cache", func(t *testing.T) {
+		_, err := jupSolana.NewClient(
+			wallet,
+			"",
+			jupSolana.WithClientRPC(rpcMock{}),
+			jupSolana.WithBlockhashStrategy(jupSolana.BlockhashCached),
+		)
+		require.EqualError(t, err, "blockhash cache is required by BlockhashCached: use WithBlockhashCache")
+	})
+
+	t.Run("unknown strategy", func(t *testing.T) {
+		_, err := jupSolana.NewClient(
+			wallet,
+			"",
+			jupSolana.WithClientRPC(rpcMock{}),
+			jupSolana.WithBlockhashStrategy(jupSolana.BlockhashStrategy(7)),
+		)
+		require.EqualError(t, err, "could not apply option: unknown blockhash strategy 7")
+	})
+}
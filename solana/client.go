@@ -3,11 +3,13 @@ package solana
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/shopspring/decimal"
 
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
 )
 
 const defaultMaxRetries = uint(20)
@@ -19,10 +21,31 @@ type TokenAccount struct {
 	Decimals uint8
 }
 
+// SendResult is the outcome of a sent transaction.
+type SendResult struct {
+	TxID TxID
+	// Blockhash is the blockhash the transaction was sent with, or its nonce if it uses a durable nonce.
+	Blockhash solana.Hash
+	// LastValidBlockHeight is the last block height at which the transaction can land. It is zero when unknown, as
+	// for a kept blockhash which only the builder of the transaction knows, and for a durable nonce transaction.
+	LastValidBlockHeight uint64
+}
+
 type client struct {
-	maxRetries uint
-	clientRPC  rpcService
-	wallet     Wallet
+	maxRetries        uint
+	clientRPC         rpcService
+	wallet            Wallet
+	publicKey         solana.PublicKey
+	verifier          TransactionVerifier
+	httpClient        *http.Client
+	blockhashStrategy BlockhashStrategy
+	blockhashCache    BlockhashCache
+	// commitment is the default commitment of reads, each read's own if zero.
+	commitment CommitmentStatus
+}
+
+type readOptions struct {
+	commitment CommitmentStatus
 }
 
 func newClient(
@@ -35,6 +58,10 @@ func newClient(
 		wallet:     wallet,
 	}
 
+	if wallet.Wallet != nil {
+		c.publicKey = wallet.PublicKey()
+	}
+
 	for _, opt := range opts {
 		if err := opt(c); err != nil {
 			return nil, fmt.Errorf("could not apply option: %w", err)
@@ -47,9 +74,19 @@ func newClient(
 		}
 
 		rpcClient := rpc.New(rpcEndpoint)
+		if c.httpClient != nil {
+			rpcClient = rpc.NewWithCustomRPCClient(jsonrpc.NewClientWithOpts(rpcEndpoint, &jsonrpc.RPCClientOpts{
+				HTTPClient: c.httpClient,
+			}))
+		}
+
 		c.clientRPC = rpcClient
 	}
 
+	if c.blockhashStrategy == BlockhashCached && c.blockhashCache == nil {
+		return nil, fmt.Errorf("blockhash cache is required by BlockhashCached: use WithBlockhashCache")
+	}
+
 	return c, nil
 }
 
@@ -63,28 +100,146 @@ func NewClient(
 	return newClient(wallet, rpcEndpoint, opts...)
 }
 
-// SendTransactionOnChain sends a transaction on-chain.
+// SendOptions tunes how a transaction is sent. The zero value sends it like SendTransactionOnChain.
+type SendOptions struct {
+	// SkipPreflight skips the simulation of the transaction by the RPC node.
+	SkipPreflight bool
+	// PreflightCommitment is the commitment the preflight simulation runs at, processed if zero.
+	PreflightCommitment CommitmentStatus
+	// MaxRetries is how many times the RPC node retries sending the transaction, the client's if nil.
+	MaxRetries *uint
+	// MinContextSlot is the minimum slot the RPC node must have reached, the slot of the fetched blockhash if nil.
+	MinContextSlot *uint64
+	// Encoding is the encoding the transaction is sent in, base64 if empty.
+	Encoding solana.EncodingType
+}
+
+// SendTransactionOnChain sends a transaction on-chain and returns its ID, see SendTransaction.
 func (e client) SendTransactionOnChain(ctx context.Context, txBase64 string) (TxID, error) {
-	latestBlockhash, err := e.clientRPC.GetLatestBlockhash(ctx, "")
+	tx, err := NewTransactionFromBase64(txBase64)
 	if err != nil {
-		return "", fmt.Errorf("could not get latest blockhash: %w", err)
+		return "", fmt.Errorf("could not deserialize swap transaction: %w", err)
 	}
 
-	tx, err := NewTransactionFromBase64(txBase64)
+	res, err := e.SendTransaction(ctx, &tx, SendOptions{})
 	if err != nil {
-		return "", fmt.Errorf("could not deserialize swap transaction: %w", err)
+		return "", err
+	}
+
+	return res.TxID, nil
+}
+
+// SendTransaction signs and sends a transaction on-chain with the blockhash of the client's strategy, unless it
+// uses a durable nonce. If a TransactionVerifier is configured, the transaction is verified before being signed.
+// The given transaction is not modified.
+func (e client) SendTransaction(ctx context.Context, tx *solana.Transaction, sendOpts SendOptions) (SendResult, error) {
+	if e.wallet.Wallet == nil {
+		return SendResult{}, ErrWatchOnly
+	}
+
+	if tx == nil {
+		return SendResult{}, fmt.Errorf("transaction is required")
+	}
+
+	if e.verifier != nil {
+		if err := e.verifier.VerifyTransaction(ctx, *tx); err != nil {
+			return SendResult{}, fmt.Errorf("could not verify transaction: %w", err)
+		}
+	}
+
+	preflight := sendOpts.PreflightCommitment
+	if preflight == (CommitmentStatus{}) {
+		preflight = CommitmentProcessed
+	}
+
+	preflightCommitment, err := mapToCommitmentType(preflight)
+	if err != nil {
+		return SendResult{}, fmt.Errorf("invalid preflight commitment: %w", err)
+	}
+
+	opts := rpc.TransactionOpts{
+		Encoding:            sendOpts.Encoding,
+		SkipPreflight:       sendOpts.SkipPreflight,
+		PreflightCommitment: preflightCommitment,
+		MaxRetries:          sendOpts.MaxRetries,
+		MinContextSlot:      sendOpts.MinContextSlot,
+	}
+
+	if opts.MaxRetries == nil {
+		opts.MaxRetries = &e.maxRetries
+	}
+
+	toSend := *tx
+	res := SendResult{Blockhash: toSend.Message.RecentBlockhash}
+
+	// A durable nonce transaction keeps its nonce as blockhash, and does not expire with block height.
+	if _, ok := DurableNonce(toSend); !ok {
+		blockhash, err := e.blockhash(ctx, toSend)
+		if err != nil {
+			return SendResult{}, err
+		}
+
+		toSend.Message.RecentBlockhash = blockhash.Hash
+		res.Blockhash = blockhash.Hash
+		res.LastValidBlockHeight = blockhash.LastValidBlockHeight
+
+		if opts.MinContextSlot == nil && blockhash.Slot > 0 {
+			opts.MinContextSlot = &blockhash.Slot
+		}
+	}
+
+	toSend, err = e.wallet.SignTransaction(toSend)
+	if err != nil {
+		return SendResult{}, fmt.Errorf("could not sign swap transaction: %w", err)
 	}
 
-	tx.Message.RecentBlockhash = latestBlockhash.Value.Blockhash
+	sig, err := e.clientRPC.SendTransactionWithOpts(ctx, &toSend, opts)
+	if err != nil {
+		return SendResult{}, fmt.Errorf("could not send transaction: %w", err)
+	}
+
+	res.TxID = TxID(sig.String())
+
+	return res, nil
+}
+
+// blockhash returns the blockhash to send the transaction with, according to the strategy of the client.
+func (e client) blockhash(ctx context.Context, tx solana.Transaction) (Blockhash, error) {
+	if e.blockhashStrategy == BlockhashKeep {
+		return Blockhash{Hash: tx.Message.RecentBlockhash}, nil
+	}
+
+	return e.latestBlockhash(ctx)
+}
+
+// latestBlockhash returns the latest blockhash, from the cache of the client if it has one.
+func (e client) latestBlockhash(ctx context.Context) (Blockhash, error) {
+	if e.blockhashStrategy == BlockhashCached {
+		return e.blockhashCache.Latest(ctx)
+	}
 
-	tx, err = e.wallet.SignTransaction(tx)
+	return latestBlockhash(ctx, e.clientRPC)
+}
+
+// SendSignedTransaction sends a transaction that is already signed, e.g. offline, without changing it.
+// It does not need a private key, so watch-only clients can broadcast transactions signed elsewhere.
+func (e client) SendSignedTransaction(ctx context.Context, txBase64 string) (TxID, error) {
+	tx, err := NewTransactionFromBase64(txBase64)
 	if err != nil {
-		return "", fmt.Errorf("could not sign swap transaction: %w", err)
+		return "", fmt.Errorf("could not deserialize transaction: %w", err)
+	}
+
+	if len(tx.Signatures) == 0 || len(tx.Signatures) != int(tx.Message.Header.NumRequiredSignatures) {
+		return "", fmt.Errorf("transaction has %d signatures, expected %d",
+			len(tx.Signatures), tx.Message.Header.NumRequiredSignatures)
+	}
+
+	if err := tx.VerifySignatures(); err != nil {
+		return "", fmt.Errorf("could not verify signatures: %w", err)
 	}
 
 	sig, err := e.clientRPC.SendTransactionWithOpts(ctx, &tx, rpc.TransactionOpts{
 		MaxRetries:          &e.maxRetries,
-		MinContextSlot:      &latestBlockhash.Context.Slot,
 		PreflightCommitment: rpc.CommitmentProcessed,
 	})
 	if err != nil {
@@ -94,8 +249,31 @@ func (e client) SendTransactionOnChain(ctx context.Context, txBase64 string) (Tx
 	return TxID(sig.String()), nil
 }
 
-// CheckSignature checks if a transaction with the given signature has been confirmed on-chain.
-func (e client) CheckSignature(ctx context.Context, tx TxID) (bool, error) {
+// readCommitment returns the commitment of a read: the one of the call, else the default of the client, else the
+// fallback of the read.
+func (e client) readCommitment(fallback CommitmentStatus, opts []ReadOption) (rpc.CommitmentType, error) {
+	r := readOptions{commitment: e.commitment}
+	if r.commitment == (CommitmentStatus{}) {
+		r.commitment = fallback
+	}
+
+	for _, opt := range opts {
+		if err := opt(&r); err != nil {
+			return "", fmt.Errorf("could not apply option: %w", err)
+		}
+	}
+
+	return mapToCommitmentType(r.commitment)
+}
+
+// CheckSignature checks if a transaction with the given signature has reached the commitment of the client on-chain,
+// finalized by default.
+func (e client) CheckSignature(ctx context.Context, tx TxID, opts ...ReadOption) (bool, error) {
+	commitment, err := e.readCommitment(CommitmentFinalized, opts)
+	if err != nil {
+		return false, err
+	}
+
 	sig, err := solana.SignatureFromBase58(string(tx))
 	if err != nil {
 		return false, fmt.Errorf("could not convert signature from base58: %w", err)
@@ -110,25 +288,46 @@ func (e client) CheckSignature(ctx context.Context, tx TxID) (bool, error) {
 		return false, fmt.Errorf("could not confirm transaction: no valid status")
 	}
 
-	if status.Value[0] == nil || status.Value[0].ConfirmationStatus != rpc.ConfirmationStatusFinalized {
-		return false, fmt.Errorf("transaction not finalized yet")
+	if status.Value[0] == nil || !reachedCommitment(status.Value[0].ConfirmationStatus, commitment) {
+		return false, fmt.Errorf("transaction not %s yet", commitment)
 	}
 
 	if status.Value[0].Err != nil {
-		return true, fmt.Errorf("transaction confirmed with error: %s", status.Value[0].Err)
+		return true, fmt.Errorf("transaction confirmed with error: %w", &InstructionError{Err: status.Value[0].Err})
 	}
 
 	return true, nil
 }
 
-// GetTokenAccountBalance returns the balance of an SPL token account.
-func (e client) GetTokenAccountBalance(ctx context.Context, tokenAccount string) (TokenAccount, error) {
+// reachedCommitment reports whether a confirmation status is at least the commitment.
+func reachedCommitment(status rpc.ConfirmationStatusType, commitment rpc.CommitmentType) bool {
+	levels := map[string]int{
+		string(rpc.CommitmentProcessed): 1,
+		string(rpc.CommitmentConfirmed): 2,
+		string(rpc.CommitmentFinalized): 3,
+	}
+
+	return levels[string(status)] > 0 && levels[string(status)] >= levels[string(commitment)]
+}
+
+// GetTokenAccountBalance returns the balance of an SPL token account at the commitment of the client, finalized by
+// default.
+func (e client) GetTokenAccountBalance(
+	ctx context.Context,
+	tokenAccount string,
+	opts ...ReadOption,
+) (TokenAccount, error) {
+	commitment, err := e.readCommitment(CommitmentFinalized, opts)
+	if err != nil {
+		return TokenAccount{}, err
+	}
+
 	tokenAccountPk, err := solana.PublicKeyFromBase58(tokenAccount)
 	if err != nil {
 		return TokenAccount{}, fmt.Errorf("could not parse token account public key: %w", err)
 	}
 
-	resp, err := e.clientRPC.GetTokenAccountBalance(ctx, tokenAccountPk, rpc.CommitmentFinalized)
+	resp, err := e.clientRPC.GetTokenAccountBalance(ctx, tokenAccountPk, commitment)
 	if err != nil {
 		return TokenAccount{}, fmt.Errorf("could not get token account balance: %w", err)
 	}
@@ -148,6 +347,21 @@ func (e client) GetTokenAccountBalance(ctx context.Context, tokenAccount string)
 	}, nil
 }
 
+// GetBlockHeight returns the current block height at the commitment of the client, finalized by default.
+func (e client) GetBlockHeight(ctx context.Context, opts ...ReadOption) (uint64, error) {
+	commitment, err := e.readCommitment(CommitmentFinalized, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	height, err := e.clientRPC.GetBlockHeight(ctx, commitment)
+	if err != nil {
+		return 0, fmt.Errorf("could not get block height: %w", err)
+	}
+
+	return height, nil
+}
+
 // Close closes the client.
 func (e client) Close() error {
 	if e.clientRPC != nil {
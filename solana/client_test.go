@@ -1,11 +1,14 @@
 package solana_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"testing"
 
+	bin "github.com/gagliardetto/binary"
 	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
 	"github.com/gagliardetto/solana-go/rpc"
 	"github.com/stretchr/testify/require"
 
@@ -17,6 +20,13 @@ type rpcMock struct {
 	shouldFailSendTransaction    bool
 	shouldFailGetSignatureStatus bool
 	shoultFailGetTokenBalance    bool
+	shouldFailSimulation         bool
+	// nonceAuthority is the authority of the nonce account returned by GetAccountInfoWithOpts, if set.
+	nonceAuthority solana.PublicKey
+	// sentOpts records the options of the last sent transaction, if set.
+	sentOpts *rpc.TransactionOpts
+	// readCommitment records the commitment of the last balance read, if set.
+	readCommitment *rpc.CommitmentType
 }
 
 var (
@@ -27,12 +37,16 @@ var (
 func (r rpcMock) SendTransactionWithOpts(
 	_ context.Context,
 	_ *solana.Transaction,
-	_ rpc.TransactionOpts,
+	opts rpc.TransactionOpts,
 ) (signature solana.Signature, err error) {
 	if r.shouldFailSendTransaction {
 		return solana.Signature{}, errors.New("mocked error")
 	}
 
+	if r.sentOpts != nil {
+		*r.sentOpts = opts
+	}
+
 	return solana.MustSignatureFromBase58(testSignature), nil
 }
 
@@ -45,6 +59,7 @@ func (r rpcMock) GetLatestBlockhash(
 	}
 
 	return &rpc.GetLatestBlockhashResult{
+		RPCContext: rpc.RPCContext{Context: rpc.Context{Slot: 100}},
 		Value: &rpc.LatestBlockhashResult{
 			LastValidBlockHeight: 123,
 			Blockhash:            solana.MustHashFromBase58("uiYzZ5PCq6C8BRSLSUGBScrXo62bBFbRFP9EkPcaWN9"),
@@ -83,12 +98,16 @@ func (r rpcMock) GetSignatureStatuses(
 func (r rpcMock) GetTokenAccountBalance(
 	_ context.Context,
 	_ solana.PublicKey,
-	_ rpc.CommitmentType,
+	commitment rpc.CommitmentType,
 ) (out *rpc.GetTokenAccountBalanceResult, err error) {
 	if r.shoultFailGetTokenBalance {
 		return nil, errors.New("mocked error")
 	}
 
+	if r.readCommitment != nil {
+		*r.readCommitment = commitment
+	}
+
 	return &rpc.GetTokenAccountBalanceResult{
 		Value: &rpc.UiTokenAmount{
 			Amount:   "1000000000",
@@ -97,10 +116,103 @@ func (r rpcMock) GetTokenAccountBalance(
 	}, nil
 }
 
+func (r rpcMock) GetBalance(
+	_ context.Context,
+	_ solana.PublicKey,
+	commitment rpc.CommitmentType,
+) (out *rpc.GetBalanceResult, err error) {
+	if r.readCommitment != nil {
+		*r.readCommitment = commitment
+	}
+
+	return &rpc.GetBalanceResult{Value: 1_500_000_000}, nil
+}
+
+func (r rpcMock) GetBlockHeight(_ context.Context, commitment rpc.CommitmentType) (uint64, error) {
+	if r.readCommitment != nil {
+		*r.readCommitment = commitment
+	}
+
+	return 150, nil
+}
+
+func (r rpcMock) SimulateTransactionWithOpts(
+	_ context.Context,
+	tx *solana.Transaction,
+	_ *rpc.SimulateTransactionOpts,
+) (out *rpc.SimulateTransactionResponse, err error) {
+	if len(tx.Signatures) != int(tx.Message.Header.NumRequiredSignatures) {
+		return nil, errors.New("not enough signers")
+	}
+
+	unitsConsumed := uint64(2_000)
+
+	res := &rpc.SimulateTransactionResponse{
+		Value: &rpc.SimulateTransactionResult{
+			Logs:          []string{"Program 11111111111111111111111111111111 success"},
+			UnitsConsumed: &unitsConsumed,
+		},
+	}
+
+	if r.shouldFailSimulation {
+		res.Value.Err = map[string]any{"InstructionError": []any{0, map[string]any{"Custom": 1}}}
+	}
+
+	return res, nil
+}
+
+func (r rpcMock) GetAccountInfoWithOpts(
+	_ context.Context,
+	_ solana.PublicKey,
+	_ *rpc.GetAccountInfoOpts,
+) (*rpc.GetAccountInfoResult, error) {
+	if r.nonceAuthority.IsZero() {
+		return nil, rpc.ErrNotFound
+	}
+
+	buf := new(bytes.Buffer)
+	if err := bin.NewBinEncoder(buf).Encode(system.NonceAccount{
+		State:            1,
+		AuthorizedPubkey: r.nonceAuthority,
+		Nonce:            solana.PublicKey(testNonce),
+		FeeCalculator:    system.FeeCalculator{LamportsPerSignature: 5000},
+	}); err != nil {
+		return nil, err
+	}
+
+	return &rpc.GetAccountInfoResult{
+		Value: &rpc.Account{
+			Lamports: 1_447_680,
+			Owner:    solana.SystemProgramID,
+			Data:     rpc.DataBytesOrJSONFromBytes(buf.Bytes()),
+		},
+	}, nil
+}
+
+func (r rpcMock) GetMinimumBalanceForRentExemption(
+	_ context.Context,
+	_ uint64,
+	_ rpc.CommitmentType,
+) (uint64, error) {
+	return 1_447_680, nil
+}
+
 func (r rpcMock) Close() error {
 	return nil
 }
 
+type verifierMock struct {
+	shouldFail bool
+}
+
+func (v verifierMock) VerifyTransaction(_ context.Context, _ solana.Transaction) error {
+	if v.shouldFail {
+		return errors.New("mocked error")
+	}
+
+	return nil
+}
+
 func TestNewClient(t *testing.T) {
 	testPk := "5473ZnvEhn35BdcCcPLKnzsyP6TsgqQrNFpn4i2gFegFiiJLyWginpa9GoFn2cy6Aq2EAuxLt2u2bjFDBPvNY6nw"
 
@@ -164,6 +276,33 @@ func TestNewClient(t *testing.T) {
 		require.Equal(t, expectedTxID, txID)
 	})
 
+	t.Run("execute valid swap with transaction verifier", func(t *testing.T) {
+		c, err := jupSolana.NewClient(
+			wallet,
+			"",
+			jupSolana.WithClientRPC(rpcMock{}),
+			jupSolana.WithTransactionVerifier(verifierMock{}),
+		)
+		require.NoError(t, err)
+
+		txID, err := c.SendTransactionOnChain(context.TODO(), testTx)
+		require.NoError(t, err)
+		require.Equal(t, jupSolana.TxID(testSignature), txID)
+	})
+
+	t.Run("error when verifying the transaction", func(t *testing.T) {
+		c, err := jupSolana.NewClient(
+			wallet,
+			"",
+			jupSolana.WithClientRPC(rpcMock{}),
+			jupSolana.WithTransactionVerifier(verifierMock{shouldFail: true}),
+		)
+		require.NoError(t, err)
+
+		_, err = c.SendTransactionOnChain(context.TODO(), testTx)
+		require.EqualError(t, err, "could not verify transaction: mocked error")
+	})
+
 	t.Run("error when getting the blockhash", func(t *testing.T) {
 		c, err := jupSolana.NewClient(
 			wallet,
@@ -274,3 +413,173 @@ func TestNewClient(t *testing.T) {
 		require.Equal(t, uint8(9), balance.Decimals)
 	})
 }
+
+func TestClient_SendTransaction(t *testing.T) {
+	wallet := testWallet(t)
+
+	tx, err := jupSolana.NewTransactionFromBase64(testTx)
+	require.NoError(t, err)
+
+	t.Run("default options", func(t *testing.T) {
+		var sentOpts rpc.TransactionOpts
+
+		c, err := jupSolana.NewClient(
+			wallet,
+			"",
+			jupSolana.WithClientRPC(rpcMock{sentOpts: &sentOpts}),
+			jupSolana.WithMaxRetries(10),
+		)
+		require.NoError(t, err)
+
+		res, err := c.SendTransaction(context.TODO(), &tx, jupSolana.SendOptions{})
+		require.NoError(t, err)
+		require.Equal(t, jupSolana.TxID(testSignature), res.TxID)
+
+		require.False(t, sentOpts.SkipPreflight)
+		require.Equal(t, rpc.CommitmentProcessed, sentOpts.PreflightCommitment)
+		require.Equal(t, uint(10), *sentOpts.MaxRetries)
+		require.Equal(t, uint64(100), *sentOpts.MinContextSlot)
+		require.Empty(t, sentOpts.Encoding)
+	})
+
+	t.Run("per-call options", func(t *testing.T) {
+		var sentOpts rpc.TransactionOpts
+
+		c, err := jupSolana.NewClient(wallet, "", jupSolana.WithClientRPC(rpcMock{sentOpts: &sentOpts}))
+		require.NoError(t, err)
+
+		maxRetries := uint(0)
+		minContextSlot := uint64(42)
+
+		_, err = c.SendTransaction(context.TODO(), &tx, jupSolana.SendOptions{
+			SkipPreflight:       true,
+			PreflightCommitment: jupSolana.CommitmentConfirmed,
+			MaxRetries:          &maxRetries,
+			MinContextSlot:      &minContextSlot,
+			Encoding:            solana.EncodingBase58,
+		})
+		require.NoError(t, err)
+
+		require.True(t, sentOpts.SkipPreflight)
+		require.Equal(t, rpc.CommitmentConfirmed, sentOpts.PreflightCommitment)
+		require.Equal(t, uint(0), *sentOpts.MaxRetries)
+		require.Equal(t, uint64(42), *sentOpts.MinContextSlot)
+		require.Equal(t, solana.EncodingBase58, sentOpts.Encoding)
+	})
+
+	t.Run("the given transaction is not modified", func(t *testing.T) {
+		stale := tx
+		stale.Message.RecentBlockhash = solana.Hash{9}
+
+		c, err := jupSolana.NewClient(wallet, "", jupSolana.WithClientRPC(rpcMock{}))
+		require.NoError(t, err)
+
+		res, err := c.SendTransaction(context.TODO(), &stale, jupSolana.SendOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "uiYzZ5PCq6C8BRSLSUGBScrXo62bBFbRFP9EkPcaWN9", res.Blockhash.String())
+		require.Equal(t, solana.Hash{9}, stale.Message.RecentBlockhash)
+		require.Empty(t, stale.Signatures)
+	})
+
+	t.Run("without transaction", func(t *testing.T) {
+		c, err := jupSolana.NewClient(wallet, "", jupSolana.WithClientRPC(rpcMock{}))
+		require.NoError(t, err)
+
+		_, err = c.SendTransaction(context.TODO(), nil, jupSolana.SendOptions{})
+		require.EqualError(t, err, "transaction is required")
+	})
+}
+
+func TestClient_Commitment(t *testing.T) {
+	wallet := testWallet(t)
+
+	t.Run("invalid default commitment", func(t *testing.T) {
+		_, err := jupSolana.NewClient(
+			wallet,
+			"",
+			jupSolana.WithClientRPC(rpcMock{}),
+			jupSolana.WithCommitment(jupSolana.CommitmentStatus{}),
+		)
+		require.EqualError(t, err, "could not apply option: invalid CommitmentStatus")
+	})
+
+	t.Run("reads at finalized by default", func(t *testing.T) {
+		var commitment rpc.CommitmentType
+
+		c, err := jupSolana.NewClient(wallet, "", jupSolana.WithClientRPC(rpcMock{readCommitment: &commitment}))
+		require.NoError(t, err)
+
+		_, err = c.GetTokenAccountBalance(context.TODO(), testWatchedPublicKey)
+		require.NoError(t, err)
+		require.Equal(t, rpc.CommitmentFinalized, commitment)
+	})
+
+	t.Run("reads at the default commitment of the client", func(t *testing.T) {
+		var commitment rpc.CommitmentType
+
+		c, err := jupSolana.NewWatchOnlyClient(
+			testWatchedPublicKey,
+			"",
+			jupSolana.WithClientRPC(rpcMock{readCommitment: &commitment}),
+			jupSolana.WithCommitment(jupSolana.CommitmentConfirmed),
+		)
+		require.NoError(t, err)
+
+		_, err = c.GetTokenAccountBalance(context.TODO(), testWatchedPublicKey)
+		require.NoError(t, err)
+		require.Equal(t, rpc.CommitmentConfirmed, commitment)
+
+		_, err = c.GetBalance(context.TODO())
+		require.NoError(t, err)
+		require.Equal(t, rpc.CommitmentConfirmed, commitment)
+
+		height, err := c.GetBlockHeight(context.TODO())
+		require.NoError(t, err)
+		require.Equal(t, uint64(150), height)
+		require.Equal(t, rpc.CommitmentConfirmed, commitment)
+	})
+
+	t.Run("per-call override", func(t *testing.T) {
+		var commitment rpc.CommitmentType
+
+		c, err := jupSolana.NewWatchOnlyClient(
+			testWatchedPublicKey,
+			"",
+			jupSolana.WithClientRPC(rpcMock{readCommitment: &commitment}),
+			jupSolana.WithCommitment(jupSolana.CommitmentConfirmed),
+		)
+		require.NoError(t, err)
+
+		_, err = c.GetBalance(context.TODO(), jupSolana.WithReadCommitment(jupSolana.CommitmentProcessed))
+		require.NoError(t, err)
+		require.Equal(t, rpc.CommitmentProcessed, commitment)
+
+		_, err = c.GetBalance(context.TODO(), jupSolana.WithReadCommitment(jupSolana.CommitmentStatus{}))
+		require.EqualError(t, err, "could not apply option: invalid CommitmentStatus")
+	})
+
+	t.Run("signature checked at the commitment", func(t *testing.T) {
+		c, err := jupSolana.NewClient(
+			wallet,
+			"",
+			jupSolana.WithClientRPC(rpcMock{}),
+			jupSolana.WithCommitment(jupSolana.CommitmentConfirmed),
+		)
+		require.NoError(t, err)
+
+		_, err = c.CheckSignature(context.TODO(), jupSolana.TxID(processingSignature))
+		require.EqualError(t, err, "transaction not confirmed yet")
+
+		ok, err := c.CheckSignature(context.TODO(), jupSolana.TxID(testSignature))
+		require.NoError(t, err)
+		require.True(t, ok, "a finalized transaction is also confirmed")
+
+		ok, err = c.CheckSignature(
+			context.TODO(),
+			jupSolana.TxID(processingSignature),
+			jupSolana.WithReadCommitment(jupSolana.CommitmentProcessed),
+		)
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+}
@@ -0,0 +1,197 @@
+package solana
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+)
+
+// ComputeBudget is the compute budget to set on a transaction. A nil field keeps the current value.
+type ComputeBudget struct {
+	// UnitLimit is the maximum number of compute units the transaction can consume.
+	UnitLimit *uint32
+	// UnitPrice is the priority fee in micro-lamports per compute unit.
+	UnitPrice *uint64
+}
+
+// ComputeBudgetReport holds the compute budget of a transaction before and after it was rewritten. A value is zero
+// when the transaction has no instruction setting it.
+type ComputeBudgetReport struct {
+	OldUnitLimit uint32
+	NewUnitLimit uint32
+	OldUnitPrice uint64
+	NewUnitPrice uint64
+}
+
+// RewriteComputeBudget decodes a transaction, sets its compute budget with SetComputeBudget and returns it in base64
+// with empty signatures, ready to be signed again.
+func RewriteComputeBudget(txBase64 string, budget ComputeBudget) (string, ComputeBudgetReport, error) {
+	tx, err := NewTransactionFromBase64(txBase64)
+	if err != nil {
+		return "", ComputeBudgetReport{}, fmt.Errorf("could not deserialize transaction: %w", err)
+	}
+
+	report, err := SetComputeBudget(&tx, budget)
+	if err != nil {
+		return "", ComputeBudgetReport{}, err
+	}
+
+	txBase64, err = tx.ToBase64()
+	if err != nil {
+		return "", ComputeBudgetReport{}, fmt.Errorf("could not serialize transaction: %w", err)
+	}
+
+	return txBase64, report, nil
+}
+
+// SetComputeBudget rewrites the SetComputeUnitLimit and SetComputeUnitPrice instructions of the transaction, and
+// inserts the missing ones first, after AdvanceNonceAccount in a durable nonce transaction. The compute budget
+// program is added to the static keys if needed, keeping the address lookup tables of the message, which must not be
+// resolved. The signatures are cleared, as the message changes.
+func SetComputeBudget(tx *solana.Transaction, budget ComputeBudget) (ComputeBudgetReport, error) {
+	if tx.Message.IsResolved() {
+		return ComputeBudgetReport{}, fmt.Errorf("transaction address lookups must not be resolved")
+	}
+
+	msg := &tx.Message
+	report := ComputeBudgetReport{}
+
+	programIndex, found := -1, false
+
+	for i, key := range msg.AccountKeys {
+		if key.Equals(solana.ComputeBudget) {
+			programIndex, found = i, true
+			break
+		}
+	}
+
+	limitIx, priceIx := -1, -1
+
+	for i, ix := range msg.Instructions {
+		if !found || int(ix.ProgramIDIndex) != programIndex || len(ix.Data) == 0 {
+			continue
+		}
+
+		switch {
+		case ix.Data[0] == computebudget.Instruction_SetComputeUnitLimit && len(ix.Data) >= 5:
+			limitIx = i
+			report.OldUnitLimit = binary.LittleEndian.Uint32(ix.Data[1:])
+		case ix.Data[0] == computebudget.Instruction_SetComputeUnitPrice && len(ix.Data) >= 9:
+			priceIx = i
+			report.OldUnitPrice = binary.LittleEndian.Uint64(ix.Data[1:])
+		}
+	}
+
+	report.NewUnitLimit, report.NewUnitPrice = report.OldUnitLimit, report.OldUnitPrice
+
+	if budget.UnitLimit != nil {
+		report.NewUnitLimit = *budget.UnitLimit
+	}
+
+	if budget.UnitPrice != nil {
+		report.NewUnitPrice = *budget.UnitPrice
+	}
+
+	insertLimit := budget.UnitLimit != nil && limitIx < 0
+	insertPrice := budget.UnitPrice != nil && priceIx < 0
+
+	if !found && (insertLimit || insertPrice) {
+		if len(msg.AccountKeys)+1+msg.NumLookups() > 256 {
+			return ComputeBudgetReport{}, fmt.Errorf("transaction has too many accounts to set a compute budget")
+		}
+
+		addReadonlyProgram(msg, solana.ComputeBudget)
+		programIndex = len(msg.AccountKeys) - 1
+	}
+
+	if budget.UnitLimit != nil && limitIx >= 0 {
+		msg.Instructions[limitIx].Data = unitLimitData(*budget.UnitLimit)
+	}
+
+	if budget.UnitPrice != nil && priceIx >= 0 {
+		msg.Instructions[priceIx].Data = unitPriceData(*budget.UnitPrice)
+	}
+
+	var inserted []solana.CompiledInstruction
+
+	if insertLimit {
+		inserted = append(inserted, solana.CompiledInstruction{
+			ProgramIDIndex: uint16(programIndex),
+			Accounts:       []uint16{},
+			Data:           unitLimitData(*budget.UnitLimit),
+		})
+	}
+
+	if insertPrice {
+		inserted = append(inserted, solana.CompiledInstruction{
+			ProgramIDIndex: uint16(programIndex),
+			Accounts:       []uint16{},
+			Data:           unitPriceData(*budget.UnitPrice),
+		})
+	}
+
+	if len(inserted) > 0 {
+		// AdvanceNonceAccount must stay the first instruction of a durable nonce transaction.
+		at := 0
+		if _, ok := DurableNonce(*tx); ok {
+			at = 1
+		}
+
+		instructions := make([]solana.CompiledInstruction, 0, len(msg.Instructions)+len(inserted))
+		instructions = append(instructions, msg.Instructions[:at]...)
+		instructions = append(instructions, inserted...)
+		instructions = append(instructions, msg.Instructions[at:]...)
+		msg.Instructions = instructions
+	}
+
+	tx.Signatures = make([]solana.Signature, msg.Header.NumRequiredSignatures)
+
+	return report, nil
+}
+
+// addReadonlyProgram appends a program to the static keys of the message, which end with the readonly non-signers.
+// The accounts loaded from lookup tables follow the static keys, so their indices shift by one.
+func addReadonlyProgram(msg *solana.Message, program solana.PublicKey) {
+	numStatic := uint16(len(msg.AccountKeys))
+
+	shift := func(index uint16) uint16 {
+		if index >= numStatic {
+			return index + 1
+		}
+
+		return index
+	}
+
+	for i := range msg.Instructions {
+		ix := &msg.Instructions[i]
+		ix.ProgramIDIndex = shift(ix.ProgramIDIndex)
+
+		accounts := make([]uint16, len(ix.Accounts))
+		for j, index := range ix.Accounts {
+			accounts[j] = shift(index)
+		}
+
+		ix.Accounts = accounts
+	}
+
+	msg.AccountKeys = append(msg.AccountKeys, program)
+	msg.Header.NumReadonlyUnsignedAccounts++
+}
+
+func unitLimitData(limit uint32) []byte {
+	data := make([]byte, 5)
+	data[0] = computebudget.Instruction_SetComputeUnitLimit
+	binary.LittleEndian.PutUint32(data[1:], limit)
+
+	return data
+}
+
+func unitPriceData(price uint64) []byte {
+	data := make([]byte, 9)
+	data[0] = computebudget.Instruction_SetComputeUnitPrice
+	binary.LittleEndian.PutUint64(data[1:], price)
+
+	return data
+}
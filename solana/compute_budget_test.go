@@ -0,0 +1,156 @@
+package solana_test
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/stretchr/testify/require"
+
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+)
+
+func TestSetComputeBudget(t *testing.T) {
+	payer := solana.NewWallet().PublicKey()
+	recipient := solana.NewWallet().PublicKey()
+	table := solana.NewWallet().PublicKey()
+	tables := map[solana.PublicKey]solana.PublicKeySlice{table: {recipient}}
+
+	limit, price := uint32(300_000), uint64(50_000)
+
+	newTx := func(t *testing.T, instructions ...solana.Instruction) solana.Transaction {
+		t.Helper()
+
+		// The recipient is loaded from an address lookup table, after the static keys.
+		tx, err := solana.NewTransaction(
+			append(instructions, system.NewTransferInstruction(1000, payer, recipient).Build()),
+			solana.MustHashFromBase58("uiYzZ5PCq6C8BRSLSUGBScrXo62bBFbRFP9EkPcaWN9"),
+			solana.TransactionPayer(payer),
+			solana.TransactionAddressTables(tables),
+		)
+		require.NoError(t, err)
+
+		txBase64, err := tx.ToBase64()
+		require.NoError(t, err)
+
+		decoded, err := jupSolana.NewTransactionFromBase64(txBase64)
+		require.NoError(t, err)
+
+		return decoded
+	}
+
+	resolve := func(t *testing.T, tx solana.Transaction) solana.Transaction {
+		t.Helper()
+
+		// A copy is resolved, as resolving the lookups changes the account keys of the message.
+		data, err := tx.ToBase64()
+		require.NoError(t, err)
+
+		resolved, err := jupSolana.NewTransactionFromBase64(data)
+		require.NoError(t, err)
+		require.NoError(t, resolved.Message.SetAddressTables(tables))
+		require.NoError(t, resolved.Message.ResolveLookups())
+
+		return resolved
+	}
+
+	t.Run("insert the missing instructions", func(t *testing.T) {
+		original := newTx(t)
+
+		rewritten := newTx(t)
+		report, err := jupSolana.SetComputeBudget(&rewritten, jupSolana.ComputeBudget{UnitLimit: &limit, UnitPrice: &price})
+		require.NoError(t, err)
+		require.Equal(t, jupSolana.ComputeBudgetReport{NewUnitLimit: limit, NewUnitPrice: price}, report)
+
+		require.Len(t, rewritten.Message.AddressTableLookups, 1)
+		require.Len(t, rewritten.Signatures, 1)
+		require.Equal(t, uint8(2), rewritten.Message.Header.NumReadonlyUnsignedAccounts)
+
+		keys := instructionKeys(t, resolve(t, rewritten))
+		require.Equal(t, [][]solana.PublicKey{{solana.ComputeBudget}, {solana.ComputeBudget}}, keys[:2])
+		require.Equal(t, instructionKeys(t, resolve(t, original)), keys[2:])
+
+		require.Equal(t, []byte{2, 0xe0, 0x93, 0x04, 0}, []byte(rewritten.Message.Instructions[0].Data))
+		require.Equal(t, []byte{3, 0x50, 0xc3, 0, 0, 0, 0, 0, 0}, []byte(rewritten.Message.Instructions[1].Data))
+	})
+
+	t.Run("rewrite the existing instructions", func(t *testing.T) {
+		tx := newTx(t,
+			computebudget.NewSetComputeUnitLimitInstruction(1_400_000).Build(),
+			computebudget.NewSetComputeUnitPriceInstruction(1_000).Build(),
+		)
+
+		report, err := jupSolana.SetComputeBudget(&tx, jupSolana.ComputeBudget{UnitPrice: &price})
+		require.NoError(t, err)
+		require.Equal(t, jupSolana.ComputeBudgetReport{
+			OldUnitLimit: 1_400_000,
+			NewUnitLimit: 1_400_000,
+			OldUnitPrice: 1_000,
+			NewUnitPrice: price,
+		}, report)
+		require.Len(t, tx.Message.Instructions, 3)
+
+		// Rewriting again reports the new values as old ones.
+		report, err = jupSolana.SetComputeBudget(&tx, jupSolana.ComputeBudget{UnitLimit: &limit})
+		require.NoError(t, err)
+		require.Equal(t, jupSolana.ComputeBudgetReport{
+			OldUnitLimit: 1_400_000,
+			NewUnitLimit: limit,
+			OldUnitPrice: price,
+			NewUnitPrice: price,
+		}, report)
+		require.Len(t, tx.Message.Instructions, 3)
+	})
+
+	t.Run("keep the durable nonce first", func(t *testing.T) {
+		tx := newTx(t)
+
+		nonce := jupSolana.NonceAccount{Address: testNonceAccount, Authority: payer, Nonce: testNonce}
+		require.NoError(t, jupSolana.SetDurableNonce(&tx, nonce))
+
+		_, err := jupSolana.SetComputeBudget(&tx, jupSolana.ComputeBudget{UnitPrice: &price})
+		require.NoError(t, err)
+
+		nonceAccount, ok := jupSolana.DurableNonce(tx)
+		require.True(t, ok)
+		require.Equal(t, testNonceAccount, nonceAccount)
+
+		keys := instructionKeys(t, resolve(t, tx))
+		require.Equal(t, []solana.PublicKey{solana.ComputeBudget}, keys[1])
+		require.Equal(t, []solana.PublicKey{solana.SystemProgramID, payer, recipient}, keys[2])
+	})
+
+	t.Run("resolved lookups", func(t *testing.T) {
+		tx := resolve(t, newTx(t))
+
+		_, err := jupSolana.SetComputeBudget(&tx, jupSolana.ComputeBudget{UnitPrice: &price})
+		require.EqualError(t, err, "transaction address lookups must not be resolved")
+	})
+}
+
+func TestRewriteComputeBudget(t *testing.T) {
+	price := uint64(10_000)
+
+	t.Run("invalid transaction", func(t *testing.T) {
+		_, _, err := jupSolana.RewriteComputeBudget("invalid", jupSolana.ComputeBudget{UnitPrice: &price})
+		require.ErrorContains(t, err, "could not deserialize transaction")
+	})
+
+	t.Run("rewrite a swap transaction", func(t *testing.T) {
+		txBase64, report, err := jupSolana.RewriteComputeBudget(testTx, jupSolana.ComputeBudget{UnitPrice: &price})
+		require.NoError(t, err)
+		require.Equal(t, price, report.NewUnitPrice)
+
+		tx, err := jupSolana.NewTransactionFromBase64(txBase64)
+		require.NoError(t, err)
+
+		program, err := tx.Message.Program(tx.Message.Instructions[0].ProgramIDIndex)
+		require.NoError(t, err)
+		require.Equal(t, solana.ComputeBudget, program)
+
+		for _, sig := range tx.Signatures {
+			require.True(t, sig.IsZero())
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package solana
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+const (
+	wrappedSolMint = "So11111111111111111111111111111111111111112"
+	solDecimals    = 9
+)
+
+type decimalsService interface {
+	GetTokenSupply(
+		ctx context.Context,
+		tokenMint solana.PublicKey,
+		commitment rpc.CommitmentType,
+	) (*rpc.GetTokenSupplyResult, error)
+}
+
+type decimals struct {
+	rpcService decimalsService
+
+	mu    sync.Mutex
+	cache map[string]uint8
+}
+
+// NewDecimals creates a decimals resolver that fetches the decimals of mints from the given
+// RPC endpoint and caches them.
+func NewDecimals(rpcEndpoint string, opts ...DecimalsOption) (Decimals, error) {
+	d := &decimals{
+		cache: map[string]uint8{wrappedSolMint: solDecimals},
+	}
+
+	for _, opt := range opts {
+		if err := opt(d); err != nil {
+			return nil, fmt.Errorf("could not apply option: %w", err)
+		}
+	}
+
+	if d.rpcService == nil {
+		if rpcEndpoint == "" {
+			return nil, fmt.Errorf("rpcEndpoint is required when no RPC service is provided")
+		}
+
+		d.rpcService = rpc.New(rpcEndpoint)
+	}
+
+	return d, nil
+}
+
+func (d *decimals) Decimals(ctx context.Context, mint string) (uint8, error) {
+	d.mu.Lock()
+	dec, ok := d.cache[mint]
+	d.mu.Unlock()
+
+	if ok {
+		return dec, nil
+	}
+
+	mintPk, err := solana.PublicKeyFromBase58(mint)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse mint %s: %w", mint, err)
+	}
+
+	supply, err := d.rpcService.GetTokenSupply(ctx, mintPk, rpc.CommitmentConfirmed)
+	if err != nil {
+		return 0, fmt.Errorf("could not get token supply of %s: %w", mint, err)
+	}
+
+	if supply == nil || supply.Value == nil {
+		return 0, fmt.Errorf("could not get token supply of %s: empty response", mint)
+	}
+
+	d.mu.Lock()
+	d.cache[mint] = supply.Value.Decimals
+	d.mu.Unlock()
+
+	return supply.Value.Decimals, nil
+}
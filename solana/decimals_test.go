@@ -0,0 +1,102 @@
+package solana_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/require"
+
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+)
+
+type decimalsRPCMock struct {
+	decimals map[string]uint8
+	calls    *int
+}
+
+func (r decimalsRPCMock) GetTokenSupply(
+	_ context.Context,
+	tokenMint solana.PublicKey,
+	_ rpc.CommitmentType,
+) (*rpc.GetTokenSupplyResult, error) {
+	if r.calls != nil {
+		*r.calls++
+	}
+
+	dec, ok := r.decimals[tokenMint.String()]
+	if !ok {
+		return nil, errors.New("mocked error")
+	}
+
+	return &rpc.GetTokenSupplyResult{Value: &rpc.UiTokenAmount{Decimals: dec}}, nil
+}
+
+const (
+	testTokenMint = "JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN"
+	testQuoteMint = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	testSolMint   = "So11111111111111111111111111111111111111112"
+)
+
+func TestDecimals_Decimals(t *testing.T) {
+	t.Run("missing rpc endpoint", func(t *testing.T) {
+		_, err := jupSolana.NewDecimals("")
+		require.EqualError(t, err, "rpcEndpoint is required when no RPC service is provided")
+	})
+
+	t.Run("fetched once and cached", func(t *testing.T) {
+		calls := 0
+
+		d, err := jupSolana.NewDecimals("", jupSolana.WithDecimalsRPC(decimalsRPCMock{
+			decimals: map[string]uint8{testTokenMint: 6},
+			calls:    &calls,
+		}))
+		require.NoError(t, err)
+
+		for range 2 {
+			dec, err := d.Decimals(context.TODO(), testTokenMint)
+			require.NoError(t, err)
+			require.Equal(t, uint8(6), dec)
+		}
+
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("known mints are not fetched", func(t *testing.T) {
+		calls := 0
+
+		d, err := jupSolana.NewDecimals("",
+			jupSolana.WithDecimalsRPC(decimalsRPCMock{calls: &calls}),
+			jupSolana.WithKnownDecimals(map[string]uint8{testQuoteMint: 6}),
+		)
+		require.NoError(t, err)
+
+		dec, err := d.Decimals(context.TODO(), testQuoteMint)
+		require.NoError(t, err)
+		require.Equal(t, uint8(6), dec)
+
+		dec, err = d.Decimals(context.TODO(), testSolMint)
+		require.NoError(t, err)
+		require.Equal(t, uint8(9), dec)
+
+		require.Zero(t, calls)
+	})
+
+	t.Run("rpc error", func(t *testing.T) {
+		d, err := jupSolana.NewDecimals("", jupSolana.WithDecimalsRPC(decimalsRPCMock{}))
+		require.NoError(t, err)
+
+		_, err = d.Decimals(context.TODO(), testTokenMint)
+		require.EqualError(t, err, "could not get token supply of "+testTokenMint+": mocked error")
+	})
+
+	t.Run("invalid mint", func(t *testing.T) {
+		d, err := jupSolana.NewDecimals("", jupSolana.WithDecimalsRPC(decimalsRPCMock{}))
+		require.NoError(t, err)
+
+		_, err = d.Decimals(context.TODO(), "invalid")
+		require.ErrorContains(t, err, "could not parse mint invalid")
+	})
+}
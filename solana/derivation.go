@@ -0,0 +1,46 @@
+package solana
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+)
+
+const hardenedOffset = uint32(0x80000000)
+
+// deriveEd25519 derives the ed25519 private key seed at the hardened path from a BIP39 seed, following SLIP-10.
+// Path indexes are given without the hardened offset, as ed25519 only supports hardened derivation.
+func deriveEd25519(seed []byte, path []uint32) ([]byte, error) {
+	mac := hmac.New(sha512.New, []byte("ed25519 seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	key, chainCode := sum[:32], sum[32:]
+
+	data := make([]byte, 37)
+	defer clear(data)
+
+	for _, index := range path {
+		if index >= hardenedOffset {
+			clear(sum)
+			return nil, fmt.Errorf("derivation index %d is out of range", index)
+		}
+
+		data[0] = 0
+		copy(data[1:33], key)
+		binary.BigEndian.PutUint32(data[33:], index+hardenedOffset)
+
+		mac = hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		next := mac.Sum(nil)
+
+		clear(sum)
+		sum = next
+		key, chainCode = sum[:32], sum[32:]
+	}
+
+	clear(chainCode)
+
+	return key, nil
+}
@@ -0,0 +1,49 @@
+package solana
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeriveEd25519(t *testing.T) {
+	// Test vector 1 of SLIP-10 for ed25519.
+	seed, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	require.NoError(t, err)
+
+	tests := []struct {
+		path       []uint32
+		privateKey string
+		publicKey  string
+	}{
+		{
+			path:       nil,
+			privateKey: "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7",
+			publicKey:  "a4b2856bfec510abab89753fac1ac0e1112364e7d250545963f135f2a33188ed",
+		},
+		{
+			path:       []uint32{0},
+			privateKey: "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3",
+			publicKey:  "8c8a13df77a28f3445213a0f432fde644acaa215fc72dcdf300d5efaa85d350c",
+		},
+		{
+			path:       []uint32{0, 1, 2, 2, 1000000000},
+			privateKey: "8f94d394a8e8fd6b1bc2f3f49f5c47e385281d5c17e65324b0f62483e37e8793",
+			publicKey:  "3c24da049451555d51a7014a37337aa4e12d41e485abccfa46b47dfb2af54b7a",
+		},
+	}
+
+	for _, tt := range tests {
+		key, err := deriveEd25519(seed, tt.path)
+		require.NoError(t, err)
+		require.Equal(t, tt.privateKey, hex.EncodeToString(key))
+
+		publicKey := ed25519.NewKeyFromSeed(key).Public().(ed25519.PublicKey)
+		require.Equal(t, tt.publicKey, hex.EncodeToString(publicKey))
+	}
+
+	_, err = deriveEd25519(seed, []uint32{hardenedOffset})
+	require.EqualError(t, err, "derivation index 2147483648 is out of range")
+}
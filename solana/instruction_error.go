@@ -0,0 +1,110 @@
+package solana
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+)
+
+// InstructionError is the error a transaction failed with, as returned by the RPC,
+// e.g. {"InstructionError": [2, {"Custom": 6001}]}.
+type InstructionError struct {
+	Err any
+}
+
+func (e *InstructionError) Error() string {
+	return fmt.Sprintf("%v", e.Err)
+}
+
+// CustomErrorCode returns the custom program error code a transaction failed with. It reads an InstructionError, as
+// set by the monitor, or the error of a preflight simulation rejected by the RPC node when sending.
+func CustomErrorCode(err error) (uint32, bool) {
+	instructionErr, ok := instructionError(err)
+	if !ok {
+		return 0, false
+	}
+
+	detail, ok := instructionErr[1].(map[string]any)
+	if !ok {
+		return 0, false
+	}
+
+	return number(detail["Custom"])
+}
+
+// InstructionErrorIndex returns the index of the instruction a transaction failed at, read like CustomErrorCode.
+func InstructionErrorIndex(err error) (int, bool) {
+	instructionErr, ok := instructionError(err)
+	if !ok {
+		return 0, false
+	}
+
+	index, ok := number(instructionErr[0])
+
+	return int(index), ok
+}
+
+// instructionError returns the index and detail of the InstructionError of a failed transaction.
+func instructionError(err error) ([]any, bool) {
+	var (
+		txErr          any
+		instructionErr *InstructionError
+		rpcErr         *jsonrpc.RPCError
+	)
+
+	switch {
+	case errors.As(err, &instructionErr):
+		txErr = instructionErr.Err
+	case errors.As(err, &rpcErr):
+		data, ok := rpcErr.Data.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		txErr = data["err"]
+	default:
+		return nil, false
+	}
+
+	m, ok := txErr.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	detail, ok := m["InstructionError"].([]any)
+	if !ok || len(detail) != 2 {
+		return nil, false
+	}
+
+	return detail, true
+}
+
+// number reads an unsigned 32-bit integer decoded from JSON.
+func number(v any) (uint32, bool) {
+	var n float64
+
+	switch c := v.(type) {
+	case float64:
+		n = c
+	case int:
+		n = float64(c)
+	case json.Number:
+		f, err := c.Float64()
+		if err != nil {
+			return 0, false
+		}
+
+		n = f
+	default:
+		return 0, false
+	}
+
+	if n < 0 || n > math.MaxUint32 || n != math.Trunc(n) {
+		return 0, false
+	}
+
+	return uint32(n), true
+}
@@ -0,0 +1,95 @@
+package solana_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/stretchr/testify/require"
+
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+)
+
+func TestCustomErrorCode(t *testing.T) {
+	custom := func(code any) map[string]any {
+		return map[string]any{"InstructionError": []any{2, map[string]any{"Custom": code}}}
+	}
+
+	tests := []struct {
+		name   string
+		err    error
+		code   uint32
+		hasErr bool
+	}{
+		{
+			name:   "instruction error",
+			err:    fmt.Errorf("transaction confirmed with error: %w", &jupSolana.InstructionError{Err: custom(6001.0)}),
+			code:   6001,
+			hasErr: true,
+		},
+		{
+			name:   "json number",
+			err:    &jupSolana.InstructionError{Err: custom(json.Number("6001"))},
+			code:   6001,
+			hasErr: true,
+		},
+		{
+			name: "preflight failure",
+			err: fmt.Errorf("could not send transaction: %w", &jsonrpc.RPCError{
+				Code:    -32002,
+				Message: "Transaction simulation failed: Error processing Instruction 2: custom program error: 0x1771",
+				Data:    map[string]any{"err": custom(6001.0), "logs": []any{}},
+			}),
+			code:   6001,
+			hasErr: true,
+		},
+		{
+			name: "not a custom error",
+			err: &jupSolana.InstructionError{
+				Err: map[string]any{"InstructionError": []any{0, "InvalidAccountData"}},
+			},
+		},
+		{
+			name: "invalid code",
+			err:  &jupSolana.InstructionError{Err: custom(-1.0)},
+		},
+		{
+			name: "rpc error without data",
+			err:  &jsonrpc.RPCError{Code: -32005, Message: "Node is behind"},
+		},
+		{
+			name: "other error",
+			err:  errors.New("mocked error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, ok := jupSolana.CustomErrorCode(tt.err)
+			require.Equal(t, tt.hasErr, ok)
+			require.Equal(t, tt.code, code)
+		})
+	}
+
+	t.Run("instruction index", func(t *testing.T) {
+		index, ok := jupSolana.InstructionErrorIndex(&jupSolana.InstructionError{Err: custom(6001.0)})
+		require.True(t, ok)
+		require.Equal(t, 2, index)
+
+		index, ok = jupSolana.InstructionErrorIndex(&jsonrpc.RPCError{
+			Data: map[string]any{"err": map[string]any{"InstructionError": []any{json.Number("4"), "InvalidAccountData"}}},
+		})
+		require.True(t, ok)
+		require.Equal(t, 4, index)
+
+		_, ok = jupSolana.InstructionErrorIndex(errors.New("mocked error"))
+		require.False(t, ok)
+	})
+
+	t.Run("message of the rpc error", func(t *testing.T) {
+		err := &jupSolana.InstructionError{Err: custom(6001.0)}
+		require.EqualError(t, err, "map[InstructionError:[2 map[Custom:6001]]]")
+	})
+}
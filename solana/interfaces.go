@@ -27,13 +27,91 @@ type rpcService interface {
 		account solana.PublicKey,
 		commitment rpc.CommitmentType, // optional
 	) (out *rpc.GetTokenAccountBalanceResult, err error)
+	GetBalance(
+		ctx context.Context,
+		account solana.PublicKey,
+		commitment rpc.CommitmentType,
+	) (out *rpc.GetBalanceResult, err error)
+	SimulateTransactionWithOpts(
+		ctx context.Context,
+		transaction *solana.Transaction,
+		opts *rpc.SimulateTransactionOpts,
+	) (out *rpc.SimulateTransactionResponse, err error)
+	GetAccountInfoWithOpts(
+		ctx context.Context,
+		account solana.PublicKey,
+		opts *rpc.GetAccountInfoOpts,
+	) (*rpc.GetAccountInfoResult, error)
+	GetMinimumBalanceForRentExemption(
+		ctx context.Context,
+		dataSize uint64,
+		commitment rpc.CommitmentType,
+	) (lamport uint64, err error)
+	GetBlockHeight(
+		ctx context.Context,
+		commitment rpc.CommitmentType,
+	) (out uint64, err error)
 	Close() error
 }
 
+// TransactionVerifier checks a transaction before it is signed.
+type TransactionVerifier interface {
+	VerifyTransaction(context.Context, solana.Transaction) error
+}
+
 type Client interface {
 	SendTransactionOnChain(context.Context, string) (TxID, error)
-	CheckSignature(context.Context, TxID) (bool, error)
-	GetTokenAccountBalance(context.Context, string) (TokenAccount, error)
+	SendTransaction(context.Context, *solana.Transaction, SendOptions) (SendResult, error)
+	CheckSignature(context.Context, TxID, ...ReadOption) (bool, error)
+	GetTokenAccountBalance(context.Context, string, ...ReadOption) (TokenAccount, error)
+	BlockHeightSource
+}
+
+// WatchOnlyClient queries, simulates and builds transactions for a public key without holding its private key.
+type WatchOnlyClient interface {
+	Client
+	PublicKey() solana.PublicKey
+	GetBalance(context.Context, ...ReadOption) (uint64, error)
+	SimulateTransaction(context.Context, string, ...ReadOption) (SimulationResult, error)
+	ExportUnsignedTransaction(context.Context, string) (string, error)
+	SendSignedTransaction(context.Context, string) (TxID, error)
+	NonceSource
+	UseDurableNonce(context.Context, string, solana.PublicKey) (string, error)
+}
+
+// NonceSource reads durable nonce accounts and whether transactions were processed, to tell when a durable nonce
+// transaction can no longer land.
+type NonceSource interface {
+	GetNonceAccount(context.Context, solana.PublicKey, ...ReadOption) (NonceAccount, error)
+	IsProcessed(context.Context, TxID) (bool, error)
+}
+
+// BlockHeightSource reads the block height and whether transactions were processed, to tell when a transaction
+// can no longer land because its blockhash expired.
+type BlockHeightSource interface {
+	GetBlockHeight(context.Context, ...ReadOption) (uint64, error)
+	IsProcessed(context.Context, TxID) (bool, error)
+}
+
+// NonceClient creates and advances durable nonce accounts whose authority is the client's wallet,
+// and rewrites transactions to use them.
+type NonceClient interface {
+	Client
+	NonceSource
+	CreateNonceAccount(context.Context) (solana.PublicKey, TxID, error)
+	AdvanceNonceAccount(context.Context, solana.PublicKey) (TxID, error)
+	UseDurableNonce(context.Context, string, solana.PublicKey) (string, error)
+}
+
+// Decimals resolves the number of decimals of a mint.
+type Decimals interface {
+	Decimals(ctx context.Context, mint string) (uint8, error)
+}
+
+// BlockhashCache keeps the latest blockhash, refreshed in the background.
+type BlockhashCache interface {
+	Latest(context.Context) (Blockhash, error)
+	Close()
 }
 
 type subscriberService interface {
@@ -46,4 +124,6 @@ type subscriberService interface {
 
 type Monitor interface {
 	WaitForCommitmentStatus(context.Context, TxID, CommitmentStatus) (MonitorResponse, error)
+	WaitForTransaction(context.Context, TxID, CommitmentStatus, uint64) (MonitorResponse, error)
+	WaitForNonceTransaction(context.Context, TxID, CommitmentStatus, NonceAccount) (MonitorResponse, error)
 }
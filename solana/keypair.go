@@ -0,0 +1,122 @@
+package solana
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// solanaCoinType is the BIP44 coin type of Solana.
+const solanaCoinType = 501
+
+// NewWalletFromKeypairFile loads a wallet from a keypair file written by solana-keygen, e.g. ~/.config/solana/id.json.
+func NewWalletFromKeypairFile(path string) (Wallet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Wallet{}, fmt.Errorf("could not read keypair file: %w", err)
+	}
+
+	defer clear(data)
+
+	return NewWalletFromKeypairJSON(data)
+}
+
+// NewWalletFromKeypairJSON loads a wallet from the solana-keygen format: a JSON array of the 64 bytes of the keypair.
+func NewWalletFromKeypairJSON(data []byte) (Wallet, error) {
+	var keypair []byte
+	if err := json.Unmarshal(data, &keypair); err != nil {
+		return Wallet{}, fmt.Errorf("could not decode keypair: expected a JSON array of bytes")
+	}
+
+	defer clear(keypair)
+
+	privateKey, err := privateKeyFromKeypair(keypair)
+	if err != nil {
+		return Wallet{}, err
+	}
+
+	return Wallet{&solana.Wallet{PrivateKey: privateKey}}, nil
+}
+
+// NewWalletFromMnemonic derives a wallet from a BIP39 mnemonic and optional passphrase at the
+// path m/44'/501'/account'/0', the one of Phantom, Solflare and most wallets. It differs from
+// solana-keygen, which uses the seed itself unless given a derivation path.
+func NewWalletFromMnemonic(mnemonic, passphrase string, account uint32) (Wallet, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(strings.Join(strings.Fields(mnemonic), " "), passphrase)
+	if err != nil {
+		return Wallet{}, fmt.Errorf("invalid mnemonic: %w", err)
+	}
+
+	defer clear(seed)
+
+	key, err := deriveEd25519(seed, []uint32{44, solanaCoinType, account, 0})
+	if err != nil {
+		return Wallet{}, fmt.Errorf("could not derive key: %w", err)
+	}
+
+	defer clear(key)
+
+	privateKey := solana.PrivateKey(ed25519.NewKeyFromSeed(key))
+
+	return Wallet{&solana.Wallet{PrivateKey: privateKey}}, nil
+}
+
+// NewWalletFromEnv loads a wallet from an environment variable holding either a base58 private key
+// or a solana-keygen JSON array.
+func NewWalletFromEnv(name string) (Wallet, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return Wallet{}, fmt.Errorf("environment variable %s is not set", name)
+	}
+
+	if strings.HasPrefix(value, "[") {
+		w, err := NewWalletFromKeypairJSON([]byte(value))
+		if err != nil {
+			return Wallet{}, fmt.Errorf("could not load %s: %w", name, err)
+		}
+
+		return w, nil
+	}
+
+	w, err := NewWalletFromPrivateKeyBase58(value)
+	if err != nil {
+		return Wallet{}, fmt.Errorf("could not load %s: %w", name, err)
+	}
+
+	if err := validateKeypair(w.PrivateKey); err != nil {
+		return Wallet{}, fmt.Errorf("could not load %s: %w", name, err)
+	}
+
+	return w, nil
+}
+
+// privateKeyFromKeypair copies a 64 bytes keypair into a private key after validating it.
+func privateKeyFromKeypair(keypair []byte) (solana.PrivateKey, error) {
+	if err := validateKeypair(keypair); err != nil {
+		return nil, err
+	}
+
+	return solana.PrivateKey(bytes.Clone(keypair)), nil
+}
+
+// validateKeypair checks the keypair is 64 bytes long and its public key half matches its private key half.
+func validateKeypair(keypair []byte) error {
+	if len(keypair) != ed25519.PrivateKeySize {
+		return fmt.Errorf("invalid keypair length %d, expected %d", len(keypair), ed25519.PrivateKeySize)
+	}
+
+	derived := ed25519.NewKeyFromSeed(keypair[:ed25519.SeedSize])
+	defer clear(derived)
+
+	if !bytes.Equal(derived[ed25519.SeedSize:], keypair[ed25519.SeedSize:]) {
+		return fmt.Errorf("invalid keypair: public key does not match private key")
+	}
+
+	return nil
+}
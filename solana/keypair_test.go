@@ -0,0 +1,152 @@
+package solana_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/require"
+
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+)
+
+const (
+	testPrivateKey = "5473ZnvEhn35BdcCcPLKnzsyP6TsgqQrNFpn4i2gFegFiiJLyWginpa9GoFn2cy6Aq2EAuxLt2u2bjFDBPvNY6nw"
+	testMnemonic   = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+)
+
+// testKeypairJSON returns the test private key in the solana-keygen format, an array of numbers.
+func testKeypairJSON(t *testing.T) []byte {
+	var numbers []int
+	for _, b := range solana.MustPrivateKeyFromBase58(testPrivateKey) {
+		numbers = append(numbers, int(b))
+	}
+
+	data, err := json.Marshal(numbers)
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestNewWalletFromKeypairFile(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("valid keypair file", func(t *testing.T) {
+		path := filepath.Join(dir, "id.json")
+		require.NoError(t, os.WriteFile(path, testKeypairJSON(t), 0o600))
+
+		wallet, err := jupSolana.NewWalletFromKeypairFile(path)
+		require.NoError(t, err)
+		require.Equal(t, testPrivateKey, wallet.PrivateKey.String())
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := jupSolana.NewWalletFromKeypairFile(filepath.Join(dir, "missing.json"))
+		require.ErrorContains(t, err, "could not read keypair file")
+	})
+}
+
+func TestNewWalletFromKeypairJSON(t *testing.T) {
+	t.Run("valid keypair", func(t *testing.T) {
+		wallet, err := jupSolana.NewWalletFromKeypairJSON(testKeypairJSON(t))
+		require.NoError(t, err)
+		require.Equal(t, testPrivateKey, wallet.PrivateKey.String())
+	})
+
+	t.Run("not an array of bytes", func(t *testing.T) {
+		_, err := jupSolana.NewWalletFromKeypairJSON([]byte(`[1, 2, 256]`))
+		require.EqualError(t, err, "could not decode keypair: expected a JSON array of bytes")
+	})
+
+	t.Run("wrong length", func(t *testing.T) {
+		_, err := jupSolana.NewWalletFromKeypairJSON([]byte(`[1, 2, 3]`))
+		require.EqualError(t, err, "invalid keypair length 3, expected 64")
+	})
+
+	t.Run("public key does not match", func(t *testing.T) {
+		var numbers []int
+		require.NoError(t, json.Unmarshal(testKeypairJSON(t), &numbers))
+		numbers[63]++
+
+		data, err := json.Marshal(numbers)
+		require.NoError(t, err)
+
+		_, err = jupSolana.NewWalletFromKeypairJSON(data)
+		require.EqualError(t, err, "invalid keypair: public key does not match private key")
+	})
+}
+
+func TestNewWalletFromMnemonic(t *testing.T) {
+	t.Run("derivation path of Phantom and Solflare", func(t *testing.T) {
+		wallet, err := jupSolana.NewWalletFromMnemonic(testMnemonic, "", 0)
+		require.NoError(t, err)
+		require.Equal(t, "HAgk14JpMQLgt6rVgv7cBQFJWFto5Dqxi472uT3DKpqk", wallet.PublicKey().String())
+
+		other, err := jupSolana.NewWalletFromMnemonic(testMnemonic, "", 1)
+		require.NoError(t, err)
+		require.NotEqual(t, wallet.PublicKey(), other.PublicKey())
+	})
+
+	t.Run("passphrase changes the wallet", func(t *testing.T) {
+		wallet, err := jupSolana.NewWalletFromMnemonic(testMnemonic, "", 0)
+		require.NoError(t, err)
+
+		withPassphrase, err := jupSolana.NewWalletFromMnemonic(testMnemonic, "secret", 0)
+		require.NoError(t, err)
+		require.NotEqual(t, wallet.PublicKey(), withPassphrase.PublicKey())
+	})
+
+	t.Run("extra whitespace is ignored", func(t *testing.T) {
+		wallet, err := jupSolana.NewWalletFromMnemonic("  abandon abandon abandon abandon abandon abandon\n"+
+			"abandon abandon abandon abandon abandon  about ", "", 0)
+		require.NoError(t, err)
+		require.Equal(t, "HAgk14JpMQLgt6rVgv7cBQFJWFto5Dqxi472uT3DKpqk", wallet.PublicKey().String())
+	})
+
+	t.Run("invalid checksum", func(t *testing.T) {
+		_, err := jupSolana.NewWalletFromMnemonic(
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon", "", 0)
+		require.ErrorContains(t, err, "invalid mnemonic")
+	})
+
+	t.Run("account out of range", func(t *testing.T) {
+		_, err := jupSolana.NewWalletFromMnemonic(testMnemonic, "", 1<<31)
+		require.EqualError(t, err, "could not derive key: derivation index 2147483648 is out of range")
+	})
+}
+
+func TestNewWalletFromEnv(t *testing.T) {
+	const name = "JUPITER_GO_TEST_PRIVATE_KEY"
+
+	t.Run("base58 private key", func(t *testing.T) {
+		t.Setenv(name, testPrivateKey)
+
+		wallet, err := jupSolana.NewWalletFromEnv(name)
+		require.NoError(t, err)
+		require.Equal(t, testPrivateKey, wallet.PrivateKey.String())
+	})
+
+	t.Run("keypair JSON", func(t *testing.T) {
+		t.Setenv(name, string(testKeypairJSON(t)))
+
+		wallet, err := jupSolana.NewWalletFromEnv(name)
+		require.NoError(t, err)
+		require.Equal(t, testPrivateKey, wallet.PrivateKey.String())
+	})
+
+	t.Run("not set", func(t *testing.T) {
+		t.Setenv(name, "")
+
+		_, err := jupSolana.NewWalletFromEnv(name)
+		require.EqualError(t, err, "environment variable JUPITER_GO_TEST_PRIVATE_KEY is not set")
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		t.Setenv(name, "[1, 2, 3]")
+
+		_, err := jupSolana.NewWalletFromEnv(name)
+		require.EqualError(t, err, "could not load JUPITER_GO_TEST_PRIVATE_KEY: invalid keypair length 3, expected 64")
+	})
+}
@@ -0,0 +1,19 @@
+package keystore
+
+import (
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+)
+
+// Keystore stores wallets encrypted with a passphrase in a directory, one file per key.
+type Keystore interface {
+	// Create generates a new wallet and stores it under the name.
+	Create(name, passphrase string) (Key, error)
+	// Import stores an existing wallet under the name.
+	Import(name, passphrase string, wallet jupSolana.Wallet) (Key, error)
+	// Export decrypts the key and returns it in the solana-keygen JSON format.
+	Export(name, passphrase string) ([]byte, error)
+	// List returns the keys of the keystore, sorted by name. Listing does not decrypt them.
+	List() ([]Key, error)
+	// Wallet decrypts the key and returns its wallet.
+	Wallet(name, passphrase string) (jupSolana.Wallet, error)
+}
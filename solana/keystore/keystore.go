@@ -0,0 +1,390 @@
+// Package keystore stores Solana wallets encrypted at rest. Each key is a JSON file whose private key is
+// encrypted with AES-256-GCM under a key derived from a passphrase with scrypt.
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"golang.org/x/crypto/scrypt"
+
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+)
+
+const (
+	version = 1
+
+	kdfScrypt       = "scrypt"
+	cipherAES256GCM = "aes-256-gcm"
+
+	keyLen  = 32
+	saltLen = 32
+
+	fileExt = ".json"
+)
+
+var (
+	// ErrKeyNotFound is returned when no key is stored under a name.
+	ErrKeyNotFound = errors.New("key not found")
+	// ErrKeyExists is returned when a key is already stored under a name.
+	ErrKeyExists = errors.New("key already exists")
+	// ErrWrongPassphrase is returned when a key cannot be decrypted with the passphrase.
+	ErrWrongPassphrase = errors.New("wrong passphrase")
+
+	validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+	// defaultScryptParams take about 200ms and 64MB to derive a key.
+	defaultScryptParams = scryptParams{N: 1 << 16, R: 8, P: 1}
+)
+
+// Key describes a stored key without its secret.
+type Key struct {
+	Name      string    `json:"name"`
+	PublicKey string    `json:"publicKey"`
+	CreatedAt time.Time `json:"createdAt"`
+	Path      string    `json:"path"`
+}
+
+type scryptParams struct {
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt string `json:"salt"`
+}
+
+type cryptoParams struct {
+	KDF        string       `json:"kdf"`
+	KDFParams  scryptParams `json:"kdfParams"`
+	Cipher     string       `json:"cipher"`
+	Nonce      string       `json:"nonce"`
+	Ciphertext string       `json:"ciphertext"`
+}
+
+// keyFile is the format of a key on disk.
+type keyFile struct {
+	Version   int          `json:"version"`
+	Name      string       `json:"name"`
+	PublicKey string       `json:"publicKey"`
+	CreatedAt time.Time    `json:"createdAt"`
+	Crypto    cryptoParams `json:"crypto"`
+}
+
+type keystore struct {
+	dir    string
+	params scryptParams
+}
+
+// NewKeystore opens the keystore in the directory, creating it if needed with permissions for the owner only.
+func NewKeystore(dir string, opts ...Option) (Keystore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("dir is required")
+	}
+
+	k := &keystore{
+		dir:    dir,
+		params: defaultScryptParams,
+	}
+
+	for _, opt := range opts {
+		if err := opt(k); err != nil {
+			return nil, fmt.Errorf("could not apply option: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("could not create keystore directory: %w", err)
+	}
+
+	return k, nil
+}
+
+// Create generates a new wallet and stores it under the name.
+func (k keystore) Create(name, passphrase string) (Key, error) {
+	return k.Import(name, passphrase, jupSolana.Wallet{Wallet: solana.NewWallet()})
+}
+
+// Import encrypts the wallet with the passphrase and stores it under the name.
+func (k keystore) Import(name, passphrase string, wallet jupSolana.Wallet) (Key, error) {
+	if err := validateName(name); err != nil {
+		return Key{}, err
+	}
+
+	if passphrase == "" {
+		return Key{}, fmt.Errorf("passphrase is required")
+	}
+
+	if wallet.Wallet == nil || len(wallet.PrivateKey) != 64 {
+		return Key{}, fmt.Errorf("wallet is required")
+	}
+
+	f := keyFile{
+		Version:   version,
+		Name:      name,
+		PublicKey: wallet.PublicKey().String(),
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+
+	crypto, err := encrypt(wallet.PrivateKey, passphrase, f.PublicKey, k.params)
+	if err != nil {
+		return Key{}, err
+	}
+
+	f.Crypto = crypto
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return Key{}, fmt.Errorf("could not encode key: %w", err)
+	}
+
+	path := k.path(name)
+
+	// O_EXCL makes sure an existing key is never overwritten.
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		return Key{}, fmt.Errorf("%w: %s", ErrKeyExists, name)
+	}
+
+	if err != nil {
+		return Key{}, fmt.Errorf("could not create key file: %w", err)
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(path)
+
+		return Key{}, fmt.Errorf("could not write key file: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		os.Remove(path)
+
+		return Key{}, fmt.Errorf("could not write key file: %w", err)
+	}
+
+	return f.key(path), nil
+}
+
+// Export decrypts the key and returns it in the solana-keygen JSON format, an array of the 64 bytes of the keypair.
+func (k keystore) Export(name, passphrase string) ([]byte, error) {
+	wallet, err := k.Wallet(name, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	defer clear(wallet.PrivateKey)
+
+	numbers := make([]int, len(wallet.PrivateKey))
+	for i, b := range wallet.PrivateKey {
+		numbers[i] = int(b)
+	}
+
+	defer clear(numbers)
+
+	data, err := json.Marshal(numbers)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode keypair: %w", err)
+	}
+
+	return data, nil
+}
+
+// List returns the keys of the keystore, sorted by name.
+func (k keystore) List() ([]Key, error) {
+	entries, err := os.ReadDir(k.dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read keystore directory: %w", err)
+	}
+
+	var keys []Key
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileExt) {
+			continue
+		}
+
+		path := filepath.Join(k.dir, entry.Name())
+
+		f, err := readKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, f.key(path))
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Name < keys[j].Name
+	})
+
+	return keys, nil
+}
+
+// Wallet decrypts the key stored under the name with the passphrase.
+func (k keystore) Wallet(name, passphrase string) (jupSolana.Wallet, error) {
+	if err := validateName(name); err != nil {
+		return jupSolana.Wallet{}, err
+	}
+
+	path := k.path(name)
+
+	f, err := readKeyFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return jupSolana.Wallet{}, fmt.Errorf("%w: %s", ErrKeyNotFound, name)
+	}
+
+	if err != nil {
+		return jupSolana.Wallet{}, err
+	}
+
+	privateKey, err := decrypt(f.Crypto, passphrase, f.PublicKey)
+	if err != nil {
+		return jupSolana.Wallet{}, fmt.Errorf("could not decrypt key %s: %w", name, err)
+	}
+
+	wallet := jupSolana.Wallet{Wallet: &solana.Wallet{PrivateKey: privateKey}}
+
+	if wallet.PublicKey().String() != f.PublicKey {
+		clear(privateKey)
+		return jupSolana.Wallet{}, fmt.Errorf("could not decrypt key %s: public key does not match", name)
+	}
+
+	return wallet, nil
+}
+
+func (k keystore) path(name string) string {
+	return filepath.Join(k.dir, name+fileExt)
+}
+
+func (f keyFile) key(path string) Key {
+	return Key{
+		Name:      f.Name,
+		PublicKey: f.PublicKey,
+		CreatedAt: f.CreatedAt,
+		Path:      path,
+	}
+}
+
+func validateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid key name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+
+	return nil
+}
+
+func readKeyFile(path string) (keyFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return keyFile{}, fmt.Errorf("could not read key file: %w", err)
+	}
+
+	var f keyFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return keyFile{}, fmt.Errorf("could not decode key file %s: %w", path, err)
+	}
+
+	if f.Version != version {
+		return keyFile{}, fmt.Errorf("unsupported key file version %d in %s", f.Version, path)
+	}
+
+	return f, nil
+}
+
+// encrypt encrypts the private key, authenticating the public key as additional data.
+func encrypt(privateKey []byte, passphrase, publicKey string, params scryptParams) (cryptoParams, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return cryptoParams{}, fmt.Errorf("could not generate salt: %w", err)
+	}
+
+	params.Salt = hex.EncodeToString(salt)
+
+	gcm, err := newGCM(passphrase, params)
+	if err != nil {
+		return cryptoParams{}, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return cryptoParams{}, fmt.Errorf("could not generate nonce: %w", err)
+	}
+
+	return cryptoParams{
+		KDF:        kdfScrypt,
+		KDFParams:  params,
+		Cipher:     cipherAES256GCM,
+		Nonce:      hex.EncodeToString(nonce),
+		Ciphertext: hex.EncodeToString(gcm.Seal(nil, nonce, privateKey, []byte(publicKey))),
+	}, nil
+}
+
+func decrypt(c cryptoParams, passphrase, publicKey string) ([]byte, error) {
+	if c.KDF != kdfScrypt || c.Cipher != cipherAES256GCM {
+		return nil, fmt.Errorf("unsupported kdf %q or cipher %q", c.KDF, c.Cipher)
+	}
+
+	nonce, err := hex.DecodeString(c.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nonce: %w", err)
+	}
+
+	ciphertext, err := hex.DecodeString(c.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext: %w", err)
+	}
+
+	gcm, err := newGCM(passphrase, c.KDFParams)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce length %d", len(nonce))
+	}
+
+	privateKey, err := gcm.Open(nil, nonce, ciphertext, []byte(publicKey))
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	return privateKey, nil
+}
+
+// newGCM derives the encryption key from the passphrase. The derived key is zeroed once the cipher is created.
+func newGCM(passphrase string, params scryptParams) (cipher.AEAD, error) {
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil || len(salt) == 0 {
+		return nil, fmt.Errorf("invalid salt")
+	}
+
+	key, err := scrypt.Key([]byte(passphrase), salt, params.N, params.R, params.P, keyLen)
+	if err != nil {
+		return nil, fmt.Errorf("could not derive key: %w", err)
+	}
+
+	defer clear(key)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not create cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("could not create cipher: %w", err)
+	}
+
+	return gcm, nil
+}
@@ -0,0 +1,157 @@
+package keystore_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+	"github.com/ilkamo/jupiter-go/solana/keystore"
+)
+
+const testPrivateKey = "5473ZnvEhn35BdcCcPLKnzsyP6TsgqQrNFpn4i2gFegFiiJLyWginpa9GoFn2cy6Aq2EAuxLt2u2bjFDBPvNY6nw"
+
+func newTestKeystore(t *testing.T) (keystore.Keystore, string) {
+	dir := filepath.Join(t.TempDir(), "keys")
+
+	// Cheap scrypt parameters keep the tests fast.
+	ks, err := keystore.NewKeystore(dir, keystore.WithScryptParams(1<<10, 8, 1))
+	require.NoError(t, err)
+
+	return ks, dir
+}
+
+func TestNewKeystore(t *testing.T) {
+	t.Run("directory is created for the owner only", func(t *testing.T) {
+		_, dir := newTestKeystore(t)
+
+		info, err := os.Stat(dir)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		_, err := keystore.NewKeystore("")
+		require.EqualError(t, err, "dir is required")
+	})
+
+	t.Run("invalid scrypt parameters", func(t *testing.T) {
+		_, err := keystore.NewKeystore(t.TempDir(), keystore.WithScryptParams(1000, 8, 1))
+		require.EqualError(t, err, "could not apply option: scrypt N must be a power of 2 greater than 1")
+
+		_, err = keystore.NewKeystore(t.TempDir(), keystore.WithScryptParams(1024, 0, 1))
+		require.EqualError(t, err, "could not apply option: scrypt r and p must be positive")
+	})
+}
+
+func TestKeystore(t *testing.T) {
+	ks, dir := newTestKeystore(t)
+
+	imported, err := jupSolana.NewWalletFromPrivateKeyBase58(testPrivateKey)
+	require.NoError(t, err)
+
+	t.Run("import and load", func(t *testing.T) {
+		key, err := ks.Import("ops", "correct horse", imported)
+		require.NoError(t, err)
+		require.Equal(t, "ops", key.Name)
+		require.Equal(t, imported.PublicKey().String(), key.PublicKey)
+		require.Equal(t, filepath.Join(dir, "ops.json"), key.Path)
+
+		info, err := os.Stat(key.Path)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+		data, err := os.ReadFile(key.Path)
+		require.NoError(t, err)
+		require.NotContains(t, string(data), testPrivateKey)
+
+		wallet, err := ks.Wallet("ops", "correct horse")
+		require.NoError(t, err)
+		require.Equal(t, testPrivateKey, wallet.PrivateKey.String())
+	})
+
+	t.Run("create", func(t *testing.T) {
+		key, err := ks.Create("bot-1", "passphrase")
+		require.NoError(t, err)
+
+		wallet, err := ks.Wallet("bot-1", "passphrase")
+		require.NoError(t, err)
+		require.Equal(t, key.PublicKey, wallet.PublicKey().String())
+	})
+
+	t.Run("existing key is not overwritten", func(t *testing.T) {
+		_, err := ks.Create("ops", "passphrase")
+		require.ErrorIs(t, err, keystore.ErrKeyExists)
+
+		wallet, err := ks.Wallet("ops", "correct horse")
+		require.NoError(t, err)
+		require.Equal(t, testPrivateKey, wallet.PrivateKey.String())
+	})
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		_, err := ks.Wallet("ops", "wrong")
+		require.ErrorIs(t, err, keystore.ErrWrongPassphrase)
+	})
+
+	t.Run("key not found", func(t *testing.T) {
+		_, err := ks.Wallet("missing", "passphrase")
+		require.ErrorIs(t, err, keystore.ErrKeyNotFound)
+	})
+
+	t.Run("invalid names", func(t *testing.T) {
+		for _, name := range []string{"", "../ops", "a/b", ".hidden"} {
+			_, err := ks.Create(name, "passphrase")
+			require.ErrorContains(t, err, "invalid key name")
+		}
+	})
+
+	t.Run("passphrase is required", func(t *testing.T) {
+		_, err := ks.Create("empty", "")
+		require.EqualError(t, err, "passphrase is required")
+	})
+
+	t.Run("export in the solana-keygen format", func(t *testing.T) {
+		data, err := ks.Export("ops", "correct horse")
+		require.NoError(t, err)
+
+		wallet, err := jupSolana.NewWalletFromKeypairJSON(data)
+		require.NoError(t, err)
+		require.Equal(t, testPrivateKey, wallet.PrivateKey.String())
+
+		_, err = ks.Export("ops", "wrong")
+		require.ErrorIs(t, err, keystore.ErrWrongPassphrase)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("not a key"), 0o600))
+
+		keys, err := ks.List()
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+		require.Equal(t, "bot-1", keys[0].Name)
+		require.Equal(t, "ops", keys[1].Name)
+		require.Equal(t, imported.PublicKey().String(), keys[1].PublicKey)
+		require.False(t, keys[1].CreatedAt.IsZero())
+	})
+
+	t.Run("tampered public key", func(t *testing.T) {
+		path := filepath.Join(dir, "ops.json")
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		var f map[string]any
+		require.NoError(t, json.Unmarshal(data, &f))
+		f["publicKey"] = "BXzmfHxfEMcMj8hDccUNdrwXVNeybyfb2iV2nktE1VnJ"
+
+		data, err = json.Marshal(f)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "tampered.json"), data, 0o600))
+
+		_, err = ks.Wallet("tampered", "correct horse")
+		require.ErrorIs(t, err, keystore.ErrWrongPassphrase)
+	})
+}
@@ -0,0 +1,23 @@
+package keystore
+
+import "fmt"
+
+// Option is a function that allows to specify options for the keystore.
+type Option func(*keystore) error
+
+// WithScryptParams sets the scrypt cost parameters used to derive the encryption key of new keys.
+// Keys store their parameters, so existing keys are decrypted with the parameters they were created with.
+func WithScryptParams(n, r, p int) Option {
+	return func(k *keystore) error {
+		if n < 2 || n&(n-1) != 0 {
+			return fmt.Errorf("scrypt N must be a power of 2 greater than 1")
+		}
+
+		if r <= 0 || p <= 0 {
+			return fmt.Errorf("scrypt r and p must be positive")
+		}
+
+		k.params = scryptParams{N: n, R: r, P: p}
+		return nil
+	}
+}
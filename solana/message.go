@@ -0,0 +1,240 @@
+package solana
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// MessageFormat is the format of the text of an off-chain message.
+type MessageFormat uint8
+
+const (
+	// MessageFormatRestrictedASCII is printable ASCII, from 0x20 to 0x7e, that fits the ledger limit.
+	MessageFormatRestrictedASCII MessageFormat = 0
+	// MessageFormatLimitedUTF8 is UTF-8 text that fits the ledger limit.
+	MessageFormatLimitedUTF8 MessageFormat = 1
+	// MessageFormatExtendedUTF8 is UTF-8 text of up to 65535 bytes, which hardware wallets may refuse to sign.
+	MessageFormatExtendedUTF8 MessageFormat = 2
+)
+
+const (
+	offchainMessageVersion = 0
+	// offchainLedgerMaxLen is the maximum length of a serialized message of the limited formats.
+	offchainLedgerMaxLen = 1232
+)
+
+// offchainSigningDomain prefixes every off-chain message, so that it can never be a valid transaction message.
+var offchainSigningDomain = []byte("\xffsolana offchain")
+
+// ErrInvalidSignature is returned when a signature does not match the message and public key.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// OffchainMessage is a message in the Solana off-chain message envelope (version 0). The envelope binds the text
+// to an application domain and to its signers, and cannot be mistaken for a transaction.
+type OffchainMessage struct {
+	// ApplicationDomain identifies the application the message is for, e.g. the sha256 of its domain name.
+	ApplicationDomain [32]byte
+	Format            MessageFormat
+	Signers           []solana.PublicKey
+	Message           []byte
+}
+
+// NewOffchainMessage creates an off-chain message with the most restrictive format its text allows.
+func NewOffchainMessage(
+	applicationDomain [32]byte,
+	message []byte,
+	signers ...solana.PublicKey,
+) (OffchainMessage, error) {
+	m := OffchainMessage{
+		ApplicationDomain: applicationDomain,
+		Signers:           signers,
+		Message:           message,
+	}
+
+	fitsLedger := m.headerLen()+len(message) <= offchainLedgerMaxLen
+
+	switch {
+	case fitsLedger && isRestrictedASCII(message):
+		m.Format = MessageFormatRestrictedASCII
+	case fitsLedger:
+		m.Format = MessageFormatLimitedUTF8
+	default:
+		m.Format = MessageFormatExtendedUTF8
+	}
+
+	if err := m.validate(); err != nil {
+		return OffchainMessage{}, err
+	}
+
+	return m, nil
+}
+
+// MarshalBinary serializes the message in the envelope, the bytes that are signed.
+func (m OffchainMessage) MarshalBinary() ([]byte, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 0, m.headerLen()+len(m.Message))
+	b = append(b, offchainSigningDomain...)
+	b = append(b, offchainMessageVersion)
+	b = append(b, m.ApplicationDomain[:]...)
+	b = append(b, byte(m.Format), byte(len(m.Signers)))
+
+	for _, signer := range m.Signers {
+		b = append(b, signer[:]...)
+	}
+
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(m.Message)))
+	b = append(b, m.Message...)
+
+	return b, nil
+}
+
+// ParseOffchainMessage deserializes and validates a message in the envelope.
+func ParseOffchainMessage(b []byte) (OffchainMessage, error) {
+	if !bytes.HasPrefix(b, offchainSigningDomain) {
+		return OffchainMessage{}, fmt.Errorf("invalid off-chain message: missing signing domain")
+	}
+
+	b = b[len(offchainSigningDomain):]
+
+	// version, application domain, format and signer count.
+	if len(b) < 1+32+1+1 {
+		return OffchainMessage{}, fmt.Errorf("invalid off-chain message: too short")
+	}
+
+	if b[0] != offchainMessageVersion {
+		return OffchainMessage{}, fmt.Errorf("unsupported off-chain message version %d", b[0])
+	}
+
+	var m OffchainMessage
+
+	copy(m.ApplicationDomain[:], b[1:33])
+	m.Format = MessageFormat(b[33])
+	signerCount := int(b[34])
+	b = b[35:]
+
+	if len(b) < signerCount*solana.PublicKeyLength+2 {
+		return OffchainMessage{}, fmt.Errorf("invalid off-chain message: too short")
+	}
+
+	m.Signers = make([]solana.PublicKey, signerCount)
+	for i := range m.Signers {
+		m.Signers[i] = solana.PublicKeyFromBytes(b[:solana.PublicKeyLength])
+		b = b[solana.PublicKeyLength:]
+	}
+
+	messageLen := int(binary.LittleEndian.Uint16(b))
+	b = b[2:]
+
+	if len(b) != messageLen {
+		return OffchainMessage{}, fmt.Errorf("invalid off-chain message: length %d, expected %d", len(b), messageLen)
+	}
+
+	m.Message = bytes.Clone(b)
+
+	if err := m.validate(); err != nil {
+		return OffchainMessage{}, err
+	}
+
+	return m, nil
+}
+
+// IsSigner reports whether the public key is one of the signers of the message.
+func (m OffchainMessage) IsSigner(publicKey solana.PublicKey) bool {
+	for _, signer := range m.Signers {
+		if signer.Equals(publicKey) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m OffchainMessage) headerLen() int {
+	// signing domain, version, application domain, format, signer count, signers and message length.
+	return len(offchainSigningDomain) + 1 + 32 + 1 + 1 + len(m.Signers)*solana.PublicKeyLength + 2
+}
+
+func (m OffchainMessage) validate() error {
+	if len(m.Signers) == 0 || len(m.Signers) > 255 {
+		return fmt.Errorf("invalid off-chain message: %d signers, expected 1 to 255", len(m.Signers))
+	}
+
+	if len(m.Message) == 0 {
+		return fmt.Errorf("invalid off-chain message: empty message")
+	}
+
+	if len(m.Message) > 65535 {
+		return fmt.Errorf("invalid off-chain message: message of %d bytes is too long", len(m.Message))
+	}
+
+	fitsLedger := m.headerLen()+len(m.Message) <= offchainLedgerMaxLen
+
+	switch m.Format {
+	case MessageFormatRestrictedASCII:
+		if !isRestrictedASCII(m.Message) {
+			return fmt.Errorf("invalid off-chain message: message is not printable ASCII")
+		}
+	case MessageFormatLimitedUTF8, MessageFormatExtendedUTF8:
+		if !utf8.Valid(m.Message) {
+			return fmt.Errorf("invalid off-chain message: message is not valid UTF-8")
+		}
+	default:
+		return fmt.Errorf("invalid off-chain message: unknown format %d", m.Format)
+	}
+
+	if m.Format != MessageFormatExtendedUTF8 && !fitsLedger {
+		return fmt.Errorf("invalid off-chain message: message is too long for format %d", m.Format)
+	}
+
+	return nil
+}
+
+func isRestrictedASCII(b []byte) bool {
+	for _, c := range b {
+		if c < 0x20 || c > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+// VerifyMessage checks the ed25519 signature of a raw message.
+func VerifyMessage(publicKey solana.PublicKey, message []byte, signature solana.Signature) error {
+	if !publicKey.Verify(message, signature) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// VerifyOffchainMessage parses a serialized off-chain message and checks the public key is one of its signers
+// and the signature is valid.
+func VerifyOffchainMessage(
+	publicKey solana.PublicKey,
+	message []byte,
+	signature solana.Signature,
+) (OffchainMessage, error) {
+	m, err := ParseOffchainMessage(message)
+	if err != nil {
+		return OffchainMessage{}, err
+	}
+
+	if !m.IsSigner(publicKey) {
+		return OffchainMessage{}, fmt.Errorf("%s is not a signer of the message", publicKey)
+	}
+
+	if err := VerifyMessage(publicKey, message, signature); err != nil {
+		return OffchainMessage{}, err
+	}
+
+	return m, nil
+}
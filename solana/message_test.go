@@ -0,0 +1,145 @@
+package solana_test
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/require"
+
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+)
+
+func testWallet(t *testing.T) jupSolana.Wallet {
+	wallet, err := jupSolana.NewWalletFromPrivateKeyBase58(testPrivateKey)
+	require.NoError(t, err)
+
+	return wallet
+}
+
+func TestWallet_SignMessage(t *testing.T) {
+	wallet := testWallet(t)
+	message := []byte("hello jupiter")
+
+	signature, err := wallet.SignMessage(message)
+	require.NoError(t, err)
+
+	require.NoError(t, jupSolana.VerifyMessage(wallet.PublicKey(), message, signature))
+	require.ErrorIs(t, jupSolana.VerifyMessage(wallet.PublicKey(), []byte("hello"), signature), jupSolana.ErrInvalidSignature)
+	require.ErrorIs(t,
+		jupSolana.VerifyMessage(solana.NewWallet().PublicKey(), message, signature),
+		jupSolana.ErrInvalidSignature,
+	)
+}
+
+func TestOffchainMessage(t *testing.T) {
+	wallet := testWallet(t)
+	domain := sha256.Sum256([]byte("jup.ag"))
+
+	t.Run("serialization", func(t *testing.T) {
+		m, err := jupSolana.NewOffchainMessage(domain, []byte("Hello, world!"), wallet.PublicKey())
+		require.NoError(t, err)
+		require.Equal(t, jupSolana.MessageFormatRestrictedASCII, m.Format)
+
+		b, err := m.MarshalBinary()
+		require.NoError(t, err)
+
+		require.Equal(t, "\xffsolana offchain", string(b[:16]))
+		require.Equal(t, byte(0), b[16])      // version
+		require.Equal(t, domain[:], b[17:49]) // application domain
+		require.Equal(t, byte(0), b[49])      // format
+		require.Equal(t, byte(1), b[50])      // signer count
+		require.Equal(t, wallet.PublicKey().Bytes(), b[51:83])
+		require.Equal(t, uint16(13), binary.LittleEndian.Uint16(b[83:85]))
+		require.Equal(t, "Hello, world!", string(b[85:]))
+
+		parsed, err := jupSolana.ParseOffchainMessage(b)
+		require.NoError(t, err)
+		require.Equal(t, m, parsed)
+	})
+
+	t.Run("format", func(t *testing.T) {
+		utf8Message, err := jupSolana.NewOffchainMessage(domain, []byte("Grüße"), wallet.PublicKey())
+		require.NoError(t, err)
+		require.Equal(t, jupSolana.MessageFormatLimitedUTF8, utf8Message.Format)
+
+		newLine, err := jupSolana.NewOffchainMessage(domain, []byte("line\nline"), wallet.PublicKey())
+		require.NoError(t, err)
+		require.Equal(t, jupSolana.MessageFormatLimitedUTF8, newLine.Format)
+
+		long, err := jupSolana.NewOffchainMessage(domain, []byte(strings.Repeat("a", 2000)), wallet.PublicKey())
+		require.NoError(t, err)
+		require.Equal(t, jupSolana.MessageFormatExtendedUTF8, long.Format)
+
+		long.Format = jupSolana.MessageFormatRestrictedASCII
+		_, err = long.MarshalBinary()
+		require.EqualError(t, err, "invalid off-chain message: message is too long for format 0")
+
+		utf8Message.Format = jupSolana.MessageFormatRestrictedASCII
+		_, err = utf8Message.MarshalBinary()
+		require.EqualError(t, err, "invalid off-chain message: message is not printable ASCII")
+	})
+
+	t.Run("invalid messages", func(t *testing.T) {
+		_, err := jupSolana.NewOffchainMessage(domain, []byte("no signer"))
+		require.EqualError(t, err, "invalid off-chain message: 0 signers, expected 1 to 255")
+
+		_, err = jupSolana.NewOffchainMessage(domain, nil, wallet.PublicKey())
+		require.EqualError(t, err, "invalid off-chain message: empty message")
+
+		_, err = jupSolana.NewOffchainMessage(domain, []byte{0xff, 0xfe}, wallet.PublicKey())
+		require.EqualError(t, err, "invalid off-chain message: message is not valid UTF-8")
+
+		_, err = jupSolana.ParseOffchainMessage([]byte("Hello, world!"))
+		require.EqualError(t, err, "invalid off-chain message: missing signing domain")
+
+		m, err := jupSolana.NewOffchainMessage(domain, []byte("Hello, world!"), wallet.PublicKey())
+		require.NoError(t, err)
+
+		b, err := m.MarshalBinary()
+		require.NoError(t, err)
+
+		_, err = jupSolana.ParseOffchainMessage(b[:len(b)-1])
+		require.EqualError(t, err, "invalid off-chain message: length 12, expected 13")
+
+		b[16] = 1
+		_, err = jupSolana.ParseOffchainMessage(b)
+		require.EqualError(t, err, "unsupported off-chain message version 1")
+	})
+
+	t.Run("sign and verify", func(t *testing.T) {
+		other := solana.NewWallet().PublicKey()
+
+		m, err := jupSolana.NewOffchainMessage(domain, []byte("Authorize session 42"), other, wallet.PublicKey())
+		require.NoError(t, err)
+
+		message, signature, err := wallet.SignOffchainMessage(m)
+		require.NoError(t, err)
+
+		verified, err := jupSolana.VerifyOffchainMessage(wallet.PublicKey(), message, signature)
+		require.NoError(t, err)
+		require.Equal(t, "Authorize session 42", string(verified.Message))
+
+		_, err = jupSolana.VerifyOffchainMessage(other, message, signature)
+		require.ErrorIs(t, err, jupSolana.ErrInvalidSignature)
+
+		_, err = jupSolana.VerifyOffchainMessage(solana.NewWallet().PublicKey(), message, signature)
+		require.ErrorContains(t, err, "is not a signer of the message")
+
+		// The envelope is signed, not the text.
+		require.ErrorIs(t,
+			jupSolana.VerifyMessage(wallet.PublicKey(), []byte("Authorize session 42"), signature),
+			jupSolana.ErrInvalidSignature,
+		)
+	})
+
+	t.Run("wallet is not a signer", func(t *testing.T) {
+		m, err := jupSolana.NewOffchainMessage(domain, []byte("hello"), solana.NewWallet().PublicKey())
+		require.NoError(t, err)
+
+		_, _, err = wallet.SignOffchainMessage(m)
+		require.ErrorContains(t, err, "is not a signer of the message")
+	})
+}
@@ -3,6 +3,7 @@ package solana
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc/ws"
@@ -61,7 +62,7 @@ func (s subscriber) Pull(
 		}
 
 		if res.Value.Err != nil {
-			resp.InstructionErr = fmt.Errorf("transaction confirmed with error: %v", res.Value.Err)
+			resp.InstructionErr = fmt.Errorf("transaction confirmed with error: %w", &InstructionError{Err: res.Value.Err})
 		}
 
 		return resp, nil
@@ -77,12 +78,24 @@ type MonitorResponse struct {
 	InstructionErr error
 }
 
+const (
+	defaultNoncePollInterval       = 2 * time.Second
+	defaultBlockHeightPollInterval = 2 * time.Second
+)
+
 type monitor struct {
-	sub subscriberService
+	sub                     subscriberService
+	nonces                  NonceSource
+	noncePollInterval       time.Duration
+	blockHeights            BlockHeightSource
+	blockHeightPollInterval time.Duration
 }
 
 func NewMonitor(wsEndpoint string, opts ...MonitorOption) (Monitor, error) {
-	m := &monitor{}
+	m := &monitor{
+		noncePollInterval:       defaultNoncePollInterval,
+		blockHeightPollInterval: defaultBlockHeightPollInterval,
+	}
 
 	for _, opt := range opts {
 		if err := opt(m); err != nil {
@@ -117,3 +130,137 @@ func (m monitor) WaitForCommitmentStatus(
 		InstructionErr: res.InstructionErr,
 	}, nil
 }
+
+// WaitForTransaction waits for a transaction to reach a specific commitment status. It fails with
+// ErrBlockhashExpired once the block height exceeds the last valid block height of the transaction's blockhash
+// and the transaction was not processed, as it can no longer land. The monitor needs a BlockHeightSource.
+func (m monitor) WaitForTransaction(
+	ctx context.Context,
+	txID TxID,
+	status CommitmentStatus,
+	lastValidBlockHeight uint64,
+) (MonitorResponse, error) {
+	if m.blockHeights == nil {
+		return MonitorResponse{}, fmt.Errorf("block height source is required: use WithBlockHeightSource")
+	}
+
+	expired := func(ctx context.Context) (bool, error) {
+		return m.blockhashExpired(ctx, txID, lastValidBlockHeight)
+	}
+
+	return m.waitUnless(ctx, txID, status, m.blockHeightPollInterval, expired, ErrBlockhashExpired)
+}
+
+// WaitForNonceTransaction waits for a durable nonce transaction to reach a specific commitment status. Such a
+// transaction does not expire with block height: it fails with ErrNonceAdvanced once its nonce was used by another
+// transaction. The nonce is the one the transaction was signed with. The monitor needs a NonceSource.
+func (m monitor) WaitForNonceTransaction(
+	ctx context.Context,
+	txID TxID,
+	status CommitmentStatus,
+	nonce NonceAccount,
+) (MonitorResponse, error) {
+	if m.nonces == nil {
+		return MonitorResponse{}, fmt.Errorf("nonce source is required: use WithNonceSource")
+	}
+
+	advanced := func(ctx context.Context) (bool, error) {
+		return m.nonceAdvanced(ctx, txID, nonce)
+	}
+
+	return m.waitUnless(ctx, txID, status, m.noncePollInterval, advanced, ErrNonceAdvanced)
+}
+
+// waitUnless waits for a transaction to reach a specific commitment status, checking at every interval whether it
+// can no longer land, in which case it fails with errCannotLand. A failed check, e.g. an RPC hiccup, is retried at
+// the next interval: only the end of the context or of the subscription ends the wait.
+func (m monitor) waitUnless(
+	ctx context.Context,
+	txID TxID,
+	status CommitmentStatus,
+	interval time.Duration,
+	cannotLand func(context.Context) (bool, error),
+	errCannotLand error,
+) (MonitorResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type pullResult struct {
+		res SubResponse
+		err error
+	}
+
+	results := make(chan pullResult, 1)
+
+	go func() {
+		res, err := m.sub.Pull(ctx, txID, status)
+		results <- pullResult{res: res, err: err}
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case r := <-results:
+			if r.err != nil {
+				return MonitorResponse{}, r.err
+			}
+
+			return MonitorResponse{
+				Ok:             true,
+				InstructionErr: r.res.InstructionErr,
+			}, nil
+		case <-ticker.C:
+			stop, err := cannotLand(ctx)
+			if err != nil {
+				continue
+			}
+
+			if stop {
+				return MonitorResponse{}, errCannotLand
+			}
+		}
+	}
+}
+
+// blockhashExpired reports whether the block height exceeds the last valid block height while txID was not
+// processed.
+func (m monitor) blockhashExpired(ctx context.Context, txID TxID, lastValidBlockHeight uint64) (bool, error) {
+	height, err := m.blockHeights.GetBlockHeight(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if height <= lastValidBlockHeight {
+		return false, nil
+	}
+
+	// A processed transaction may still reach the commitment status.
+	processed, err := m.blockHeights.IsProcessed(ctx, txID)
+	if err != nil {
+		return false, err
+	}
+
+	return !processed, nil
+}
+
+// nonceAdvanced reports whether the nonce was used by another transaction than txID.
+func (m monitor) nonceAdvanced(ctx context.Context, txID TxID, nonce NonceAccount) (bool, error) {
+	current, err := m.nonces.GetNonceAccount(ctx, nonce.Address)
+	if err != nil {
+		return false, err
+	}
+
+	if current.Nonce.Equals(nonce.Nonce) {
+		return false, nil
+	}
+
+	// The nonce also advances when the transaction itself lands.
+	processed, err := m.nonces.IsProcessed(ctx, txID)
+	if err != nil {
+		return false, err
+	}
+
+	return !processed, nil
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	solanago "github.com/gagliardetto/solana-go"
 	"github.com/stretchr/testify/require"
 
 	"github.com/ilkamo/jupiter-go/solana"
@@ -81,3 +83,184 @@ func Test_monitor_WaitForCommitmentStatus(t *testing.T) {
 		require.Nil(t, resp.InstructionErr)
 	})
 }
+
+// pendingSubscriberMock never sees the transaction reach the commitment status.
+type pendingSubscriberMock struct{}
+
+func (pendingSubscriberMock) Pull(
+	ctx context.Context,
+	_ solana.TxID,
+	_ solana.CommitmentStatus,
+) (solana.SubResponse, error) {
+	<-ctx.Done()
+	return solana.SubResponse{}, errors.New("context cancelled")
+}
+
+type nonceSourceMock struct {
+	nonce     solana.NonceAccount
+	processed bool
+}
+
+func (n nonceSourceMock) GetNonceAccount(
+	_ context.Context,
+	_ solanago.PublicKey,
+	_ ...solana.ReadOption,
+) (solana.NonceAccount, error) {
+	return n.nonce, nil
+}
+
+func (n nonceSourceMock) IsProcessed(_ context.Context, _ solana.TxID) (bool, error) {
+	return n.processed, nil
+}
+
+func Test_monitor_WaitForNonceTransaction(t *testing.T) {
+	signed := solana.NonceAccount{Address: solanago.NewWallet().PublicKey(), Nonce: solanago.Hash{1}}
+	advanced := solana.NonceAccount{Address: signed.Address, Nonce: solanago.Hash{2}}
+
+	newMonitor := func(t *testing.T, sub interface {
+		Pull(context.Context, solana.TxID, solana.CommitmentStatus) (solana.SubResponse, error)
+	}, nonces solana.NonceSource) solana.Monitor {
+		opts := []solana.MonitorOption{
+			solana.WithMonitorSubscriber(sub),
+			solana.WithNoncePollInterval(time.Millisecond),
+		}
+
+		if nonces != nil {
+			opts = append(opts, solana.WithNonceSource(nonces))
+		}
+
+		m, err := solana.NewMonitor("", opts...)
+		require.NoError(t, err)
+
+		return m
+	}
+
+	t.Run("nonce source is required", func(t *testing.T) {
+		m := newMonitor(t, subscriberMock{}, nil)
+
+		_, err := m.WaitForNonceTransaction(context.Background(), "txID", solana.CommitmentConfirmed, signed)
+		require.EqualError(t, err, "nonce source is required: use WithNonceSource")
+	})
+
+	t.Run("invalid poll interval", func(t *testing.T) {
+		_, err := solana.NewMonitor("", solana.WithNoncePollInterval(0))
+		require.EqualError(t, err, "could not apply option: nonce poll interval must be positive")
+	})
+
+	t.Run("confirmed", func(t *testing.T) {
+		m := newMonitor(t, subscriberMock{}, nonceSourceMock{nonce: signed})
+
+		resp, err := m.WaitForNonceTransaction(context.Background(), "txID", solana.CommitmentConfirmed, signed)
+		require.NoError(t, err)
+		require.True(t, resp.Ok)
+	})
+
+	t.Run("nonce advanced by another transaction", func(t *testing.T) {
+		m := newMonitor(t, pendingSubscriberMock{}, nonceSourceMock{nonce: advanced})
+
+		_, err := m.WaitForNonceTransaction(context.Background(), "txID", solana.CommitmentConfirmed, signed)
+		require.ErrorIs(t, err, solana.ErrNonceAdvanced)
+	})
+
+	t.Run("nonce advanced by the transaction itself", func(t *testing.T) {
+		m := newMonitor(t, pendingSubscriberMock{}, nonceSourceMock{nonce: advanced, processed: true})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		// The monitor keeps waiting for the commitment status.
+		_, err := m.WaitForNonceTransaction(ctx, "txID", solana.CommitmentFinalized, signed)
+		require.EqualError(t, err, "context cancelled")
+	})
+}
+
+type blockHeightSourceMock struct {
+	height    uint64
+	processed bool
+	// errs fail the calls to GetBlockHeight in order.
+	errs *[]error
+}
+
+func (b blockHeightSourceMock) GetBlockHeight(_ context.Context, _ ...solana.ReadOption) (uint64, error) {
+	if b.errs != nil && len(*b.errs) > 0 {
+		err := (*b.errs)[0]
+		*b.errs = (*b.errs)[1:]
+
+		return 0, err
+	}
+
+	return b.height, nil
+}
+
+func (b blockHeightSourceMock) IsProcessed(_ context.Context, _ solana.TxID) (bool, error) {
+	return b.processed, nil
+}
+
+func Test_monitor_WaitForTransaction(t *testing.T) {
+	const lastValidBlockHeight = 100
+
+	newMonitor := func(t *testing.T, sub interface {
+		Pull(context.Context, solana.TxID, solana.CommitmentStatus) (solana.SubResponse, error)
+	}, blockHeights solana.BlockHeightSource) solana.Monitor {
+		opts := []solana.MonitorOption{
+			solana.WithMonitorSubscriber(sub),
+			solana.WithBlockHeightPollInterval(time.Millisecond),
+		}
+
+		if blockHeights != nil {
+			opts = append(opts, solana.WithBlockHeightSource(blockHeights))
+		}
+
+		m, err := solana.NewMonitor("", opts...)
+		require.NoError(t, err)
+
+		return m
+	}
+
+	t.Run("block height source is required", func(t *testing.T) {
+		m := newMonitor(t, subscriberMock{}, nil)
+
+		_, err := m.WaitForTransaction(context.Background(), "txID", solana.CommitmentConfirmed, lastValidBlockHeight)
+		require.EqualError(t, err, "block height source is required: use WithBlockHeightSource")
+	})
+
+	t.Run("invalid poll interval", func(t *testing.T) {
+		_, err := solana.NewMonitor("", solana.WithBlockHeightPollInterval(0))
+		require.EqualError(t, err, "could not apply option: block height poll interval must be positive")
+	})
+
+	t.Run("confirmed", func(t *testing.T) {
+		m := newMonitor(t, subscriberMock{}, blockHeightSourceMock{height: 90})
+
+		resp, err := m.WaitForTransaction(context.Background(), "txID", solana.CommitmentConfirmed, lastValidBlockHeight)
+		require.NoError(t, err)
+		require.True(t, resp.Ok)
+	})
+
+	t.Run("blockhash expired", func(t *testing.T) {
+		m := newMonitor(t, pendingSubscriberMock{}, blockHeightSourceMock{height: 101})
+
+		_, err := m.WaitForTransaction(context.Background(), "txID", solana.CommitmentConfirmed, lastValidBlockHeight)
+		require.ErrorIs(t, err, solana.ErrBlockhashExpired)
+	})
+
+	t.Run("retry failed block height checks", func(t *testing.T) {
+		errs := []error{errors.New("mocked error"), errors.New("mocked error")}
+		m := newMonitor(t, pendingSubscriberMock{}, blockHeightSourceMock{height: 101, errs: &errs})
+
+		_, err := m.WaitForTransaction(context.Background(), "txID", solana.CommitmentConfirmed, lastValidBlockHeight)
+		require.ErrorIs(t, err, solana.ErrBlockhashExpired)
+		require.Empty(t, errs)
+	})
+
+	t.Run("processed before the blockhash expired", func(t *testing.T) {
+		m := newMonitor(t, pendingSubscriberMock{}, blockHeightSourceMock{height: 101, processed: true})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		// The monitor keeps waiting for the commitment status.
+		_, err := m.WaitForTransaction(ctx, "txID", solana.CommitmentFinalized, lastValidBlockHeight)
+		require.EqualError(t, err, "context cancelled")
+	})
+}
@@ -0,0 +1,398 @@
+package solana
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// nonceAccountSize is the size of the data of a durable nonce account.
+const nonceAccountSize = 80
+
+// nonceInitialized is the state of a nonce account whose nonce can be used.
+const nonceInitialized = 1
+
+// ErrNonceAdvanced is returned when the nonce of a durable nonce transaction was used by another transaction,
+// so that the transaction can never land.
+var ErrNonceAdvanced = errors.New("nonce has been advanced")
+
+// NonceAccount is the state of a durable nonce account.
+type NonceAccount struct {
+	Address solana.PublicKey
+	// Authority is the key that must sign to advance the nonce.
+	Authority solana.PublicKey
+	// Nonce replaces the recent blockhash of the transactions using the account.
+	Nonce                solana.Hash
+	LamportsPerSignature uint64
+}
+
+// NewNonceClient creates a client that manages durable nonce accounts whose authority is the wallet.
+// Durable nonce transactions do not expire with their blockhash, so they can wait for offline or multisig approval.
+func NewNonceClient(
+	wallet Wallet,
+	rpcEndpoint string,
+	opts ...ClientOption,
+) (NonceClient, error) {
+	if wallet.Wallet == nil {
+		return nil, fmt.Errorf("wallet is required")
+	}
+
+	return newClient(wallet, rpcEndpoint, opts...)
+}
+
+// CreateNonceAccount creates and initializes a nonce account funded by the wallet, with the wallet as authority.
+// It returns the address of the account and the ID of the creating transaction.
+func (e client) CreateNonceAccount(ctx context.Context) (solana.PublicKey, TxID, error) {
+	if e.wallet.Wallet == nil {
+		return solana.PublicKey{}, "", ErrWatchOnly
+	}
+
+	lamports, err := e.clientRPC.GetMinimumBalanceForRentExemption(ctx, nonceAccountSize, rpc.CommitmentConfirmed)
+	if err != nil {
+		return solana.PublicKey{}, "", fmt.Errorf("could not get rent exemption: %w", err)
+	}
+
+	nonceWallet := solana.NewWallet()
+	payer := e.wallet.PublicKey()
+
+	txID, err := e.sendInstructions(ctx, []solana.Instruction{
+		system.NewCreateAccountInstruction(
+			lamports,
+			nonceAccountSize,
+			solana.SystemProgramID,
+			payer,
+			nonceWallet.PublicKey(),
+		).Build(),
+		system.NewInitializeNonceAccountInstruction(
+			payer,
+			nonceWallet.PublicKey(),
+			solana.SysVarRecentBlockHashesPubkey,
+			solana.SysVarRentPubkey,
+		).Build(),
+	}, nonceWallet.PrivateKey)
+	if err != nil {
+		return solana.PublicKey{}, "", err
+	}
+
+	return nonceWallet.PublicKey(), txID, nil
+}
+
+// AdvanceNonceAccount advances the nonce of the account, which invalidates the transactions signed with its
+// current nonce.
+func (e client) AdvanceNonceAccount(ctx context.Context, nonceAccount solana.PublicKey) (TxID, error) {
+	if e.wallet.Wallet == nil {
+		return "", ErrWatchOnly
+	}
+
+	return e.sendInstructions(ctx, []solana.Instruction{
+		system.NewAdvanceNonceAccountInstruction(
+			nonceAccount,
+			solana.SysVarRecentBlockHashesPubkey,
+			e.wallet.PublicKey(),
+		).Build(),
+	})
+}
+
+// GetNonceAccount returns the state of a nonce account at the commitment of the client, confirmed by default.
+func (e client) GetNonceAccount(
+	ctx context.Context,
+	nonceAccount solana.PublicKey,
+	opts ...ReadOption,
+) (NonceAccount, error) {
+	commitment, err := e.readCommitment(CommitmentConfirmed, opts)
+	if err != nil {
+		return NonceAccount{}, err
+	}
+
+	resp, err := e.clientRPC.GetAccountInfoWithOpts(ctx, nonceAccount, &rpc.GetAccountInfoOpts{
+		Encoding:   solana.EncodingBase64,
+		Commitment: commitment,
+	})
+	if err != nil {
+		return NonceAccount{}, fmt.Errorf("could not get nonce account: %w", err)
+	}
+
+	if resp == nil || resp.Value == nil {
+		return NonceAccount{}, fmt.Errorf("could not get nonce account: account %s not found", nonceAccount)
+	}
+
+	var data []byte
+	if resp.Value.Data != nil {
+		data = resp.Value.Data.GetBinary()
+	}
+
+	if !resp.Value.Owner.Equals(solana.SystemProgramID) || len(data) != nonceAccountSize {
+		return NonceAccount{}, fmt.Errorf("account %s is not a nonce account", nonceAccount)
+	}
+
+	var state system.NonceAccount
+	if err := state.UnmarshalWithDecoder(bin.NewBinDecoder(data)); err != nil {
+		return NonceAccount{}, fmt.Errorf("could not decode nonce account: %w", err)
+	}
+
+	if state.State != nonceInitialized {
+		return NonceAccount{}, fmt.Errorf("nonce account %s is not initialized", nonceAccount)
+	}
+
+	return NonceAccount{
+		Address:              nonceAccount,
+		Authority:            state.AuthorizedPubkey,
+		Nonce:                solana.Hash(state.Nonce),
+		LamportsPerSignature: state.FeeCalculator.LamportsPerSignature,
+	}, nil
+}
+
+// IsProcessed reports whether the cluster has processed the transaction, at any commitment and even with an error.
+func (e client) IsProcessed(ctx context.Context, tx TxID) (bool, error) {
+	sig, err := solana.SignatureFromBase58(string(tx))
+	if err != nil {
+		return false, fmt.Errorf("could not convert signature from base58: %w", err)
+	}
+
+	status, err := e.clientRPC.GetSignatureStatuses(ctx, false, sig)
+	if err != nil {
+		return false, fmt.Errorf("could not get signature status: %w", err)
+	}
+
+	return len(status.Value) > 0 && status.Value[0] != nil, nil
+}
+
+// UseDurableNonce rewrites the transaction to use the current nonce of the account instead of a recent blockhash,
+// and returns it in base64 with empty signatures. The transaction no longer expires until the nonce is advanced.
+func (e client) UseDurableNonce(ctx context.Context, txBase64 string, nonceAccount solana.PublicKey) (string, error) {
+	tx, err := NewTransactionFromBase64(txBase64)
+	if err != nil {
+		return "", fmt.Errorf("could not deserialize transaction: %w", err)
+	}
+
+	nonce, err := e.GetNonceAccount(ctx, nonceAccount)
+	if err != nil {
+		return "", err
+	}
+
+	if err := SetDurableNonce(&tx, nonce); err != nil {
+		return "", err
+	}
+
+	txBase64, err = tx.ToBase64()
+	if err != nil {
+		return "", fmt.Errorf("could not serialize transaction: %w", err)
+	}
+
+	return txBase64, nil
+}
+
+// sendInstructions sends a transaction of the instructions paid by the wallet and signed by the extra signers.
+func (e client) sendInstructions(
+	ctx context.Context,
+	instructions []solana.Instruction,
+	signers ...solana.PrivateKey,
+) (TxID, error) {
+	blockhash, err := e.latestBlockhash(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := solana.NewTransaction(
+		instructions,
+		blockhash.Hash,
+		solana.TransactionPayer(e.wallet.PublicKey()),
+	)
+	if err != nil {
+		return "", fmt.Errorf("could not build transaction: %w", err)
+	}
+
+	signers = append(signers, e.wallet.PrivateKey)
+
+	if _, err := tx.Sign(func(key solana.PublicKey) *solana.PrivateKey {
+		for i := range signers {
+			if signers[i].PublicKey().Equals(key) {
+				return &signers[i]
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return "", fmt.Errorf("could not sign transaction: %w", err)
+	}
+
+	opts := rpc.TransactionOpts{
+		MaxRetries:          &e.maxRetries,
+		PreflightCommitment: rpc.CommitmentProcessed,
+	}
+
+	if blockhash.Slot > 0 {
+		opts.MinContextSlot = &blockhash.Slot
+	}
+
+	sig, err := e.clientRPC.SendTransactionWithOpts(ctx, tx, opts)
+	if err != nil {
+		return "", fmt.Errorf("could not send transaction: %w", err)
+	}
+
+	return TxID(sig.String()), nil
+}
+
+// DurableNonce returns the nonce account of a durable nonce transaction, whose first instruction advances it.
+func DurableNonce(tx solana.Transaction) (solana.PublicKey, bool) {
+	msg := tx.Message
+	if len(msg.Instructions) == 0 {
+		return solana.PublicKey{}, false
+	}
+
+	ix := msg.Instructions[0]
+
+	if int(ix.ProgramIDIndex) >= len(msg.AccountKeys) {
+		return solana.PublicKey{}, false
+	}
+
+	if !msg.AccountKeys[ix.ProgramIDIndex].Equals(solana.SystemProgramID) {
+		return solana.PublicKey{}, false
+	}
+
+	if len(ix.Data) < 4 || binary.LittleEndian.Uint32(ix.Data) != system.Instruction_AdvanceNonceAccount {
+		return solana.PublicKey{}, false
+	}
+
+	if len(ix.Accounts) < 3 || int(ix.Accounts[0]) >= len(msg.AccountKeys) {
+		return solana.PublicKey{}, false
+	}
+
+	return msg.AccountKeys[ix.Accounts[0]], true
+}
+
+type accountFlags struct {
+	key      solana.PublicKey
+	signer   bool
+	writable bool
+}
+
+// SetDurableNonce rewrites the transaction to use the nonce instead of a recent blockhash: AdvanceNonceAccount
+// becomes its first instruction and the nonce its blockhash. The accounts of the instruction are added to the static
+// keys of the message, so the nonce authority becomes a signer if it was not one. The signatures are cleared, as the
+// message changes. The address lookup tables of the message must not be resolved.
+func SetDurableNonce(tx *solana.Transaction, nonce NonceAccount) error {
+	if _, ok := DurableNonce(*tx); ok {
+		return fmt.Errorf("transaction already uses a durable nonce")
+	}
+
+	if tx.Message.IsResolved() {
+		return fmt.Errorf("transaction address lookups must not be resolved")
+	}
+
+	msg := &tx.Message
+	header := msg.Header
+	numStatic := len(msg.AccountKeys)
+
+	accounts := make([]accountFlags, numStatic)
+	for i, key := range msg.AccountKeys {
+		signer := i < int(header.NumRequiredSignatures)
+
+		writable := i < numStatic-int(header.NumReadonlyUnsignedAccounts)
+		if signer {
+			writable = i < int(header.NumRequiredSignatures-header.NumReadonlySignedAccounts)
+		}
+
+		accounts[i] = accountFlags{key: key, signer: signer, writable: writable}
+	}
+
+	add := func(key solana.PublicKey, signer, writable bool) {
+		for i := range accounts {
+			if accounts[i].key.Equals(key) {
+				accounts[i].signer = accounts[i].signer || signer
+				accounts[i].writable = accounts[i].writable || writable
+
+				return
+			}
+		}
+
+		accounts = append(accounts, accountFlags{key: key, signer: signer, writable: writable})
+	}
+
+	add(nonce.Address, false, true)
+	add(solana.SysVarRecentBlockHashesPubkey, false, false)
+	add(nonce.Authority, true, false)
+	add(solana.SystemProgramID, false, false)
+
+	if len(accounts)+msg.NumLookups() > 256 {
+		return fmt.Errorf("transaction has too many accounts to use a durable nonce")
+	}
+
+	// The static keys are ordered by writable signers, readonly signers, writable and readonly non-signers.
+	var keys solana.PublicKeySlice
+
+	newIndex := make(map[solana.PublicKey]uint16, len(accounts))
+	header = solana.MessageHeader{}
+
+	for _, group := range []struct{ signer, writable bool }{{true, true}, {true, false}, {false, true}, {false, false}} {
+		for _, a := range accounts {
+			if a.signer != group.signer || a.writable != group.writable {
+				continue
+			}
+
+			newIndex[a.key] = uint16(len(keys))
+			keys = append(keys, a.key)
+
+			switch {
+			case a.signer && a.writable:
+				header.NumRequiredSignatures++
+			case a.signer:
+				header.NumRequiredSignatures++
+				header.NumReadonlySignedAccounts++
+			case !a.writable:
+				header.NumReadonlyUnsignedAccounts++
+			}
+		}
+	}
+
+	remap := func(index uint16) uint16 {
+		if int(index) < numStatic {
+			return newIndex[msg.AccountKeys[index]]
+		}
+
+		// Lookup accounts follow the static keys.
+		return index - uint16(numStatic) + uint16(len(keys))
+	}
+
+	instructions := make([]solana.CompiledInstruction, 0, len(msg.Instructions)+1)
+
+	advanceData := make([]byte, 4)
+	binary.LittleEndian.PutUint32(advanceData, system.Instruction_AdvanceNonceAccount)
+
+	instructions = append(instructions, solana.CompiledInstruction{
+		ProgramIDIndex: newIndex[solana.SystemProgramID],
+		Accounts: []uint16{
+			newIndex[nonce.Address],
+			newIndex[solana.SysVarRecentBlockHashesPubkey],
+			newIndex[nonce.Authority],
+		},
+		Data: advanceData,
+	})
+
+	for _, ix := range msg.Instructions {
+		accounts := make([]uint16, len(ix.Accounts))
+		for i, index := range ix.Accounts {
+			accounts[i] = remap(index)
+		}
+
+		instructions = append(instructions, solana.CompiledInstruction{
+			ProgramIDIndex: remap(ix.ProgramIDIndex),
+			Accounts:       accounts,
+			Data:           ix.Data,
+		})
+	}
+
+	msg.AccountKeys = keys
+	msg.Header = header
+	msg.Instructions = instructions
+	msg.RecentBlockhash = nonce.Nonce
+	tx.Signatures = make([]solana.Signature, header.NumRequiredSignatures)
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package solana_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/stretchr/testify/require"
+
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+)
+
+var (
+	testNonce        = solana.MustHashFromBase58("4vJ9JU1bJJE96FWSJKvHsmmFADCg4gpZQff4P3bkLKi")
+	testNonceAccount = solana.MustPublicKeyFromBase58("7Ds3B4gGgQTRjCVPwKkKUZHkPhHk9cLhWgqMdjqt5Y9S")
+)
+
+// instructionKeys resolves the program and accounts of every instruction of the transaction.
+func instructionKeys(t *testing.T, tx solana.Transaction) [][]solana.PublicKey {
+	t.Helper()
+
+	var out [][]solana.PublicKey
+
+	for _, ix := range tx.Message.Instructions {
+		program, err := tx.Message.Program(ix.ProgramIDIndex)
+		require.NoError(t, err)
+
+		keys := []solana.PublicKey{program}
+
+		for _, index := range ix.Accounts {
+			key, err := tx.Message.Account(index)
+			require.NoError(t, err)
+
+			keys = append(keys, key)
+		}
+
+		out = append(out, keys)
+	}
+
+	return out
+}
+
+func TestNonceClient(t *testing.T) {
+	wallet := testWallet(t)
+
+	t.Run("wallet is required", func(t *testing.T) {
+		_, err := jupSolana.NewNonceClient(jupSolana.Wallet{}, "http://localhost:8899")
+		require.EqualError(t, err, "wallet is required")
+	})
+
+	c, err := jupSolana.NewNonceClient(
+		wallet,
+		"",
+		jupSolana.WithClientRPC(rpcMock{nonceAuthority: wallet.PublicKey()}),
+	)
+	require.NoError(t, err)
+
+	t.Run("create nonce account", func(t *testing.T) {
+		nonceAccount, txID, err := c.CreateNonceAccount(context.TODO())
+		require.NoError(t, err)
+		require.False(t, nonceAccount.IsZero())
+		require.Equal(t, jupSolana.TxID(testSignature), txID)
+	})
+
+	t.Run("advance nonce account", func(t *testing.T) {
+		txID, err := c.AdvanceNonceAccount(context.TODO(), testNonceAccount)
+		require.NoError(t, err)
+		require.Equal(t, jupSolana.TxID(testSignature), txID)
+	})
+
+	t.Run("get nonce account", func(t *testing.T) {
+		nonce, err := c.GetNonceAccount(context.TODO(), testNonceAccount)
+		require.NoError(t, err)
+		require.Equal(t, jupSolana.NonceAccount{
+			Address:              testNonceAccount,
+			Authority:            wallet.PublicKey(),
+			Nonce:                testNonce,
+			LamportsPerSignature: 5000,
+		}, nonce)
+	})
+
+	t.Run("nonce account not found", func(t *testing.T) {
+		missing, err := jupSolana.NewNonceClient(wallet, "", jupSolana.WithClientRPC(rpcMock{}))
+		require.NoError(t, err)
+
+		_, err = missing.GetNonceAccount(context.TODO(), testNonceAccount)
+		require.ErrorContains(t, err, "could not get nonce account: not found")
+	})
+
+	t.Run("is processed", func(t *testing.T) {
+		processed, err := c.IsProcessed(context.TODO(), jupSolana.TxID(processingSignature))
+		require.NoError(t, err)
+		require.True(t, processed)
+	})
+
+	t.Run("use durable nonce and send without blockhash", func(t *testing.T) {
+		txBase64, err := c.UseDurableNonce(context.TODO(), testTx, testNonceAccount)
+		require.NoError(t, err)
+
+		tx, err := jupSolana.NewTransactionFromBase64(txBase64)
+		require.NoError(t, err)
+		require.Equal(t, testNonce, tx.Message.RecentBlockhash)
+
+		nonceAccount, ok := jupSolana.DurableNonce(tx)
+		require.True(t, ok)
+		require.Equal(t, testNonceAccount, nonceAccount)
+
+		// The blockhash of a durable nonce transaction is not replaced.
+		sender, err := jupSolana.NewClient(
+			wallet,
+			"",
+			jupSolana.WithClientRPC(rpcMock{shouldFailGetLatestBlockhash: true}),
+		)
+		require.NoError(t, err)
+
+		txID, err := sender.SendTransactionOnChain(context.TODO(), txBase64)
+		require.NoError(t, err)
+		require.Equal(t, jupSolana.TxID(testSignature), txID)
+	})
+}
+
+func TestSetDurableNonce(t *testing.T) {
+	payer := solana.NewWallet().PublicKey()
+	recipient := solana.NewWallet().PublicKey()
+	table := solana.NewWallet().PublicKey()
+	authority := solana.NewWallet().PublicKey()
+
+	// The recipient is loaded from an address lookup table, after the static keys.
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{
+			system.NewTransferInstruction(1000, payer, recipient).Build(),
+		},
+		solana.MustHashFromBase58("uiYzZ5PCq6C8BRSLSUGBScrXo62bBFbRFP9EkPcaWN9"),
+		solana.TransactionPayer(payer),
+		solana.TransactionAddressTables(map[solana.PublicKey]solana.PublicKeySlice{table: {recipient}}),
+	)
+	require.NoError(t, err)
+
+	txBase64, err := tx.ToBase64()
+	require.NoError(t, err)
+
+	original, err := jupSolana.NewTransactionFromBase64(txBase64)
+	require.NoError(t, err)
+	require.Len(t, original.Message.AddressTableLookups, 1)
+
+	rewritten, err := jupSolana.NewTransactionFromBase64(txBase64)
+	require.NoError(t, err)
+
+	nonce := jupSolana.NonceAccount{Address: testNonceAccount, Authority: authority, Nonce: testNonce}
+	require.NoError(t, jupSolana.SetDurableNonce(&rewritten, nonce))
+
+	t.Run("advances the nonce first", func(t *testing.T) {
+		require.Equal(t, testNonce, rewritten.Message.RecentBlockhash)
+		require.Len(t, rewritten.Message.Instructions, 2)
+
+		nonceAccount, ok := jupSolana.DurableNonce(rewritten)
+		require.True(t, ok)
+		require.Equal(t, testNonceAccount, nonceAccount)
+	})
+
+	t.Run("authority becomes a readonly signer after the fee payer", func(t *testing.T) {
+		require.Equal(t, uint8(2), rewritten.Message.Header.NumRequiredSignatures)
+		require.Equal(t, uint8(1), rewritten.Message.Header.NumReadonlySignedAccounts)
+		require.Equal(t, payer, rewritten.Message.AccountKeys[0])
+		require.Equal(t, authority, rewritten.Message.AccountKeys[1])
+		require.Len(t, rewritten.Signatures, 2)
+	})
+
+	t.Run("instructions keep their accounts", func(t *testing.T) {
+		tables := map[solana.PublicKey]solana.PublicKeySlice{table: {recipient}}
+
+		require.NoError(t, original.Message.SetAddressTables(tables))
+		require.NoError(t, original.Message.ResolveLookups())
+
+		// A copy is resolved, as resolving the lookups changes the account keys of the message.
+		data, err := rewritten.ToBase64()
+		require.NoError(t, err)
+
+		resolved, err := jupSolana.NewTransactionFromBase64(data)
+		require.NoError(t, err)
+		require.NoError(t, resolved.Message.SetAddressTables(tables))
+		require.NoError(t, resolved.Message.ResolveLookups())
+
+		keys := instructionKeys(t, resolved)
+		require.Equal(t, [][]solana.PublicKey{
+			{solana.SystemProgramID, testNonceAccount, solana.SysVarRecentBlockHashesPubkey, authority},
+		}, keys[:1])
+		require.Equal(t, instructionKeys(t, original), keys[1:])
+
+		writable, err := resolved.Message.IsWritable(testNonceAccount)
+		require.NoError(t, err)
+		require.True(t, writable)
+	})
+
+	t.Run("already a durable nonce transaction", func(t *testing.T) {
+		err := jupSolana.SetDurableNonce(&rewritten, nonce)
+		require.EqualError(t, err, "transaction already uses a durable nonce")
+	})
+}
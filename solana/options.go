@@ -1,5 +1,11 @@
 package solana
 
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
 // ClientOption is a function that allows to specify options for the client.
 type ClientOption func(*client) error
 
@@ -19,6 +25,81 @@ func WithClientRPC(clientRPC rpcService) ClientOption {
 	}
 }
 
+// WithHTTPClient sets the HTTP client used to reach the RPC endpoint, e.g. one with a recording transport.
+// It is ignored when an RPC service is provided with WithClientRPC.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(e *client) error {
+		e.httpClient = httpClient
+		return nil
+	}
+}
+
+// WithTransactionVerifier sets a verifier that every transaction must satisfy before being signed and sent.
+func WithTransactionVerifier(verifier TransactionVerifier) ClientOption {
+	return func(e *client) error {
+		e.verifier = verifier
+		return nil
+	}
+}
+
+// WithCommitment sets the default commitment of the reads of the client, e.g. CommitmentConfirmed for bots acting
+// on confirmed state. Without it, each read keeps its own default, finalized for balances and signatures.
+func WithCommitment(status CommitmentStatus) ClientOption {
+	return func(e *client) error {
+		if _, err := mapToCommitmentType(status); err != nil {
+			return err
+		}
+
+		e.commitment = status
+
+		return nil
+	}
+}
+
+// ReadOption is a function that allows to specify options for a single read of the client.
+type ReadOption func(*readOptions) error
+
+// WithReadCommitment overrides the commitment of the client for a single read.
+func WithReadCommitment(status CommitmentStatus) ReadOption {
+	return func(r *readOptions) error {
+		if _, err := mapToCommitmentType(status); err != nil {
+			return err
+		}
+
+		r.commitment = status
+
+		return nil
+	}
+}
+
+// WithBlockhashStrategy sets the blockhash strategy of the client, BlockhashRefresh by default.
+// BlockhashCached needs WithBlockhashCache.
+func WithBlockhashStrategy(strategy BlockhashStrategy) ClientOption {
+	return func(e *client) error {
+		if strategy < BlockhashRefresh || strategy > BlockhashCached {
+			return fmt.Errorf("unknown blockhash strategy %d", strategy)
+		}
+
+		e.blockhashStrategy = strategy
+
+		return nil
+	}
+}
+
+// WithBlockhashCache sends transactions with the latest blockhash of the cache, which can be shared by clients.
+func WithBlockhashCache(cache BlockhashCache) ClientOption {
+	return func(e *client) error {
+		if cache == nil {
+			return fmt.Errorf("blockhash cache is required")
+		}
+
+		e.blockhashCache = cache
+		e.blockhashStrategy = BlockhashCached
+
+		return nil
+	}
+}
+
 // MonitorOption is a function that allows to specify options for the monitor.
 type MonitorOption func(*monitor) error
 
@@ -29,3 +110,103 @@ func WithMonitorSubscriber(subscriber subscriberService) MonitorOption {
 		return nil
 	}
 }
+
+// WithNonceSource sets the source of nonce accounts used to detect expired durable nonce transactions.
+func WithNonceSource(nonces NonceSource) MonitorOption {
+	return func(m *monitor) error {
+		m.nonces = nonces
+		return nil
+	}
+}
+
+// WithNoncePollInterval sets how often the nonce of a durable nonce transaction is checked while waiting for it.
+func WithNoncePollInterval(interval time.Duration) MonitorOption {
+	return func(m *monitor) error {
+		if interval <= 0 {
+			return fmt.Errorf("nonce poll interval must be positive")
+		}
+
+		m.noncePollInterval = interval
+
+		return nil
+	}
+}
+
+// WithBlockHeightSource sets the source of block heights used to detect transactions whose blockhash expired.
+func WithBlockHeightSource(blockHeights BlockHeightSource) MonitorOption {
+	return func(m *monitor) error {
+		m.blockHeights = blockHeights
+		return nil
+	}
+}
+
+// WithBlockHeightPollInterval sets how often the block height is checked while waiting for a transaction.
+func WithBlockHeightPollInterval(interval time.Duration) MonitorOption {
+	return func(m *monitor) error {
+		if interval <= 0 {
+			return fmt.Errorf("block height poll interval must be positive")
+		}
+
+		m.blockHeightPollInterval = interval
+
+		return nil
+	}
+}
+
+// BlockhashCacheOption is a function that allows to specify options for the blockhash cache.
+type BlockhashCacheOption func(*blockhashCache) error
+
+// WithBlockhashCacheRPC sets the RPC service the blockhash cache fetches blockhashes from.
+func WithBlockhashCacheRPC(rpcService blockhashService) BlockhashCacheOption {
+	return func(c *blockhashCache) error {
+		c.rpcService = rpcService
+		return nil
+	}
+}
+
+// WithBlockhashRefreshInterval sets how often the blockhash cache fetches the latest blockhash.
+func WithBlockhashRefreshInterval(interval time.Duration) BlockhashCacheOption {
+	return func(c *blockhashCache) error {
+		if interval <= 0 {
+			return fmt.Errorf("blockhash refresh interval must be positive")
+		}
+
+		c.refreshInterval = interval
+
+		return nil
+	}
+}
+
+// WithBlockhashMaxAge sets the age after which a cached blockhash is fetched again before being used.
+func WithBlockhashMaxAge(maxAge time.Duration) BlockhashCacheOption {
+	return func(c *blockhashCache) error {
+		if maxAge <= 0 {
+			return fmt.Errorf("blockhash max age must be positive")
+		}
+
+		c.maxAge = maxAge
+
+		return nil
+	}
+}
+
+// DecimalsOption is a function that allows to specify options for the decimals resolver.
+type DecimalsOption func(*decimals) error
+
+// WithDecimalsRPC sets the RPC service used to fetch the decimals of mints.
+func WithDecimalsRPC(rpcService decimalsService) DecimalsOption {
+	return func(d *decimals) error {
+		d.rpcService = rpcService
+		return nil
+	}
+}
+
+// WithKnownDecimals sets the decimals of mints that are resolved without RPC calls.
+func WithKnownDecimals(known map[string]uint8) DecimalsOption {
+	return func(d *decimals) error {
+		for mint, dec := range known {
+			d.cache[mint] = dec
+		}
+		return nil
+	}
+}
@@ -1,6 +1,7 @@
 package solana
 
 import (
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -16,3 +17,12 @@ func TestWithMaxRetries(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, uint(10), c.maxRetries)
 }
+
+func TestWithHTTPClient(t *testing.T) {
+	httpClient := &http.Client{}
+
+	c, err := newClient(Wallet{}, "http://localhost:8899", WithHTTPClient(httpClient))
+	require.NoError(t, err)
+	require.Same(t, httpClient, c.httpClient)
+	require.NotNil(t, c.clientRPC)
+}
@@ -0,0 +1,238 @@
+package solana
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+const (
+	signInHeaderSuffix = " wants you to sign in with your Solana account:"
+	// signInTimeLayout is the ISO 8601 layout of JavaScript's Date.toISOString, used by browser wallets.
+	signInTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+)
+
+// SignInMessage is a Sign-In With Solana (SIWS) message, the text a wallet signs to prove it owns an address
+// to a website. Only Domain and Address are required. SIWS messages are signed as raw messages.
+type SignInMessage struct {
+	Domain         string
+	Address        string
+	Statement      string
+	URI            string
+	Version        string
+	ChainID        string
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime time.Time
+	NotBefore      time.Time
+	RequestID      string
+	Resources      []string
+}
+
+// String returns the text of the message, the bytes to sign.
+func (m SignInMessage) String() string {
+	var b strings.Builder
+
+	b.WriteString(m.Domain + signInHeaderSuffix + "\n")
+	b.WriteString(m.Address)
+
+	if m.Statement != "" {
+		b.WriteString("\n\n" + m.Statement)
+	}
+
+	var fields []string
+
+	addField := func(name, value string) {
+		if value != "" {
+			fields = append(fields, name+": "+value)
+		}
+	}
+
+	addField("URI", m.URI)
+	addField("Version", m.Version)
+	addField("Chain ID", m.ChainID)
+	addField("Nonce", m.Nonce)
+	addField("Issued At", formatSignInTime(m.IssuedAt))
+	addField("Expiration Time", formatSignInTime(m.ExpirationTime))
+	addField("Not Before", formatSignInTime(m.NotBefore))
+	addField("Request ID", m.RequestID)
+
+	if len(m.Resources) > 0 {
+		fields = append(fields, "Resources:")
+		for _, resource := range m.Resources {
+			fields = append(fields, "- "+resource)
+		}
+	}
+
+	if len(fields) > 0 {
+		b.WriteString("\n\n" + strings.Join(fields, "\n"))
+	}
+
+	return b.String()
+}
+
+// ParseSignInMessage parses the text of a SIWS message.
+func ParseSignInMessage(text string) (SignInMessage, error) {
+	lines := strings.Split(text, "\n")
+
+	if len(lines) < 2 || !strings.HasSuffix(lines[0], signInHeaderSuffix) {
+		return SignInMessage{}, fmt.Errorf("invalid sign-in message: missing header")
+	}
+
+	m := SignInMessage{
+		Domain:  strings.TrimSuffix(lines[0], signInHeaderSuffix),
+		Address: lines[1],
+	}
+
+	if m.Domain == "" {
+		return SignInMessage{}, fmt.Errorf("invalid sign-in message: missing domain")
+	}
+
+	if _, err := solana.PublicKeyFromBase58(m.Address); err != nil {
+		return SignInMessage{}, fmt.Errorf("invalid sign-in message: invalid address: %w", err)
+	}
+
+	lines = lines[2:]
+	if len(lines) == 0 {
+		return m, nil
+	}
+
+	if lines[0] != "" {
+		return SignInMessage{}, fmt.Errorf("invalid sign-in message: expected an empty line after the address")
+	}
+
+	lines = lines[1:]
+
+	// The statement is the paragraph before the fields, if its first line is not a field.
+	if len(lines) > 0 && !isSignInField(lines[0]) {
+		end := len(lines)
+		for i, line := range lines {
+			if line == "" {
+				end = i
+				break
+			}
+		}
+
+		m.Statement = strings.Join(lines[:end], "\n")
+		lines = lines[end:]
+
+		if len(lines) > 0 {
+			if len(lines) == 1 || lines[0] != "" {
+				return SignInMessage{}, fmt.Errorf("invalid sign-in message: expected fields after the statement")
+			}
+
+			lines = lines[1:]
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if line == "Resources:" {
+			for _, resource := range lines[i+1:] {
+				r, ok := strings.CutPrefix(resource, "- ")
+				if !ok {
+					return SignInMessage{}, fmt.Errorf("invalid sign-in message: invalid resource %q", resource)
+				}
+
+				m.Resources = append(m.Resources, r)
+			}
+
+			break
+		}
+
+		name, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			return SignInMessage{}, fmt.Errorf("invalid sign-in message: invalid line %q", line)
+		}
+
+		var err error
+
+		switch name {
+		case "URI":
+			m.URI = value
+		case "Version":
+			m.Version = value
+		case "Chain ID":
+			m.ChainID = value
+		case "Nonce":
+			m.Nonce = value
+		case "Issued At":
+			m.IssuedAt, err = time.Parse(time.RFC3339Nano, value)
+		case "Expiration Time":
+			m.ExpirationTime, err = time.Parse(time.RFC3339Nano, value)
+		case "Not Before":
+			m.NotBefore, err = time.Parse(time.RFC3339Nano, value)
+		case "Request ID":
+			m.RequestID = value
+		default:
+			return SignInMessage{}, fmt.Errorf("invalid sign-in message: unknown field %q", name)
+		}
+
+		if err != nil {
+			return SignInMessage{}, fmt.Errorf("invalid sign-in message: invalid %s: %w", name, err)
+		}
+	}
+
+	return m, nil
+}
+
+// Validate checks the message is for the domain and valid at the given time.
+func (m SignInMessage) Validate(domain string, now time.Time) error {
+	if m.Domain != domain {
+		return fmt.Errorf("sign-in message is for %s, not %s", m.Domain, domain)
+	}
+
+	if !m.ExpirationTime.IsZero() && !now.Before(m.ExpirationTime) {
+		return fmt.Errorf("sign-in message expired at %s", m.ExpirationTime.Format(time.RFC3339))
+	}
+
+	if !m.NotBefore.IsZero() && now.Before(m.NotBefore) {
+		return fmt.Errorf("sign-in message is not valid before %s", m.NotBefore.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// VerifySignIn parses a signed SIWS message, checks its signature by its address, and validates it for the domain.
+func VerifySignIn(text string, signature solana.Signature, domain string, now time.Time) (SignInMessage, error) {
+	m, err := ParseSignInMessage(text)
+	if err != nil {
+		return SignInMessage{}, err
+	}
+
+	if err := VerifyMessage(solana.MustPublicKeyFromBase58(m.Address), []byte(text), signature); err != nil {
+		return SignInMessage{}, err
+	}
+
+	if err := m.Validate(domain, now); err != nil {
+		return SignInMessage{}, err
+	}
+
+	return m, nil
+}
+
+func isSignInField(line string) bool {
+	if line == "Resources:" {
+		return true
+	}
+
+	for _, name := range []string{"URI", "Version", "Chain ID", "Nonce", "Issued At", "Expiration Time",
+		"Not Before", "Request ID"} {
+		if strings.HasPrefix(line, name+": ") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func formatSignInTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(signInTimeLayout)
+}
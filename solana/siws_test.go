@@ -0,0 +1,124 @@
+package solana_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+)
+
+const testSignInText = `example.com wants you to sign in with your Solana account:
+BXzmfHxfEMcMj8hDccUNdrwXVNeybyfb2iV2nktE1VnJ
+
+Sign in to trade on example.com.
+
+URI: https://example.com/login
+Version: 1
+Chain ID: mainnet
+Nonce: 32891756
+Issued At: 2025-01-10T12:00:00.000Z
+Expiration Time: 2025-01-10T12:10:00.000Z
+Resources:
+- https://example.com/terms
+- ipfs://bafybeiemxf5abjwjbikoz4mc3a3dla6ual3jsgpdr4cjr3oz3evfyavhwq`
+
+func TestSignInMessage(t *testing.T) {
+	issuedAt := time.Date(2025, time.January, 10, 12, 0, 0, 0, time.UTC)
+
+	m := jupSolana.SignInMessage{
+		Domain:         "example.com",
+		Address:        "BXzmfHxfEMcMj8hDccUNdrwXVNeybyfb2iV2nktE1VnJ",
+		Statement:      "Sign in to trade on example.com.",
+		URI:            "https://example.com/login",
+		Version:        "1",
+		ChainID:        "mainnet",
+		Nonce:          "32891756",
+		IssuedAt:       issuedAt,
+		ExpirationTime: issuedAt.Add(10 * time.Minute),
+		Resources: []string{
+			"https://example.com/terms",
+			"ipfs://bafybeiemxf5abjwjbikoz4mc3a3dla6ual3jsgpdr4cjr3oz3evfyavhwq",
+		},
+	}
+
+	t.Run("build", func(t *testing.T) {
+		require.Equal(t, testSignInText, m.String())
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		parsed, err := jupSolana.ParseSignInMessage(testSignInText)
+		require.NoError(t, err)
+		require.Equal(t, m, parsed)
+	})
+
+	t.Run("minimal message", func(t *testing.T) {
+		minimal := jupSolana.SignInMessage{Domain: "example.com", Address: m.Address}
+
+		text := "example.com wants you to sign in with your Solana account:\n" + m.Address
+		require.Equal(t, text, minimal.String())
+
+		parsed, err := jupSolana.ParseSignInMessage(text)
+		require.NoError(t, err)
+		require.Equal(t, minimal, parsed)
+	})
+
+	t.Run("fields without statement", func(t *testing.T) {
+		noStatement := jupSolana.SignInMessage{Domain: "example.com", Address: m.Address, Nonce: "1"}
+
+		parsed, err := jupSolana.ParseSignInMessage(noStatement.String())
+		require.NoError(t, err)
+		require.Equal(t, noStatement, parsed)
+	})
+
+	t.Run("invalid messages", func(t *testing.T) {
+		_, err := jupSolana.ParseSignInMessage("hello")
+		require.EqualError(t, err, "invalid sign-in message: missing header")
+
+		_, err = jupSolana.ParseSignInMessage("example.com wants you to sign in with your Solana account:\nnot-an-address")
+		require.ErrorContains(t, err, "invalid sign-in message: invalid address")
+
+		_, err = jupSolana.ParseSignInMessage(jupSolana.SignInMessage{
+			Domain: "example.com", Address: m.Address, Nonce: "1",
+		}.String() + "\nColor: blue")
+		require.EqualError(t, err, `invalid sign-in message: unknown field "Color"`)
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		require.NoError(t, m.Validate("example.com", issuedAt.Add(time.Minute)))
+		require.EqualError(t, m.Validate("evil.com", issuedAt), "sign-in message is for example.com, not evil.com")
+		require.EqualError(t, m.Validate("example.com", issuedAt.Add(time.Hour)),
+			"sign-in message expired at 2025-01-10T12:10:00Z")
+
+		notBefore := m
+		notBefore.NotBefore = issuedAt.Add(time.Minute)
+		require.EqualError(t, notBefore.Validate("example.com", issuedAt),
+			"sign-in message is not valid before 2025-01-10T12:01:00Z")
+	})
+}
+
+func TestVerifySignIn(t *testing.T) {
+	wallet := testWallet(t)
+	now := time.Date(2025, time.January, 10, 12, 0, 0, 0, time.UTC)
+
+	text := jupSolana.SignInMessage{
+		Domain:         "example.com",
+		Address:        wallet.PublicKey().String(),
+		Nonce:          "abc",
+		ExpirationTime: now.Add(time.Minute),
+	}.String()
+
+	signature, err := wallet.SignMessage([]byte(text))
+	require.NoError(t, err)
+
+	m, err := jupSolana.VerifySignIn(text, signature, "example.com", now)
+	require.NoError(t, err)
+	require.Equal(t, "abc", m.Nonce)
+
+	_, err = jupSolana.VerifySignIn(text+"\nRequest ID: 1", signature, "example.com", now)
+	require.ErrorIs(t, err, jupSolana.ErrInvalidSignature)
+
+	_, err = jupSolana.VerifySignIn(text, signature, "example.com", now.Add(time.Hour))
+	require.ErrorContains(t, err, "expired")
+}
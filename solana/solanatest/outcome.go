@@ -0,0 +1,76 @@
+package solanatest
+
+import (
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// Outcome is what happens to a transaction sent to the server.
+type Outcome struct {
+	// Status is the commitment the transaction reaches when sent. The transaction stays pending if empty,
+	// until its status is set with Server.SetSignatureStatus.
+	Status rpc.ConfirmationStatusType
+	// Err is the error the transaction lands with, as returned by the RPC, e.g. InstructionError(2, 6001).
+	Err any
+	// Expire drops the transaction and advances the block height past the last valid block height of its blockhash.
+	Expire bool
+	// SendErr makes sendTransaction fail with the error instead.
+	SendErr *RPCError
+}
+
+// Simulation is the result of simulateTransaction. Simulated transactions are not recorded.
+type Simulation struct {
+	Err           any
+	Logs          []string
+	UnitsConsumed uint64
+}
+
+// RPCError is a JSON-RPC error returned by the server.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+var (
+	// Landed finalizes the transaction as soon as it is sent.
+	Landed = Outcome{Status: rpc.ConfirmationStatusFinalized}
+	// Pending accepts the transaction without any status.
+	Pending = Outcome{}
+	// Expired drops the transaction and expires its blockhash.
+	Expired = Outcome{Expire: true}
+)
+
+// Failed finalizes the transaction with the given error as soon as it is sent.
+func Failed(err any) Outcome {
+	return Outcome{Status: rpc.ConfirmationStatusFinalized, Err: err}
+}
+
+// InstructionError returns the error of a transaction whose instruction at index failed with a custom program error.
+func InstructionError(index int, code uint32) any {
+	return map[string]any{
+		"InstructionError": []any{index, map[string]any{"Custom": code}},
+	}
+}
+
+var (
+	errBlockhashNotFound = &RPCError{Code: -32002, Message: "Transaction simulation failed: Blockhash not found"}
+	errMethodNotFound    = &RPCError{Code: -32601, Message: "Method not found"}
+)
+
+func errInvalidParams(msg string) *RPCError {
+	return &RPCError{Code: -32602, Message: "Invalid params: " + msg}
+}
+
+// commitmentLevel orders the confirmation statuses, zero for an unknown one.
+func commitmentLevel(s rpc.ConfirmationStatusType) int {
+	switch s {
+	case rpc.ConfirmationStatusProcessed:
+		return 1
+	case rpc.ConfirmationStatusConfirmed:
+		return 2
+	case rpc.ConfirmationStatusFinalized:
+		return 3
+	}
+
+	return 0
+}
@@ -0,0 +1,374 @@
+package solanatest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+type request struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+type rpcContext struct {
+	Slot uint64 `json:"slot"`
+}
+
+type withContext struct {
+	Context rpcContext `json:"context"`
+	Value   any        `json:"value"`
+}
+
+func (s *Server) serveRPC(w http.ResponseWriter, r *http.Request) {
+	var req request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	result, rpcErr := s.handle(req.Method, req.Params)
+
+	resp := response{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rpcErr}
+	if rpcErr == nil && result == nil {
+		resp.Result = json.RawMessage("null")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (s *Server) handle(method string, params []json.RawMessage) (any, *RPCError) {
+	switch method {
+	case "getLatestBlockhash":
+		return s.getLatestBlockhash()
+	case "getBlockHeight":
+		return s.BlockHeight(), nil
+	case "getSlot":
+		return s.Slot(), nil
+	case "sendTransaction":
+		return s.sendTransaction(params)
+	case "simulateTransaction":
+		return s.simulateTransaction(params)
+	case "getSignatureStatuses":
+		return s.getSignatureStatuses(params)
+	case "getBalance":
+		return s.getBalance(params)
+	case "getAccountInfo":
+		return s.getAccountInfo(params)
+	case "getTokenAccountBalance":
+		return s.getTokenAccountBalance(params)
+	case "getMinimumBalanceForRentExemption":
+		return getMinimumBalanceForRentExemption(params)
+	}
+
+	return nil, errMethodNotFound
+}
+
+func (s *Server) getLatestBlockhash() (any, *RPCError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return withContext{
+		Context: rpcContext{Slot: s.slot},
+		Value: rpc.LatestBlockhashResult{
+			Blockhash:            s.blockhash,
+			LastValidBlockHeight: s.blockhashes[s.blockhash],
+		},
+	}, nil
+}
+
+func transactionParam(params []json.RawMessage) (*solana.Transaction, *RPCError) {
+	var encoded string
+	if err := param(params, 0, &encoded); err != nil {
+		return nil, err
+	}
+
+	txBytes, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errInvalidParams("transaction is not base64")
+	}
+
+	tx, err := solana.TransactionFromDecoder(bin.NewBinDecoder(txBytes))
+	if err != nil || len(tx.Signatures) == 0 {
+		return nil, errInvalidParams("could not deserialize transaction")
+	}
+
+	return tx, nil
+}
+
+func (s *Server) sendTransaction(params []json.RawMessage) (any, *RPCError) {
+	tx, rpcErr := transactionParam(params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	s.mu.Lock()
+	expired, onSend := s.expired(tx.Message.RecentBlockhash), s.onSend
+	s.mu.Unlock()
+
+	if expired {
+		return nil, errBlockhashNotFound
+	}
+
+	// The callback runs without the lock, so that it can call the server.
+	outcome := onSend(*tx)
+	if outcome.SendErr != nil {
+		return nil, outcome.SendErr
+	}
+
+	s.mu.Lock()
+
+	sig := tx.Signatures[0]
+	s.transactions = append(s.transactions, *tx)
+
+	var notifications notifications
+
+	switch {
+	case outcome.Expire:
+		lastValid, ok := s.blockhashes[tx.Message.RecentBlockhash]
+		if !ok {
+			lastValid = s.blockHeight + BlockhashValidity
+		}
+
+		if s.blockHeight <= lastValid {
+			s.advanceBlocks(lastValid - s.blockHeight + 1)
+		}
+	case outcome.Status != "":
+		s.statuses[sig] = signatureStatus{slot: s.slot, status: outcome.Status, err: outcome.Err}
+		notifications = s.takeNotifications(sig)
+	}
+
+	s.mu.Unlock()
+
+	notifications.send()
+
+	return sig.String(), nil
+}
+
+type simulationResult struct {
+	Err           any      `json:"err"`
+	Logs          []string `json:"logs"`
+	Accounts      []any    `json:"accounts"`
+	UnitsConsumed uint64   `json:"unitsConsumed"`
+}
+
+func (s *Server) simulateTransaction(params []json.RawMessage) (any, *RPCError) {
+	if _, rpcErr := transactionParam(params); rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return withContext{
+		Context: rpcContext{Slot: s.slot},
+		Value: simulationResult{
+			Err:           s.simulation.Err,
+			Logs:          s.simulation.Logs,
+			UnitsConsumed: s.simulation.UnitsConsumed,
+		},
+	}, nil
+}
+
+type statusResult struct {
+	Slot               uint64                     `json:"slot"`
+	Confirmations      *uint64                    `json:"confirmations"`
+	Err                any                        `json:"err"`
+	ConfirmationStatus rpc.ConfirmationStatusType `json:"confirmationStatus"`
+	Status             map[string]any             `json:"status"`
+}
+
+func (s *Server) getSignatureStatuses(params []json.RawMessage) (any, *RPCError) {
+	var sigs []string
+	if err := param(params, 0, &sigs); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values := make([]*statusResult, 0, len(sigs))
+
+	for _, sigStr := range sigs {
+		sig, err := solana.SignatureFromBase58(sigStr)
+		if err != nil {
+			return nil, errInvalidParams(fmt.Sprintf("invalid signature %s", sigStr))
+		}
+
+		st, ok := s.statuses[sig]
+		if !ok {
+			values = append(values, nil)
+			continue
+		}
+
+		res := &statusResult{
+			Slot:               st.slot,
+			Err:                st.err,
+			ConfirmationStatus: st.status,
+			Status:             map[string]any{"Ok": nil},
+		}
+
+		if st.err != nil {
+			res.Status = map[string]any{"Err": st.err}
+		}
+
+		if st.status != rpc.ConfirmationStatusFinalized {
+			confirmations := s.slot - st.slot
+			res.Confirmations = &confirmations
+		}
+
+		values = append(values, res)
+	}
+
+	return withContext{Context: rpcContext{Slot: s.slot}, Value: values}, nil
+}
+
+func (s *Server) getBalance(params []json.RawMessage) (any, *RPCError) {
+	pk, rpcErr := publicKeyParam(params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return withContext{Context: rpcContext{Slot: s.slot}, Value: s.accounts[pk].Lamports}, nil
+}
+
+type accountResult struct {
+	Data       [2]string `json:"data"`
+	Executable bool      `json:"executable"`
+	Lamports   uint64    `json:"lamports"`
+	Owner      string    `json:"owner"`
+	RentEpoch  uint64    `json:"rentEpoch"`
+	Space      int       `json:"space"`
+}
+
+// getAccountInfo always returns base64 data, whatever the requested encoding.
+func (s *Server) getAccountInfo(params []json.RawMessage) (any, *RPCError) {
+	pk, rpcErr := publicKeyParam(params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, ok := s.accounts[pk]
+	if !ok {
+		return withContext{Context: rpcContext{Slot: s.slot}, Value: nil}, nil
+	}
+
+	return withContext{
+		Context: rpcContext{Slot: s.slot},
+		Value: accountResult{
+			Data:       [2]string{base64.StdEncoding.EncodeToString(account.Data), "base64"},
+			Executable: account.Executable,
+			Lamports:   account.Lamports,
+			Owner:      account.Owner.String(),
+			Space:      len(account.Data),
+		},
+	}, nil
+}
+
+func (s *Server) getTokenAccountBalance(params []json.RawMessage) (any, *RPCError) {
+	pk, rpcErr := publicKeyParam(params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	balance, ok := s.tokens[pk]
+	if !ok {
+		return nil, errInvalidParams("could not find account")
+	}
+
+	uiAmount := formatUiAmount(balance)
+
+	return withContext{
+		Context: rpcContext{Slot: s.slot},
+		Value: rpc.UiTokenAmount{
+			Amount:         strconv.FormatUint(balance.Amount, 10),
+			Decimals:       balance.Decimals,
+			UiAmountString: uiAmount,
+		},
+	}, nil
+}
+
+func formatUiAmount(b TokenBalance) string {
+	amount := strconv.FormatUint(b.Amount, 10)
+	if b.Decimals == 0 {
+		return amount
+	}
+
+	for len(amount) <= int(b.Decimals) {
+		amount = "0" + amount
+	}
+
+	point := len(amount) - int(b.Decimals)
+	integer, fraction := amount[:point], amount[point:]
+
+	for len(fraction) > 0 && fraction[len(fraction)-1] == '0' {
+		fraction = fraction[:len(fraction)-1]
+	}
+
+	if fraction == "" {
+		return integer
+	}
+
+	return integer + "." + fraction
+}
+
+// getMinimumBalanceForRentExemption uses the default rent of the cluster: two years of 3480 lamports per byte-year,
+// counting 128 bytes of account metadata.
+func getMinimumBalanceForRentExemption(params []json.RawMessage) (any, *RPCError) {
+	var dataSize uint64
+	if rpcErr := param(params, 0, &dataSize); rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	return (dataSize + 128) * 3480 * 2, nil
+}
+
+func param(params []json.RawMessage, i int, v any) *RPCError {
+	if len(params) <= i {
+		return errInvalidParams(fmt.Sprintf("missing parameter %d", i))
+	}
+
+	if err := json.Unmarshal(params[i], v); err != nil {
+		return errInvalidParams(fmt.Sprintf("invalid parameter %d", i))
+	}
+
+	return nil
+}
+
+func publicKeyParam(params []json.RawMessage) (solana.PublicKey, *RPCError) {
+	var pkStr string
+	if err := param(params, 0, &pkStr); err != nil {
+		return solana.PublicKey{}, err
+	}
+
+	pk, err := solana.PublicKeyFromBase58(pkStr)
+	if err != nil {
+		return solana.PublicKey{}, errInvalidParams("invalid public key")
+	}
+
+	return pk, nil
+}
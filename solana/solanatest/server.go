@@ -0,0 +1,245 @@
+// Package solanatest provides an in-process fake of the Solana JSON-RPC and websocket APIs
+// with scripted in-memory state, to test code built on the solana package.
+package solanatest
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// BlockhashValidity is the number of blocks a blockhash stays valid for.
+	BlockhashValidity = 150
+
+	initialSlot        = 1000
+	initialBlockHeight = 900
+)
+
+// Account is the state of an account.
+type Account struct {
+	Lamports   uint64
+	Owner      solana.PublicKey
+	Data       []byte
+	Executable bool
+}
+
+// TokenBalance is the balance of a token account, in raw units.
+type TokenBalance struct {
+	Amount   uint64
+	Decimals uint8
+}
+
+type signatureStatus struct {
+	slot   uint64
+	status rpc.ConfirmationStatusType
+	err    any
+}
+
+// Server is a fake Solana RPC node listening on a local HTTP server, which also accepts websocket connections.
+// Sent transactions are not executed: their outcome is scripted with SetOutcome or OnSend.
+type Server struct {
+	srv      *httptest.Server
+	upgrader websocket.Upgrader
+
+	mu           sync.Mutex
+	slot         uint64
+	blockHeight  uint64
+	blockhash    solana.Hash
+	blockhashes  map[solana.Hash]uint64
+	accounts     map[solana.PublicKey]Account
+	tokens       map[solana.PublicKey]TokenBalance
+	statuses     map[solana.Signature]signatureStatus
+	transactions []solana.Transaction
+	onSend       func(solana.Transaction) Outcome
+	simulation   Simulation
+	subs         map[uint64]subscription
+	nextSubID    uint64
+	conns        map[*wsConn]struct{}
+}
+
+// NewServer starts a server whose sent transactions are finalized right away. It must be closed after use.
+func NewServer() *Server {
+	s := &Server{
+		slot:        initialSlot,
+		blockHeight: initialBlockHeight,
+		blockhashes: make(map[solana.Hash]uint64),
+		accounts:    make(map[solana.PublicKey]Account),
+		tokens:      make(map[solana.PublicKey]TokenBalance),
+		statuses:    make(map[solana.Signature]signatureStatus),
+		subs:        make(map[uint64]subscription),
+		conns:       make(map[*wsConn]struct{}),
+		onSend: func(solana.Transaction) Outcome {
+			return Landed
+		},
+	}
+
+	s.rotateBlockhash()
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// URL is the JSON-RPC endpoint of the server.
+func (s *Server) URL() string {
+	return s.srv.URL
+}
+
+// WSURL is the websocket endpoint of the server.
+func (s *Server) WSURL() string {
+	return "ws" + strings.TrimPrefix(s.srv.URL, "http")
+}
+
+// Close closes the websocket connections and shuts the server down.
+func (s *Server) Close() {
+	s.mu.Lock()
+	conns := make([]*wsConn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.mu.Unlock()
+
+	for _, c := range conns {
+		_ = c.conn.Close()
+	}
+
+	s.srv.Close()
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		s.serveWS(w, r)
+		return
+	}
+
+	s.serveRPC(w, r)
+}
+
+// SetOutcome sets the outcome of every transaction sent from now on.
+func (s *Server) SetOutcome(o Outcome) {
+	s.OnSend(func(solana.Transaction) Outcome {
+		return o
+	})
+}
+
+// OnSend sets the function deciding the outcome of every transaction sent from now on.
+// The function can call the server, e.g. to advance blocks.
+func (s *Server) OnSend(fn func(solana.Transaction) Outcome) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.onSend = fn
+}
+
+// SetSimulation sets the result of every transaction simulated from now on.
+func (s *Server) SetSimulation(sim Simulation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.simulation = sim
+}
+
+// Transactions returns the transactions sent to the server, in order.
+func (s *Server) Transactions() []solana.Transaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]solana.Transaction(nil), s.transactions...)
+}
+
+// SetAccount sets the state of an account.
+func (s *Server) SetAccount(pk solana.PublicKey, account Account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.accounts[pk] = account
+}
+
+// SetBalance sets the lamports of an account, creating it as a system account if needed.
+func (s *Server) SetBalance(pk solana.PublicKey, lamports uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account := s.accounts[pk]
+	account.Lamports = lamports
+	s.accounts[pk] = account
+}
+
+// SetTokenBalance sets the balance of a token account.
+func (s *Server) SetTokenBalance(pk solana.PublicKey, balance TokenBalance) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[pk] = balance
+}
+
+// Slot returns the current slot.
+func (s *Server) Slot() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.slot
+}
+
+// BlockHeight returns the current block height.
+func (s *Server) BlockHeight() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.blockHeight
+}
+
+// Blockhash returns the latest blockhash and its last valid block height.
+func (s *Server) Blockhash() (solana.Hash, uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.blockhash, s.blockhashes[s.blockhash]
+}
+
+// AdvanceBlocks produces n blocks, each in its own slot, with a new latest blockhash.
+func (s *Server) AdvanceBlocks(n uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.advanceBlocks(n)
+}
+
+func (s *Server) advanceBlocks(n uint64) {
+	s.slot += n
+	s.blockHeight += n
+	s.rotateBlockhash()
+}
+
+func (s *Server) rotateBlockhash() {
+	var height [8]byte
+	binary.LittleEndian.PutUint64(height[:], s.blockHeight)
+
+	s.blockhash = sha256.Sum256(height[:])
+	s.blockhashes[s.blockhash] = s.blockHeight + BlockhashValidity
+}
+
+// SetSignatureStatus lands the transaction with the signature at the given commitment in the current slot,
+// with err as its error if not nil, and notifies the matching signature subscriptions.
+func (s *Server) SetSignatureStatus(sig solana.Signature, status rpc.ConfirmationStatusType, err any) {
+	s.mu.Lock()
+	s.statuses[sig] = signatureStatus{slot: s.slot, status: status, err: err}
+	notifications := s.takeNotifications(sig)
+	s.mu.Unlock()
+
+	notifications.send()
+}
+
+// expired reports whether a blockhash issued by the server can no longer be used. Blockhashes the server
+// did not issue, e.g. the ones of recorded transactions, are accepted.
+func (s *Server) expired(blockhash solana.Hash) bool {
+	lastValid, ok := s.blockhashes[blockhash]
+	return ok && s.blockHeight > lastValid
+}
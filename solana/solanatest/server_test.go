@@ -0,0 +1,296 @@
+package solanatest_test
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/require"
+
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+	"github.com/ilkamo/jupiter-go/solana/solanatest"
+)
+
+func newTestTx(t *testing.T, payer solana.PublicKey) string {
+	t.Helper()
+
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{system.NewTransferInstruction(1000, payer, solana.NewWallet().PublicKey()).Build()},
+		solana.Hash{},
+		solana.TransactionPayer(payer),
+	)
+	require.NoError(t, err)
+
+	tx.Signatures = []solana.Signature{{}}
+
+	txBytes, err := tx.MarshalBinary()
+	require.NoError(t, err)
+
+	return base64.StdEncoding.EncodeToString(txBytes)
+}
+
+func newTestClient(t *testing.T, srv *solanatest.Server) (jupSolana.Client, string) {
+	t.Helper()
+
+	wallet := jupSolana.Wallet{Wallet: solana.NewWallet()}
+
+	client, err := jupSolana.NewClient(wallet, srv.URL())
+	require.NoError(t, err)
+
+	return client, newTestTx(t, wallet.PublicKey())
+}
+
+func TestServer_Transactions(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	t.Run("landed", func(t *testing.T) {
+		srv := solanatest.NewServer()
+		defer srv.Close()
+
+		client, tx := newTestClient(t, srv)
+
+		txID, err := client.SendTransactionOnChain(ctx, tx)
+		require.NoError(t, err)
+
+		ok, err := client.CheckSignature(ctx, txID)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		monitor, err := jupSolana.NewMonitor(srv.WSURL())
+		require.NoError(t, err)
+
+		res, err := monitor.WaitForCommitmentStatus(ctx, txID, jupSolana.CommitmentFinalized)
+		require.NoError(t, err)
+		require.True(t, res.Ok)
+		require.NoError(t, res.InstructionErr)
+
+		blockhash, _ := srv.Blockhash()
+		sent := srv.Transactions()
+		require.Len(t, sent, 1)
+		require.Equal(t, blockhash, sent[0].Message.RecentBlockhash)
+		require.Equal(t, string(txID), sent[0].Signatures[0].String())
+	})
+
+	t.Run("failed with a custom error", func(t *testing.T) {
+		srv := solanatest.NewServer()
+		defer srv.Close()
+
+		srv.SetOutcome(solanatest.Failed(solanatest.InstructionError(2, 6001)))
+
+		client, tx := newTestClient(t, srv)
+
+		txID, err := client.SendTransactionOnChain(ctx, tx)
+		require.NoError(t, err)
+
+		ok, err := client.CheckSignature(ctx, txID)
+		require.True(t, ok)
+		require.ErrorContains(t, err, "transaction confirmed with error: map[InstructionError:")
+
+		monitor, err := jupSolana.NewMonitor(srv.WSURL())
+		require.NoError(t, err)
+
+		res, err := monitor.WaitForCommitmentStatus(ctx, txID, jupSolana.CommitmentConfirmed)
+		require.NoError(t, err)
+		require.EqualError(t, res.InstructionErr,
+			"transaction confirmed with error: map[InstructionError:[2 map[Custom:6001]]]")
+	})
+
+	t.Run("pending until its status is set", func(t *testing.T) {
+		srv := solanatest.NewServer()
+		defer srv.Close()
+
+		srv.SetOutcome(solanatest.Pending)
+
+		client, tx := newTestClient(t, srv)
+
+		txID, err := client.SendTransactionOnChain(ctx, tx)
+		require.NoError(t, err)
+
+		_, err = client.CheckSignature(ctx, txID)
+		require.EqualError(t, err, "transaction not finalized yet")
+
+		monitor, err := jupSolana.NewMonitor(srv.WSURL())
+		require.NoError(t, err)
+
+		done := make(chan jupSolana.MonitorResponse)
+
+		go func() {
+			res, err := monitor.WaitForCommitmentStatus(ctx, txID, jupSolana.CommitmentFinalized)
+			require.NoError(t, err)
+			done <- res
+		}()
+
+		sig := solana.MustSignatureFromBase58(string(txID))
+
+		srv.SetSignatureStatus(sig, rpc.ConfirmationStatusConfirmed, nil)
+
+		_, err = client.CheckSignature(ctx, txID)
+		require.EqualError(t, err, "transaction not finalized yet")
+
+		srv.AdvanceBlocks(32)
+		srv.SetSignatureStatus(sig, rpc.ConfirmationStatusFinalized, nil)
+
+		res := <-done
+		require.True(t, res.Ok)
+
+		ok, err := client.CheckSignature(ctx, txID)
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		srv := solanatest.NewServer()
+		defer srv.Close()
+
+		srv.SetOutcome(solanatest.Expired)
+
+		client, tx := newTestClient(t, srv)
+
+		_, lastValid := srv.Blockhash()
+
+		txID, err := client.SendTransactionOnChain(ctx, tx)
+		require.NoError(t, err)
+		require.Greater(t, srv.BlockHeight(), lastValid)
+
+		_, err = client.CheckSignature(ctx, txID)
+		require.EqualError(t, err, "transaction not finalized yet")
+
+		// The expired transaction can no longer be resent.
+		sent := srv.Transactions()
+		_, err = rpc.New(srv.URL()).SendTransaction(ctx, &sent[0])
+		require.ErrorContains(t, err, "Blockhash not found")
+	})
+
+	t.Run("callback calling the server", func(t *testing.T) {
+		srv := solanatest.NewServer()
+		defer srv.Close()
+
+		var slotAtSend uint64
+
+		srv.OnSend(func(solana.Transaction) solanatest.Outcome {
+			slotAtSend = srv.Slot()
+			srv.AdvanceBlocks(2)
+
+			return solanatest.Landed
+		})
+
+		client, tx := newTestClient(t, srv)
+
+		slot := srv.Slot()
+
+		txID, err := client.SendTransactionOnChain(ctx, tx)
+		require.NoError(t, err)
+		require.Equal(t, slot, slotAtSend)
+		require.Equal(t, slot+2, srv.Slot())
+
+		ok, err := client.CheckSignature(ctx, txID)
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("send error", func(t *testing.T) {
+		srv := solanatest.NewServer()
+		defer srv.Close()
+
+		srv.SetOutcome(solanatest.Outcome{SendErr: &solanatest.RPCError{Code: -32005, Message: "Node is behind"}})
+
+		client, tx := newTestClient(t, srv)
+
+		_, err := client.SendTransactionOnChain(ctx, tx)
+		require.ErrorContains(t, err, "Node is behind")
+		require.Empty(t, srv.Transactions())
+	})
+}
+
+func TestServer_Accounts(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	srv := solanatest.NewServer()
+	defer srv.Close()
+
+	rpcClient := rpc.New(srv.URL())
+
+	wallet := solana.NewWallet().PublicKey()
+	tokenAccount := solana.NewWallet().PublicKey()
+
+	srv.SetBalance(wallet, 1_500_000_000)
+	srv.SetAccount(tokenAccount, solanatest.Account{
+		Lamports: 2_039_280,
+		Owner:    solana.TokenProgramID,
+		Data:     []byte{1, 2, 3},
+	})
+	srv.SetTokenBalance(tokenAccount, solanatest.TokenBalance{Amount: 1_234_500, Decimals: 6})
+
+	balance, err := rpcClient.GetBalance(ctx, wallet, rpc.CommitmentFinalized)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1_500_000_000), balance.Value)
+	require.Equal(t, srv.Slot(), balance.Context.Slot)
+
+	info, err := rpcClient.GetAccountInfo(ctx, tokenAccount)
+	require.NoError(t, err)
+	require.Equal(t, solana.TokenProgramID, info.Value.Owner)
+	require.Equal(t, []byte{1, 2, 3}, info.Value.Data.GetBinary())
+
+	_, err = rpcClient.GetAccountInfo(ctx, solana.NewWallet().PublicKey())
+	require.ErrorIs(t, err, rpc.ErrNotFound)
+
+	client, err := jupSolana.NewClient(jupSolana.Wallet{Wallet: solana.NewWallet()}, srv.URL())
+	require.NoError(t, err)
+
+	tokenBalance, err := client.GetTokenAccountBalance(ctx, tokenAccount.String())
+	require.NoError(t, err)
+	require.Equal(t, "1234500", tokenBalance.Amount.String())
+	require.Equal(t, uint8(6), tokenBalance.Decimals)
+
+	_, err = client.GetTokenAccountBalance(ctx, wallet.String())
+	require.ErrorContains(t, err, "could not find account")
+
+	height, err := rpcClient.GetBlockHeight(ctx, rpc.CommitmentFinalized)
+	require.NoError(t, err)
+
+	srv.AdvanceBlocks(10)
+
+	newHeight, err := rpcClient.GetBlockHeight(ctx, rpc.CommitmentFinalized)
+	require.NoError(t, err)
+	require.Equal(t, height+10, newHeight)
+
+	rent, err := rpcClient.GetMinimumBalanceForRentExemption(ctx, 80, rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1_447_680), rent)
+
+	_, err = rpcClient.GetVersion(ctx)
+	require.ErrorContains(t, err, "Method not found")
+}
+
+func TestServer_SimulateTransaction(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	srv := solanatest.NewServer()
+	defer srv.Close()
+
+	srv.SetSimulation(solanatest.Simulation{
+		Err:           solanatest.InstructionError(0, 1),
+		Logs:          []string{"Program log: insufficient funds"},
+		UnitsConsumed: 1200,
+	})
+
+	txBytes, err := base64.StdEncoding.DecodeString(newTestTx(t, solana.NewWallet().PublicKey()))
+	require.NoError(t, err)
+
+	tx, err := jupSolana.NewTransactionFromBytes(txBytes)
+	require.NoError(t, err)
+
+	res, err := rpc.New(srv.URL()).SimulateTransaction(ctx, &tx)
+	require.NoError(t, err)
+	require.NotNil(t, res.Value.Err)
+	require.Equal(t, []string{"Program log: insufficient funds"}, res.Value.Logs)
+	require.Equal(t, uint64(1200), *res.Value.UnitsConsumed)
+	require.Empty(t, srv.Transactions())
+}
@@ -0,0 +1,186 @@
+package solanatest
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gorilla/websocket"
+)
+
+type wsConn struct {
+	conn *websocket.Conn
+	// mu serializes writes, so that a subscription is always confirmed before its notification.
+	mu sync.Mutex
+}
+
+func (c *wsConn) write(v any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_ = c.conn.WriteJSON(v)
+}
+
+type subscription struct {
+	conn       *wsConn
+	signature  solana.Signature
+	commitment rpc.ConfirmationStatusType
+}
+
+type notification struct {
+	conn    *wsConn
+	message any
+}
+
+type notifications []notification
+
+func (n notifications) send() {
+	for _, notif := range n {
+		notif.conn.write(notif.message)
+	}
+}
+
+type signatureNotification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  struct {
+		Result       withContext `json:"result"`
+		Subscription uint64      `json:"subscription"`
+	} `json:"params"`
+}
+
+func newSignatureNotification(id uint64, st signatureStatus) signatureNotification {
+	n := signatureNotification{JSONRPC: "2.0", Method: "signatureNotification"}
+	n.Params.Subscription = id
+	n.Params.Result = withContext{
+		Context: rpcContext{Slot: st.slot},
+		Value:   map[string]any{"err": st.err},
+	}
+
+	return n
+}
+
+// takeNotifications removes the subscriptions to the signature satisfied by its status and returns
+// their notifications. The caller must hold s.mu and send them once released.
+func (s *Server) takeNotifications(sig solana.Signature) notifications {
+	st, ok := s.statuses[sig]
+	if !ok {
+		return nil
+	}
+
+	var n notifications
+
+	for id, sub := range s.subs {
+		if sub.signature != sig || commitmentLevel(st.status) < commitmentLevel(sub.commitment) {
+			continue
+		}
+
+		delete(s.subs, id)
+		n = append(n, notification{conn: sub.conn, message: newSignatureNotification(id, st)})
+	}
+
+	return n
+}
+
+func (s *Server) serveWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	c := &wsConn{conn: conn}
+
+	s.mu.Lock()
+	s.conns[c] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+
+		for id, sub := range s.subs {
+			if sub.conn == c {
+				delete(s.subs, id)
+			}
+		}
+		s.mu.Unlock()
+
+		_ = conn.Close()
+	}()
+
+	for {
+		var req request
+		if err := conn.ReadJSON(&req); err != nil {
+			return
+		}
+
+		switch req.Method {
+		case "signatureSubscribe":
+			s.signatureSubscribe(c, req)
+		case "signatureUnsubscribe":
+			var id uint64
+
+			if rpcErr := param(req.Params, 0, &id); rpcErr != nil {
+				c.write(response{JSONRPC: "2.0", ID: req.ID, Error: rpcErr})
+				continue
+			}
+
+			s.mu.Lock()
+			_, ok := s.subs[id]
+			delete(s.subs, id)
+			s.mu.Unlock()
+
+			c.write(response{JSONRPC: "2.0", ID: req.ID, Result: ok})
+		default:
+			c.write(response{JSONRPC: "2.0", ID: req.ID, Error: errMethodNotFound})
+		}
+	}
+}
+
+func (s *Server) signatureSubscribe(c *wsConn, req request) {
+	var (
+		sigStr string
+		conf   struct {
+			Commitment rpc.ConfirmationStatusType `json:"commitment"`
+		}
+	)
+
+	if rpcErr := param(req.Params, 0, &sigStr); rpcErr != nil {
+		c.write(response{JSONRPC: "2.0", ID: req.ID, Error: rpcErr})
+		return
+	}
+
+	if len(req.Params) > 1 {
+		if rpcErr := param(req.Params, 1, &conf); rpcErr != nil {
+			c.write(response{JSONRPC: "2.0", ID: req.ID, Error: rpcErr})
+			return
+		}
+	}
+
+	if conf.Commitment == "" {
+		conf.Commitment = rpc.ConfirmationStatusFinalized
+	}
+
+	sig, err := solana.SignatureFromBase58(sigStr)
+	if err != nil {
+		c.write(response{JSONRPC: "2.0", ID: req.ID, Error: errInvalidParams("invalid signature")})
+		return
+	}
+
+	// Holding the connection write lock until the subscription is confirmed keeps concurrent
+	// notifications from being sent before the confirmation.
+	c.mu.Lock()
+
+	s.mu.Lock()
+	s.nextSubID++
+	id := s.nextSubID
+	s.subs[id] = subscription{conn: c, signature: sig, commitment: conf.Commitment}
+	pending := s.takeNotifications(sig)
+	s.mu.Unlock()
+
+	_ = c.conn.WriteJSON(response{JSONRPC: "2.0", ID: req.ID, Result: id})
+	c.mu.Unlock()
+
+	pending.send()
+}
@@ -36,3 +36,33 @@ func (w Wallet) SignTransaction(tx solana.Transaction) (solana.Transaction, erro
 
 	return tx, nil
 }
+
+// SignMessage signs a raw message with the wallet's private key.
+func (w Wallet) SignMessage(message []byte) (solana.Signature, error) {
+	signature, err := w.PrivateKey.Sign(message)
+	if err != nil {
+		return solana.Signature{}, fmt.Errorf("could not sign message: %w", err)
+	}
+
+	return signature, nil
+}
+
+// SignOffchainMessage serializes the off-chain message and signs it. The wallet must be one of its signers.
+// It returns the signed bytes with the signature, as verifiers need both.
+func (w Wallet) SignOffchainMessage(m OffchainMessage) ([]byte, solana.Signature, error) {
+	if !m.IsSigner(w.PublicKey()) {
+		return nil, solana.Signature{}, fmt.Errorf("wallet %s is not a signer of the message", w.PublicKey())
+	}
+
+	message, err := m.MarshalBinary()
+	if err != nil {
+		return nil, solana.Signature{}, err
+	}
+
+	signature, err := w.SignMessage(message)
+	if err != nil {
+		return nil, solana.Signature{}, err
+	}
+
+	return message, signature, nil
+}
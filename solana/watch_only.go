@@ -0,0 +1,152 @@
+package solana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// ErrWatchOnly is returned by the methods of a watch-only client that need a private key.
+var ErrWatchOnly = errors.New("watch-only client cannot sign transactions")
+
+// SimulationResult is the outcome of a simulated transaction.
+type SimulationResult struct {
+	Logs          []string
+	UnitsConsumed uint64
+	// InstructionErr is filled if the transaction would fail.
+	InstructionErr error
+}
+
+// NewWatchOnlyClient creates a client for the public key, without its private key. It can query balances, simulate
+// transactions and export them unsigned for an external signer, while SendTransactionOnChain fails with ErrWatchOnly.
+func NewWatchOnlyClient(
+	publicKey string,
+	rpcEndpoint string,
+	opts ...ClientOption,
+) (WatchOnlyClient, error) {
+	pk, err := solana.PublicKeyFromBase58(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse public key: %w", err)
+	}
+
+	c, err := newClient(Wallet{}, rpcEndpoint, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	c.publicKey = pk
+
+	return c, nil
+}
+
+// PublicKey returns the public key of the client's wallet.
+func (e client) PublicKey() solana.PublicKey {
+	return e.publicKey
+}
+
+// GetBalance returns the balance of the client's wallet in lamports, at the commitment of the client, finalized by
+// default.
+func (e client) GetBalance(ctx context.Context, opts ...ReadOption) (uint64, error) {
+	commitment, err := e.readCommitment(CommitmentFinalized, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := e.clientRPC.GetBalance(ctx, e.publicKey, commitment)
+	if err != nil {
+		return 0, fmt.Errorf("could not get balance: %w", err)
+	}
+
+	return resp.Value, nil
+}
+
+// SimulateTransaction simulates an unsigned transaction with the latest blockhash, without verifying signatures,
+// against the state at the commitment of the client, processed by default.
+func (e client) SimulateTransaction(
+	ctx context.Context,
+	txBase64 string,
+	opts ...ReadOption,
+) (SimulationResult, error) {
+	commitment, err := e.readCommitment(CommitmentProcessed, opts)
+	if err != nil {
+		return SimulationResult{}, err
+	}
+
+	tx, err := unsignedTransaction(txBase64)
+	if err != nil {
+		return SimulationResult{}, err
+	}
+
+	resp, err := e.clientRPC.SimulateTransactionWithOpts(ctx, &tx, &rpc.SimulateTransactionOpts{
+		SigVerify:              false,
+		ReplaceRecentBlockhash: true,
+		Commitment:             commitment,
+	})
+	if err != nil {
+		return SimulationResult{}, fmt.Errorf("could not simulate transaction: %w", err)
+	}
+
+	if resp.Value == nil {
+		return SimulationResult{}, fmt.Errorf("could not simulate transaction: response value is nil")
+	}
+
+	res := SimulationResult{Logs: resp.Value.Logs}
+
+	if resp.Value.UnitsConsumed != nil {
+		res.UnitsConsumed = *resp.Value.UnitsConsumed
+	}
+
+	if resp.Value.Err != nil {
+		res.InstructionErr = fmt.Errorf("transaction simulation failed: %w", &InstructionError{Err: resp.Value.Err})
+	}
+
+	return res, nil
+}
+
+// ExportUnsignedTransaction verifies the transaction if a TransactionVerifier is configured, sets the blockhash of
+// the client's strategy unless it uses a durable nonce, and returns it in base64 with empty signatures, to be signed
+// elsewhere.
+func (e client) ExportUnsignedTransaction(ctx context.Context, txBase64 string) (string, error) {
+	tx, err := unsignedTransaction(txBase64)
+	if err != nil {
+		return "", err
+	}
+
+	if e.verifier != nil {
+		if err := e.verifier.VerifyTransaction(ctx, tx); err != nil {
+			return "", fmt.Errorf("could not verify transaction: %w", err)
+		}
+	}
+
+	if _, ok := DurableNonce(tx); !ok {
+		blockhash, err := e.blockhash(ctx, tx)
+		if err != nil {
+			return "", err
+		}
+
+		tx.Message.RecentBlockhash = blockhash.Hash
+	}
+
+	txBase64, err = tx.ToBase64()
+	if err != nil {
+		return "", fmt.Errorf("could not serialize transaction: %w", err)
+	}
+
+	return txBase64, nil
+}
+
+// unsignedTransaction deserializes a transaction and replaces its signatures with as many empty ones as required,
+// the format expected by the RPC and by external signers.
+func unsignedTransaction(txBase64 string) (solana.Transaction, error) {
+	tx, err := NewTransactionFromBase64(txBase64)
+	if err != nil {
+		return solana.Transaction{}, fmt.Errorf("could not deserialize transaction: %w", err)
+	}
+
+	tx.Signatures = make([]solana.Signature, tx.Message.Header.NumRequiredSignatures)
+
+	return tx, nil
+}
@@ -0,0 +1,123 @@
+package solana_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/stretchr/testify/require"
+
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+)
+
+const testWatchedPublicKey = "BXzmfHxfEMcMj8hDccUNdrwXVNeybyfb2iV2nktE1VnJ"
+
+func TestNewWatchOnlyClient(t *testing.T) {
+	t.Run("invalid public key", func(t *testing.T) {
+		_, err := jupSolana.NewWatchOnlyClient("invalid", "http://localhost:8899")
+		require.ErrorContains(t, err, "could not parse public key")
+	})
+
+	t.Run("without rpc endpoint", func(t *testing.T) {
+		_, err := jupSolana.NewWatchOnlyClient(testWatchedPublicKey, "")
+		require.EqualError(t, err, "rpcEndpoint is required when no RPC service is provided")
+	})
+
+	c, err := jupSolana.NewWatchOnlyClient(testWatchedPublicKey, "", jupSolana.WithClientRPC(rpcMock{}))
+	require.NoError(t, err)
+	require.Equal(t, testWatchedPublicKey, c.PublicKey().String())
+
+	t.Run("sending needs a private key", func(t *testing.T) {
+		_, err := c.SendTransactionOnChain(context.TODO(), testTx)
+		require.ErrorIs(t, err, jupSolana.ErrWatchOnly)
+	})
+
+	t.Run("balances", func(t *testing.T) {
+		lamports, err := c.GetBalance(context.TODO())
+		require.NoError(t, err)
+		require.Equal(t, uint64(1_500_000_000), lamports)
+
+		balance, err := c.GetTokenAccountBalance(context.TODO(), testWatchedPublicKey)
+		require.NoError(t, err)
+		require.Equal(t, "1000000000", balance.Amount.String())
+	})
+
+	t.Run("simulate an unsigned transaction", func(t *testing.T) {
+		res, err := c.SimulateTransaction(context.TODO(), testTx)
+		require.NoError(t, err)
+		require.NoError(t, res.InstructionErr)
+		require.Equal(t, uint64(2_000), res.UnitsConsumed)
+		require.Len(t, res.Logs, 1)
+	})
+
+	t.Run("simulation failure", func(t *testing.T) {
+		failing, err := jupSolana.NewWatchOnlyClient(
+			testWatchedPublicKey,
+			"",
+			jupSolana.WithClientRPC(rpcMock{shouldFailSimulation: true}),
+		)
+		require.NoError(t, err)
+
+		res, err := failing.SimulateTransaction(context.TODO(), testTx)
+		require.NoError(t, err)
+		require.ErrorContains(t, res.InstructionErr, "transaction simulation failed")
+	})
+
+	t.Run("export unsigned transaction", func(t *testing.T) {
+		txBase64, err := c.ExportUnsignedTransaction(context.TODO(), testTx)
+		require.NoError(t, err)
+
+		tx, err := jupSolana.NewTransactionFromBase64(txBase64)
+		require.NoError(t, err)
+		require.Equal(t, []solana.Signature{{}}, tx.Signatures)
+		require.Equal(t, "uiYzZ5PCq6C8BRSLSUGBScrXo62bBFbRFP9EkPcaWN9", tx.Message.RecentBlockhash.String())
+	})
+
+	t.Run("export verifies the transaction", func(t *testing.T) {
+		verified, err := jupSolana.NewWatchOnlyClient(
+			testWatchedPublicKey,
+			"",
+			jupSolana.WithClientRPC(rpcMock{}),
+			jupSolana.WithTransactionVerifier(verifierMock{shouldFail: true}),
+		)
+		require.NoError(t, err)
+
+		_, err = verified.ExportUnsignedTransaction(context.TODO(), testTx)
+		require.EqualError(t, err, "could not verify transaction: mocked error")
+	})
+
+	t.Run("send a transaction signed elsewhere", func(t *testing.T) {
+		signer := solana.NewWallet()
+
+		tx, err := solana.NewTransaction(
+			[]solana.Instruction{
+				system.NewTransferInstruction(1000, signer.PublicKey(), c.PublicKey()).Build(),
+			},
+			solana.MustHashFromBase58("uiYzZ5PCq6C8BRSLSUGBScrXo62bBFbRFP9EkPcaWN9"),
+			solana.TransactionPayer(signer.PublicKey()),
+		)
+		require.NoError(t, err)
+
+		unsigned, err := tx.ToBase64()
+		require.NoError(t, err)
+
+		_, err = c.SendSignedTransaction(context.TODO(), unsigned)
+		require.ErrorContains(t, err, "could not verify signatures")
+
+		_, err = tx.Sign(func(solana.PublicKey) *solana.PrivateKey { return &signer.PrivateKey })
+		require.NoError(t, err)
+
+		signed, err := tx.ToBase64()
+		require.NoError(t, err)
+
+		txID, err := c.SendSignedTransaction(context.TODO(), signed)
+		require.NoError(t, err)
+		require.Equal(t, jupSolana.TxID(testSignature), txID)
+	})
+
+	t.Run("invalid transaction", func(t *testing.T) {
+		_, err := c.SimulateTransaction(context.TODO(), "invalid")
+		require.ErrorContains(t, err, "could not deserialize transaction")
+	})
+}
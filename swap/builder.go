@@ -0,0 +1,274 @@
+package swap
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+
+	"github.com/ilkamo/jupiter-go/jupiter"
+)
+
+// MaxTransactionSize is the maximum size in bytes of a serialized transaction, signatures included.
+const MaxTransactionSize = 1232
+
+// ErrTransactionTooLarge is returned when a composed transaction still exceeds MaxTransactionSize
+// after every step of the ShrinkPolicy.
+var ErrTransactionTooLarge = errors.New("transaction too large")
+
+// ComposeFunc builds a transaction from the swap instructions of a quote, adding its own instructions.
+// It does not need to sign the transaction: the signatures are counted in its size anyway.
+type ComposeFunc func(
+	context.Context,
+	jupiter.SwapInstructionsResponse,
+	jupiter.QuoteResponse,
+) (*solana.Transaction, error)
+
+// BuildRequest describes a transaction to compose around a swap.
+type BuildRequest struct {
+	// Quote holds the parameters used to get a quote from Jupiter.
+	Quote jupiter.QuoteGetParams
+	// Swap is used as a template for the /swap-instructions call.
+	// QuoteResponse and UserPublicKey are filled by the builder.
+	Swap jupiter.SwapRequest
+	// Compose builds the transaction from the swap instructions.
+	Compose ComposeFunc
+}
+
+// BuildResult holds the transaction that fits and how it was quoted.
+type BuildResult struct {
+	Transaction  *solana.Transaction
+	Size         int
+	Quote        jupiter.QuoteResponse
+	Instructions jupiter.SwapInstructionsResponse
+	// QuoteParams are the parameters of the final quote, with MaxAccounts and OnlyDirectRoutes as shrunk.
+	QuoteParams jupiter.QuoteGetParams
+	// Sizes lists the size of every transaction composed, the final one last.
+	Sizes []int
+}
+
+// ShrinkPolicy defines how the quote of a transaction that is too large is restricted.
+type ShrinkPolicy struct {
+	// MaxAccounts are tried in order, skipping the ones not below the MaxAccounts of the previous quote.
+	MaxAccounts []uint64
+	// OnlyDirectRoutes restricts the quote to single hop routes once MaxAccounts are exhausted.
+	OnlyDirectRoutes bool
+}
+
+// DefaultShrinkPolicy starts below the default max accounts of Jupiter, 64, and ends with direct routes.
+var DefaultShrinkPolicy = ShrinkPolicy{
+	MaxAccounts:      []uint64{48, 32, 24, 16},
+	OnlyDirectRoutes: true,
+}
+
+// shrink returns the parameters of the next quote, false if the policy has no further step.
+func (p ShrinkPolicy) shrink(params jupiter.QuoteGetParams) (jupiter.QuoteGetParams, bool) {
+	for _, maxAccounts := range p.MaxAccounts {
+		if params.MaxAccounts == nil || maxAccounts < *params.MaxAccounts {
+			params.MaxAccounts = &maxAccounts
+			return params, true
+		}
+	}
+
+	if p.OnlyDirectRoutes && (params.OnlyDirectRoutes == nil || !*params.OnlyDirectRoutes) {
+		direct := true
+		params.OnlyDirectRoutes = &direct
+
+		return params, true
+	}
+
+	return params, false
+}
+
+type builder struct {
+	jupClient     jupiter.ClientWithResponsesInterface
+	userPublicKey string
+	policy        ShrinkPolicy
+}
+
+// NewBuilder creates a builder that composes transactions around the swap instructions
+// of Jupiter for the given user public key, shrinking their routes until they fit.
+func NewBuilder(
+	jupClient jupiter.ClientWithResponsesInterface,
+	userPublicKey string,
+	opts ...BuilderOption,
+) (Builder, error) {
+	if jupClient == nil {
+		return nil, fmt.Errorf("jupiter client is required")
+	}
+
+	if userPublicKey == "" {
+		return nil, fmt.Errorf("userPublicKey is required")
+	}
+
+	b := &builder{
+		jupClient:     jupClient,
+		userPublicKey: userPublicKey,
+		policy:        DefaultShrinkPolicy,
+	}
+
+	for _, opt := range opts {
+		if err := opt(b); err != nil {
+			return nil, fmt.Errorf("could not apply option: %w", err)
+		}
+	}
+
+	return b, nil
+}
+
+// Build quotes, gets the swap instructions and composes the transaction. While the serialized transaction
+// exceeds MaxTransactionSize, it quotes again with a lower MaxAccounts and, as a last resort, direct routes only.
+func (b builder) Build(ctx context.Context, req BuildRequest) (BuildResult, error) {
+	if req.Compose == nil {
+		return BuildResult{}, fmt.Errorf("compose function is required")
+	}
+
+	var res BuildResult
+
+	params := req.Quote
+
+	for {
+		quote, err := b.quote(ctx, params)
+		if err != nil {
+			return res, err
+		}
+
+		instructions, err := b.swapInstructions(ctx, req.Swap, quote)
+		if err != nil {
+			return res, err
+		}
+
+		tx, err := req.Compose(ctx, instructions, quote)
+		if err != nil {
+			return res, fmt.Errorf("could not compose transaction: %w", err)
+		}
+
+		if tx == nil {
+			return res, fmt.Errorf("could not compose transaction: no transaction returned")
+		}
+
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			return res, fmt.Errorf("could not serialize transaction: %w", err)
+		}
+
+		res = BuildResult{
+			Transaction:  tx,
+			Size:         len(raw),
+			Quote:        quote,
+			Instructions: instructions,
+			QuoteParams:  params,
+			Sizes:        append(res.Sizes, len(raw)),
+		}
+
+		if res.Size <= MaxTransactionSize {
+			return res, nil
+		}
+
+		next, ok := b.policy.shrink(params)
+		if !ok {
+			return res, fmt.Errorf("%w: %d bytes, at most %d after %d quotes",
+				ErrTransactionTooLarge, res.Size, MaxTransactionSize, len(res.Sizes))
+		}
+
+		params = next
+	}
+}
+
+func (b builder) quote(ctx context.Context, params jupiter.QuoteGetParams) (jupiter.QuoteResponse, error) {
+	if err := params.Validate(); err != nil {
+		return jupiter.QuoteResponse{}, fmt.Errorf("could not get quote: %w", err)
+	}
+
+	resp, err := b.jupClient.QuoteGetWithResponse(ctx, &params)
+	if err != nil {
+		return jupiter.QuoteResponse{}, fmt.Errorf("could not get quote: %w", err)
+	}
+
+	if resp.JSON200 == nil {
+		return jupiter.QuoteResponse{}, fmt.Errorf("could not get quote: %s: %s", resp.Status(), resp.Body)
+	}
+
+	return *resp.JSON200, nil
+}
+
+func (b builder) swapInstructions(
+	ctx context.Context,
+	req jupiter.SwapRequest,
+	quote jupiter.QuoteResponse,
+) (jupiter.SwapInstructionsResponse, error) {
+	req.QuoteResponse = quote
+	req.UserPublicKey = b.userPublicKey
+
+	if err := req.Validate(); err != nil {
+		return jupiter.SwapInstructionsResponse{}, fmt.Errorf("could not get swap instructions: %w", err)
+	}
+
+	resp, err := b.jupClient.SwapInstructionsPostWithResponse(ctx, req)
+	if err != nil {
+		return jupiter.SwapInstructionsResponse{}, fmt.Errorf("could not get swap instructions: %w", err)
+	}
+
+	if resp.JSON200 == nil {
+		return jupiter.SwapInstructionsResponse{}, fmt.Errorf("could not get swap instructions: %s: %s",
+			resp.Status(), resp.Body)
+	}
+
+	return *resp.JSON200, nil
+}
+
+// Instructions converts the swap instructions in execution order: compute budget, other, setup, swap and cleanup.
+func Instructions(resp jupiter.SwapInstructionsResponse) ([]solana.Instruction, error) {
+	all := append([]jupiter.Instruction{}, resp.ComputeBudgetInstructions...)
+
+	if resp.OtherInstructions != nil {
+		all = append(all, *resp.OtherInstructions...)
+	}
+
+	all = append(all, resp.SetupInstructions...)
+	all = append(all, resp.SwapInstruction)
+
+	if resp.CleanupInstruction != nil {
+		all = append(all, *resp.CleanupInstruction)
+	}
+
+	instructions := make([]solana.Instruction, len(all))
+
+	for i, ix := range all {
+		var err error
+
+		instructions[i], err = instruction(ix)
+		if err != nil {
+			return nil, fmt.Errorf("could not convert instruction %d: %w", i, err)
+		}
+	}
+
+	return instructions, nil
+}
+
+func instruction(ix jupiter.Instruction) (solana.Instruction, error) {
+	programID, err := solana.PublicKeyFromBase58(ix.ProgramId)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse program id: %w", err)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(ix.Data)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode instruction data: %w", err)
+	}
+
+	accounts := make(solana.AccountMetaSlice, len(ix.Accounts))
+
+	for i, account := range ix.Accounts {
+		key, err := solana.PublicKeyFromBase58(account.Pubkey)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse account %d: %w", i, err)
+		}
+
+		accounts[i] = solana.NewAccountMeta(key, account.IsWritable, account.IsSigner)
+	}
+
+	return solana.NewInstruction(programID, accounts, data), nil
+}
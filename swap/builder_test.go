@@ -0,0 +1,267 @@
+package swap_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilkamo/jupiter-go/jupiter"
+	"github.com/ilkamo/jupiter-go/jupiter/jupitertest"
+	"github.com/ilkamo/jupiter-go/swap"
+)
+
+// instructionsMock returns a swap instruction with as many accounts as the max accounts of the last quote,
+// 64 by default like Jupiter, and 8 for direct routes.
+type instructionsMock struct {
+	jupiterMock
+	statusCode int
+}
+
+func (j instructionsMock) SwapInstructionsPostWithResponse(
+	_ context.Context,
+	_ jupiter.SwapInstructionsPostJSONRequestBody,
+	_ ...jupiter.RequestEditorFn,
+) (*jupiter.SwapInstructionsPostResponse, error) {
+	if j.statusCode != 0 {
+		return &jupiter.SwapInstructionsPostResponse{
+			HTTPResponse: &http.Response{StatusCode: j.statusCode, Status: http.StatusText(j.statusCode)},
+		}, nil
+	}
+
+	params := (*j.quoteParams)[len(*j.quoteParams)-1]
+
+	accounts := uint64(64)
+	if params.MaxAccounts != nil {
+		accounts = *params.MaxAccounts
+	}
+
+	if params.OnlyDirectRoutes != nil && *params.OnlyDirectRoutes {
+		accounts = 8
+	}
+
+	swapIx := jupiter.Instruction{ProgramId: testJupiterProgramID.String(), Data: "AQ=="}
+	for i := range accounts {
+		swapIx.Accounts = append(swapIx.Accounts, jupiter.AccountMeta{
+			Pubkey:     solana.PublicKey{byte(i + 1)}.String(),
+			IsWritable: true,
+		})
+	}
+
+	return &jupiter.SwapInstructionsPostResponse{
+		HTTPResponse: &http.Response{StatusCode: http.StatusOK},
+		JSON200:      &jupiter.SwapInstructionsResponse{SwapInstruction: swapIx},
+	}, nil
+}
+
+func composeLegacy(
+	_ context.Context,
+	resp jupiter.SwapInstructionsResponse,
+	_ jupiter.QuoteResponse,
+) (*solana.Transaction, error) {
+	instructions, err := swap.Instructions(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return solana.NewTransaction(instructions, testBlockhash,
+		solana.TransactionPayer(solana.MustPublicKeyFromBase58(testUserPublicKey)))
+}
+
+func TestBuilder_Build(t *testing.T) {
+	req := swap.BuildRequest{
+		Quote: jupiter.QuoteGetParams{
+			InputMint:  swap.WrappedSolMint,
+			OutputMint: testMint,
+			Amount:     100000,
+		},
+		Compose: composeLegacy,
+	}
+
+	newBuilder := func(t *testing.T, quoteParams *[]jupiter.QuoteGetParams, opts ...swap.BuilderOption) swap.Builder {
+		t.Helper()
+
+		b, err := swap.NewBuilder(instructionsMock{jupiterMock: jupiterMock{quoteParams: quoteParams}},
+			testUserPublicKey, opts...)
+		require.NoError(t, err)
+
+		return b
+	}
+
+	t.Run("invalid params", func(t *testing.T) {
+		_, err := swap.NewBuilder(nil, testUserPublicKey)
+		require.EqualError(t, err, "jupiter client is required")
+
+		_, err = swap.NewBuilder(jupiterMock{}, "")
+		require.EqualError(t, err, "userPublicKey is required")
+
+		_, err = swap.NewBuilder(jupiterMock{}, testUserPublicKey,
+			swap.WithShrinkPolicy(swap.ShrinkPolicy{MaxAccounts: []uint64{32, 0}}))
+		require.EqualError(t, err, "could not apply option: max accounts must be positive")
+
+		_, err = swap.NewBuilder(jupiterMock{}, testUserPublicKey,
+			swap.WithShrinkPolicy(swap.ShrinkPolicy{MaxAccounts: []uint64{32, 32}}))
+		require.EqualError(t, err, "could not apply option: max accounts must be decreasing")
+
+		var quoteParams []jupiter.QuoteGetParams
+
+		_, err = newBuilder(t, &quoteParams).Build(context.TODO(), swap.BuildRequest{Quote: req.Quote})
+		require.EqualError(t, err, "compose function is required")
+	})
+
+	t.Run("fits at the first quote", func(t *testing.T) {
+		var quoteParams []jupiter.QuoteGetParams
+
+		maxAccounts := uint64(20)
+		small := req
+		small.Quote.MaxAccounts = &maxAccounts
+
+		res, err := newBuilder(t, &quoteParams).Build(context.TODO(), small)
+		require.NoError(t, err)
+		require.Len(t, quoteParams, 1)
+		require.Equal(t, []int{res.Size}, res.Sizes)
+		require.Equal(t, small.Quote, res.QuoteParams)
+		require.Len(t, res.Transaction.Message.Instructions, 1)
+	})
+
+	t.Run("shrink the max accounts", func(t *testing.T) {
+		var quoteParams []jupiter.QuoteGetParams
+
+		res, err := newBuilder(t, &quoteParams).Build(context.TODO(), req)
+		require.NoError(t, err)
+		require.Len(t, quoteParams, 3)
+		require.Nil(t, quoteParams[0].MaxAccounts)
+		require.Equal(t, uint64(48), *quoteParams[1].MaxAccounts)
+		require.Equal(t, uint64(32), *res.QuoteParams.MaxAccounts)
+		require.Nil(t, res.QuoteParams.OnlyDirectRoutes)
+
+		require.Len(t, res.Sizes, 3)
+		require.Greater(t, res.Sizes[1], swap.MaxTransactionSize)
+		require.LessOrEqual(t, res.Size, swap.MaxTransactionSize)
+
+		raw, err := res.Transaction.MarshalBinary()
+		require.NoError(t, err)
+		require.Len(t, raw, res.Size)
+
+		require.Nil(t, req.Quote.MaxAccounts, "the request of the caller must not change")
+	})
+
+	t.Run("direct routes as a last resort", func(t *testing.T) {
+		var quoteParams []jupiter.QuoteGetParams
+
+		b := newBuilder(t, &quoteParams, swap.WithShrinkPolicy(swap.ShrinkPolicy{
+			MaxAccounts:      []uint64{56, 48},
+			OnlyDirectRoutes: true,
+		}))
+
+		res, err := b.Build(context.TODO(), req)
+		require.NoError(t, err)
+		require.Len(t, res.Sizes, 4)
+		require.Equal(t, uint64(48), *res.QuoteParams.MaxAccounts)
+		require.True(t, *res.QuoteParams.OnlyDirectRoutes)
+	})
+
+	t.Run("skip the max accounts not below the requested ones", func(t *testing.T) {
+		var quoteParams []jupiter.QuoteGetParams
+
+		maxAccounts := uint64(40)
+		large := req
+		large.Quote.MaxAccounts = &maxAccounts
+
+		res, err := newBuilder(t, &quoteParams).Build(context.TODO(), large)
+		require.NoError(t, err)
+		require.Equal(t, uint64(32), *res.QuoteParams.MaxAccounts)
+		require.Len(t, quoteParams, 2)
+	})
+
+	t.Run("still too large", func(t *testing.T) {
+		var quoteParams []jupiter.QuoteGetParams
+
+		b := newBuilder(t, &quoteParams, swap.WithShrinkPolicy(swap.ShrinkPolicy{MaxAccounts: []uint64{48}}))
+
+		res, err := b.Build(context.TODO(), req)
+		require.ErrorIs(t, err, swap.ErrTransactionTooLarge)
+		require.ErrorContains(t, err, "at most 1232 after 2 quotes")
+		require.Len(t, res.Sizes, 2)
+		require.Equal(t, uint64(48), *res.QuoteParams.MaxAccounts)
+	})
+
+	t.Run("compose error", func(t *testing.T) {
+		var quoteParams []jupiter.QuoteGetParams
+
+		failing := req
+		failing.Compose = func(
+			context.Context,
+			jupiter.SwapInstructionsResponse,
+			jupiter.QuoteResponse,
+		) (*solana.Transaction, error) {
+			return nil, errors.New("mocked error")
+		}
+
+		_, err := newBuilder(t, &quoteParams).Build(context.TODO(), failing)
+		require.EqualError(t, err, "could not compose transaction: mocked error")
+	})
+
+	t.Run("compose without transaction", func(t *testing.T) {
+		var quoteParams []jupiter.QuoteGetParams
+
+		empty := req
+		empty.Compose = func(
+			context.Context,
+			jupiter.SwapInstructionsResponse,
+			jupiter.QuoteResponse,
+		) (*solana.Transaction, error) {
+			return nil, nil
+		}
+
+		_, err := newBuilder(t, &quoteParams).Build(context.TODO(), empty)
+		require.EqualError(t, err, "could not compose transaction: no transaction returned")
+	})
+
+	t.Run("swap instructions error", func(t *testing.T) {
+		var quoteParams []jupiter.QuoteGetParams
+
+		b, err := swap.NewBuilder(instructionsMock{
+			jupiterMock: jupiterMock{quoteParams: &quoteParams},
+			statusCode:  http.StatusBadRequest,
+		}, testUserPublicKey)
+		require.NoError(t, err)
+
+		_, err = b.Build(context.TODO(), req)
+		require.EqualError(t, err, "could not get swap instructions: Bad Request: ")
+	})
+}
+
+func TestInstructions(t *testing.T) {
+	fixtures := jupitertest.DefaultFixtures()
+
+	t.Run("execution order", func(t *testing.T) {
+		instructions, err := swap.Instructions(fixtures.SwapInstructions)
+		require.NoError(t, err)
+		require.Len(t, instructions, 8)
+
+		require.Equal(t, solana.ComputeBudget, instructions[0].ProgramID())
+		require.Equal(t, solana.SystemProgramID, instructions[1].ProgramID())
+		require.Equal(t, testJupiterProgramID, instructions[6].ProgramID())
+		require.Equal(t, solana.TokenProgramID, instructions[7].ProgramID())
+
+		data, err := instructions[0].Data()
+		require.NoError(t, err)
+		require.Equal(t, []byte{2, 0x21, 0xc5, 0x03, 0x00}, data)
+
+		accounts := instructions[6].Accounts()
+		require.Len(t, accounts, len(fixtures.SwapInstructions.SwapInstruction.Accounts))
+		require.Equal(t, fixtures.SwapInstructions.SwapInstruction.Accounts[0].Pubkey, accounts[0].PublicKey.String())
+	})
+
+	t.Run("invalid instruction", func(t *testing.T) {
+		invalid := fixtures.SwapInstructions
+		invalid.SwapInstruction.Data = "not base64"
+
+		_, err := swap.Instructions(invalid)
+		require.ErrorContains(t, err, "could not convert instruction 6: could not decode instruction data: ")
+	})
+}
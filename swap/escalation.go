@@ -0,0 +1,184 @@
+package swap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/ilkamo/jupiter-go/jupiter"
+	"github.com/ilkamo/jupiter-go/solana"
+)
+
+var (
+	// ErrEscalationBudgetExhausted is returned when the next attempt of an escalating swap would exceed its budget.
+	ErrEscalationBudgetExhausted = errors.New("escalation budget exhausted")
+	// ErrSwapFailed is returned when a swap transaction landed with an instruction error, which is not retried.
+	ErrSwapFailed = errors.New("swap transaction failed")
+)
+
+// FeeSchedule gives the priority fee or Jito tip in lamports of every attempt of an escalating swap, from zero.
+type FeeSchedule interface {
+	Fee(attempt int) uint64
+}
+
+// LinearFeeSchedule raises the fee by StepLamports at every attempt, up to MaxLamports if not zero.
+type LinearFeeSchedule struct {
+	InitialLamports uint64
+	StepLamports    uint64
+	MaxLamports     uint64
+}
+
+// Fee returns the fee of the attempt.
+func (s LinearFeeSchedule) Fee(attempt int) uint64 {
+	return capFee(float64(s.InitialLamports)+float64(s.StepLamports)*float64(attempt), s.MaxLamports)
+}
+
+// MultiplicativeFeeSchedule multiplies the fee by Factor at every attempt, up to MaxLamports if not zero.
+type MultiplicativeFeeSchedule struct {
+	InitialLamports uint64
+	Factor          float64
+	MaxLamports     uint64
+}
+
+// Fee returns the fee of the attempt.
+func (s MultiplicativeFeeSchedule) Fee(attempt int) uint64 {
+	return capFee(float64(s.InitialLamports)*math.Pow(s.Factor, float64(attempt)), s.MaxLamports)
+}
+
+func capFee(fee float64, maxLamports uint64) uint64 {
+	if maxLamports > 0 && fee >= float64(maxLamports) {
+		return maxLamports
+	}
+
+	if fee >= math.MaxUint64 {
+		return math.MaxUint64
+	}
+
+	return uint64(fee)
+}
+
+// EscalationPolicy defines how a swap that did not land before its blockhash expired is built and sent again.
+type EscalationPolicy struct {
+	// Schedule gives the fee of every attempt.
+	Schedule FeeSchedule
+	// BudgetLamports bounds the sum of the fees of all attempts. The fee of an attempt is only paid if it lands, but
+	// counting every attempt keeps a congested market from draining the wallet.
+	BudgetLamports uint64
+	// JitoTip pays the fee as a Jito tip instead of a priority fee. The Solana client must send to a Jito RPC.
+	JitoTip bool
+	// PriorityLevel is the priority level Jupiter estimates the priority fee at, capped at the fee of the attempt.
+	// VeryHigh by default.
+	PriorityLevel jupiter.SwapRequestPrioritizationFeeLamportsPriorityLevelWithMaxLamportsPriorityLevel
+	// Commitment is the commitment status a swap transaction must reach, CommitmentConfirmed by default.
+	Commitment solana.CommitmentStatus
+}
+
+// Attempt describes a swap transaction built by the swapper.
+type Attempt struct {
+	// TxID is empty if the transaction was not sent.
+	TxID solana.TxID
+	// FeeLamports is the priority fee or Jito tip of an escalating swapper.
+	FeeLamports uint64
+	// Quote is the quote the transaction was built from. Its SlippageBps and OtherAmountThreshold tell the
+	// slippage accepted.
+	Quote jupiter.QuoteResponse
+	// DynamicSlippage is true if Jupiter estimated the slippage of the transaction instead.
+	DynamicSlippage bool
+	// Err is why the attempt did not succeed, nil for the last attempt of a successful swap.
+	Err error
+}
+
+type priorityLevel = jupiter.SwapRequestPrioritizationFeeLamportsPriorityLevelWithMaxLamportsPriorityLevel
+
+type priorityLevelWithMaxLamports = struct {
+	MaxLamports   *uint64        `json:"maxLamports,omitempty"`
+	PriorityLevel *priorityLevel `json:"priorityLevel,omitempty"`
+}
+
+// prioritizationFee is the type of jupiter.SwapRequest.PrioritizationFeeLamports.
+type prioritizationFee = struct {
+	JitoTipLamports              *uint64                       `json:"jitoTipLamports,omitempty"`
+	PriorityLevelWithMaxLamports *priorityLevelWithMaxLamports `json:"priorityLevelWithMaxLamports,omitempty"`
+}
+
+// prioritizationFee returns the prioritization fee of the swap request for the fee of an attempt.
+func (p EscalationPolicy) prioritizationFee(fee uint64) *prioritizationFee {
+	if p.JitoTip {
+		return &prioritizationFee{JitoTipLamports: &fee}
+	}
+
+	level := p.PriorityLevel
+
+	return &prioritizationFee{
+		PriorityLevelWithMaxLamports: &priorityLevelWithMaxLamports{MaxLamports: &fee, PriorityLevel: &level},
+	}
+}
+
+// escalate builds and sends the swap until it lands, raising its fee every time its blockhash expires first.
+// It stops on success, on an instruction error, or when the next attempt would exceed the budget. The fees of the
+// attempts of previous quotes count towards the budget, while the schedule starts again with every quote.
+func (s swapper) escalate(
+	ctx context.Context,
+	req Request,
+	quote jupiter.QuoteResponse,
+	res Result,
+) (Result, error) {
+	policy := *s.escalation
+
+	spent, sent := uint64(0), 0
+
+	for _, a := range res.Attempts {
+		if a.TxID != "" {
+			spent += a.FeeLamports
+			sent++
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		fee := policy.Schedule.Fee(attempt)
+		if fee == 0 {
+			return res, fmt.Errorf("fee schedule gives no fee for attempt %d", attempt+1)
+		}
+
+		if fee > policy.BudgetLamports-spent {
+			return res, fmt.Errorf("%w: %d of %d lamports offered in %d attempts, the next one needs %d",
+				ErrEscalationBudgetExhausted, spent, policy.BudgetLamports, sent, fee)
+		}
+
+		attemptReq := req
+		attemptReq.Swap.PrioritizationFeeLamports = policy.prioritizationFee(fee)
+
+		var err error
+
+		res, err = s.execute(ctx, attemptReq, quote, res)
+
+		last := &res.Attempts[len(res.Attempts)-1]
+		last.FeeLamports = fee
+
+		if err != nil {
+			return res, err
+		}
+
+		spent += fee
+		sent++
+
+		resp, err := s.monitor.WaitForTransaction(ctx, res.TxID, policy.Commitment, res.LastValidBlockHeight)
+		if errors.Is(err, solana.ErrBlockhashExpired) {
+			last.Err = err
+			continue
+		}
+
+		if err != nil {
+			last.Err = err
+			return res, fmt.Errorf("could not wait for swap transaction: %w", err)
+		}
+
+		if resp.InstructionErr != nil {
+			last.Err = resp.InstructionErr
+			return res, fmt.Errorf("%w: %w", ErrSwapFailed, resp.InstructionErr)
+		}
+
+		return res, nil
+	}
+}
@@ -0,0 +1,200 @@
+package swap_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilkamo/jupiter-go/jupiter"
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+	"github.com/ilkamo/jupiter-go/swap"
+)
+
+// monitorMock returns its outcomes in order, one per waited transaction.
+type monitorMock struct {
+	jupSolana.Monitor
+	outcomes []monitorOutcome
+	waited   *[]waitedTx
+}
+
+type monitorOutcome struct {
+	resp jupSolana.MonitorResponse
+	err  error
+}
+
+type waitedTx struct {
+	status               jupSolana.CommitmentStatus
+	lastValidBlockHeight uint64
+}
+
+func (m monitorMock) WaitForTransaction(
+	_ context.Context,
+	_ jupSolana.TxID,
+	status jupSolana.CommitmentStatus,
+	lastValidBlockHeight uint64,
+) (jupSolana.MonitorResponse, error) {
+	*m.waited = append(*m.waited, waitedTx{status: status, lastValidBlockHeight: lastValidBlockHeight})
+
+	outcome := m.outcomes[len(*m.waited)-1]
+
+	return outcome.resp, outcome.err
+}
+
+var (
+	landed  = monitorOutcome{resp: jupSolana.MonitorResponse{Ok: true}}
+	expired = monitorOutcome{err: jupSolana.ErrBlockhashExpired}
+)
+
+func TestFeeSchedule(t *testing.T) {
+	t.Run("linear", func(t *testing.T) {
+		s := swap.LinearFeeSchedule{InitialLamports: 1_000, StepLamports: 500, MaxLamports: 2_000}
+
+		require.Equal(t, []uint64{1_000, 1_500, 2_000, 2_000}, []uint64{s.Fee(0), s.Fee(1), s.Fee(2), s.Fee(3)})
+	})
+
+	t.Run("multiplicative", func(t *testing.T) {
+		s := swap.MultiplicativeFeeSchedule{InitialLamports: 1_000, Factor: 2, MaxLamports: 5_000}
+
+		require.Equal(t, []uint64{1_000, 2_000, 4_000, 5_000}, []uint64{s.Fee(0), s.Fee(1), s.Fee(2), s.Fee(3)})
+	})
+
+	t.Run("without cap", func(t *testing.T) {
+		s := swap.MultiplicativeFeeSchedule{InitialLamports: 1_000, Factor: 10}
+
+		require.Equal(t, uint64(1_000_000_000), s.Fee(6))
+	})
+}
+
+func TestSwapper_Escalation(t *testing.T) {
+	req := swap.Request{
+		Quote: jupiter.QuoteGetParams{
+			InputMint:  swap.WrappedSolMint,
+			OutputMint: testMint,
+			Amount:     100000,
+		},
+	}
+
+	policy := swap.EscalationPolicy{
+		Schedule:       swap.LinearFeeSchedule{InitialLamports: 1_000, StepLamports: 500},
+		BudgetLamports: 10_000,
+	}
+
+	newSwapper := func(
+		t *testing.T,
+		swapReq *jupiter.SwapRequest,
+		waited *[]waitedTx,
+		policy swap.EscalationPolicy,
+		outcomes ...monitorOutcome,
+	) swap.Swapper {
+		t.Helper()
+
+		s, err := swap.NewSwapper(
+			jupiterMock{lastSwapRequest: swapReq},
+			solanaClientMock{},
+			testUserPublicKey,
+			swap.WithEscalation(monitorMock{outcomes: outcomes, waited: waited}, policy),
+		)
+		require.NoError(t, err)
+
+		return s
+	}
+
+	t.Run("invalid policy", func(t *testing.T) {
+		_, err := swap.NewSwapper(jupiterMock{}, solanaClientMock{}, testUserPublicKey, swap.WithEscalation(nil, policy))
+		require.EqualError(t, err, "could not apply option: monitor is required")
+
+		_, err = swap.NewSwapper(jupiterMock{}, solanaClientMock{}, testUserPublicKey,
+			swap.WithEscalation(monitorMock{}, swap.EscalationPolicy{BudgetLamports: 1}))
+		require.EqualError(t, err, "could not apply option: fee schedule is required")
+
+		_, err = swap.NewSwapper(jupiterMock{}, solanaClientMock{}, testUserPublicKey,
+			swap.WithEscalation(monitorMock{}, swap.EscalationPolicy{Schedule: policy.Schedule}))
+		require.EqualError(t, err, "could not apply option: escalation budget must be positive")
+	})
+
+	t.Run("lands at the first attempt", func(t *testing.T) {
+		var swapReq jupiter.SwapRequest
+		var waited []waitedTx
+
+		res, err := newSwapper(t, &swapReq, &waited, policy, landed).Swap(context.TODO(), req)
+		require.NoError(t, err)
+		require.Len(t, res.Attempts, 1)
+		require.Equal(t, jupSolana.TxID(testSignature), res.Attempts[0].TxID)
+		require.Equal(t, uint64(1_000), res.Attempts[0].FeeLamports)
+		require.Equal(t, res.Quote, res.Attempts[0].Quote)
+		require.NoError(t, res.Attempts[0].Err)
+
+		require.Equal(t, []waitedTx{{status: jupSolana.CommitmentConfirmed, lastValidBlockHeight: 123}}, waited)
+
+		fee := swapReq.PrioritizationFeeLamports.PriorityLevelWithMaxLamports
+		require.Equal(t, uint64(1_000), *fee.MaxLamports)
+		require.Equal(t, jupiter.VeryHigh, *fee.PriorityLevel)
+		require.Nil(t, swapReq.PrioritizationFeeLamports.JitoTipLamports)
+	})
+
+	t.Run("raise the fee when the blockhash expires", func(t *testing.T) {
+		var swapReq jupiter.SwapRequest
+		var waited []waitedTx
+
+		res, err := newSwapper(t, &swapReq, &waited, policy, expired, expired, landed).Swap(context.TODO(), req)
+		require.NoError(t, err)
+		require.Len(t, res.Attempts, 3)
+		require.Equal(t, []uint64{1_000, 1_500, 2_000}, []uint64{
+			res.Attempts[0].FeeLamports, res.Attempts[1].FeeLamports, res.Attempts[2].FeeLamports,
+		})
+		require.ErrorIs(t, res.Attempts[0].Err, jupSolana.ErrBlockhashExpired)
+		require.ErrorIs(t, res.Attempts[1].Err, jupSolana.ErrBlockhashExpired)
+		require.NoError(t, res.Attempts[2].Err)
+
+		require.Equal(t, uint64(2_000), *swapReq.PrioritizationFeeLamports.PriorityLevelWithMaxLamports.MaxLamports)
+	})
+
+	t.Run("jito tip", func(t *testing.T) {
+		var swapReq jupiter.SwapRequest
+		var waited []waitedTx
+
+		jito := policy
+		jito.JitoTip = true
+
+		_, err := newSwapper(t, &swapReq, &waited, jito, expired, landed).Swap(context.TODO(), req)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1_500), *swapReq.PrioritizationFeeLamports.JitoTipLamports)
+		require.Nil(t, swapReq.PrioritizationFeeLamports.PriorityLevelWithMaxLamports)
+	})
+
+	t.Run("stop when the budget is exhausted", func(t *testing.T) {
+		var waited []waitedTx
+
+		tight := policy
+		tight.BudgetLamports = 2_600
+
+		res, err := newSwapper(t, nil, &waited, tight, expired, expired, landed).Swap(context.TODO(), req)
+		require.ErrorIs(t, err, swap.ErrEscalationBudgetExhausted)
+		require.EqualError(t, err,
+			"escalation budget exhausted: 2500 of 2600 lamports offered in 2 attempts, the next one needs 2000")
+		require.Len(t, res.Attempts, 2)
+	})
+
+	t.Run("stop on an instruction error", func(t *testing.T) {
+		var waited []waitedTx
+
+		failed := monitorOutcome{resp: jupSolana.MonitorResponse{Ok: true, InstructionErr: errors.New("slippage")}}
+
+		res, err := newSwapper(t, nil, &waited, policy, failed, landed).Swap(context.TODO(), req)
+		require.ErrorIs(t, err, swap.ErrSwapFailed)
+		require.EqualError(t, err, "swap transaction failed: slippage")
+		require.Len(t, res.Attempts, 1)
+		require.Len(t, waited, 1)
+	})
+
+	t.Run("stop on a monitor error", func(t *testing.T) {
+		var waited []waitedTx
+
+		broken := monitorOutcome{err: errors.New("mocked error")}
+
+		_, err := newSwapper(t, nil, &waited, policy, broken).Swap(context.TODO(), req)
+		require.EqualError(t, err, "could not wait for swap transaction: mocked error")
+	})
+}
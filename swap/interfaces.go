@@ -0,0 +1,37 @@
+package swap
+
+import (
+	"context"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"github.com/ilkamo/jupiter-go/jupiter"
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+)
+
+type rpcService interface {
+	GetAccountInfoWithOpts(
+		ctx context.Context,
+		account solana.PublicKey,
+		opts *rpc.GetAccountInfoOpts,
+	) (*rpc.GetAccountInfoResult, error)
+}
+
+type RiskCheck interface {
+	Check(context.Context, string) (RiskReport, error)
+}
+
+type Swapper interface {
+	Swap(context.Context, Request) (Result, error)
+}
+
+type Builder interface {
+	Build(context.Context, BuildRequest) (BuildResult, error)
+}
+
+type Verifier interface {
+	jupSolana.TransactionVerifier
+	Verify(context.Context, solana.Transaction, jupiter.QuoteResponse) error
+	ForQuote(jupiter.QuoteResponse) jupSolana.TransactionVerifier
+}
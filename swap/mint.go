@@ -0,0 +1,117 @@
+package swap
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+const (
+	mintSize = 82
+
+	// Token-2022 accounts are padded to the size of a token account before the
+	// account type byte and the TLV encoded extensions.
+	token2022AccountTypeOffset = 165
+	token2022AccountTypeMint   = 1
+
+	extensionPermanentDelegate = 12
+	extensionTransferHook      = 14
+)
+
+// mint is the subset of the SPL Token (and Token-2022) mint state used by the risk check.
+type mint struct {
+	mintAuthority       *solana.PublicKey
+	supply              uint64
+	decimals            uint8
+	freezeAuthority     *solana.PublicKey
+	permanentDelegate   *solana.PublicKey
+	transferHookProgram *solana.PublicKey
+}
+
+func parseMint(owner solana.PublicKey, data []byte) (mint, error) {
+	if !owner.Equals(solana.TokenProgramID) && !owner.Equals(solana.Token2022ProgramID) {
+		return mint{}, fmt.Errorf("account is not owned by a token program: %s", owner)
+	}
+
+	if len(data) < mintSize {
+		return mint{}, fmt.Errorf("invalid mint account size: %d", len(data))
+	}
+
+	if data[45] != 1 {
+		return mint{}, fmt.Errorf("mint is not initialized")
+	}
+
+	m := mint{
+		mintAuthority:   parseCOptionKey(data[0:36]),
+		supply:          binary.LittleEndian.Uint64(data[36:44]),
+		decimals:        data[44],
+		freezeAuthority: parseCOptionKey(data[46:82]),
+	}
+
+	if !owner.Equals(solana.Token2022ProgramID) || len(data) <= token2022AccountTypeOffset {
+		return m, nil
+	}
+
+	if data[token2022AccountTypeOffset] != token2022AccountTypeMint {
+		return mint{}, fmt.Errorf("account is not a mint")
+	}
+
+	if err := m.parseExtensions(data[token2022AccountTypeOffset+1:]); err != nil {
+		return mint{}, err
+	}
+
+	return m, nil
+}
+
+func (m *mint) parseExtensions(tlv []byte) error {
+	for len(tlv) >= 4 {
+		extType := binary.LittleEndian.Uint16(tlv[0:2])
+		extLen := int(binary.LittleEndian.Uint16(tlv[2:4]))
+		tlv = tlv[4:]
+
+		if extType == 0 {
+			return nil
+		}
+
+		if extLen > len(tlv) {
+			return fmt.Errorf("invalid extension %d length: %d", extType, extLen)
+		}
+
+		value := tlv[:extLen]
+		tlv = tlv[extLen:]
+
+		switch extType {
+		case extensionPermanentDelegate:
+			if len(value) >= 32 {
+				m.permanentDelegate = parseNonZeroKey(value[0:32])
+			}
+		case extensionTransferHook:
+			// authority (32 bytes) followed by the hook program id (32 bytes).
+			if len(value) >= 64 {
+				m.transferHookProgram = parseNonZeroKey(value[32:64])
+			}
+		}
+	}
+
+	return nil
+}
+
+func parseCOptionKey(b []byte) *solana.PublicKey {
+	if binary.LittleEndian.Uint32(b[0:4]) == 0 {
+		return nil
+	}
+
+	key := solana.PublicKeyFromBytes(b[4:36])
+
+	return &key
+}
+
+func parseNonZeroKey(b []byte) *solana.PublicKey {
+	key := solana.PublicKeyFromBytes(b)
+	if key.IsZero() {
+		return nil
+	}
+
+	return &key
+}
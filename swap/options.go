@@ -0,0 +1,175 @@
+package swap
+
+import (
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+
+	"github.com/ilkamo/jupiter-go/jupiter"
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+)
+
+// RiskCheckOption is a function that allows to specify options for the risk check.
+type RiskCheckOption func(*riskCheck) error
+
+// WithRiskCheckRPC sets the RPC service used by the risk check to fetch mint accounts.
+func WithRiskCheckRPC(clientRPC rpcService) RiskCheckOption {
+	return func(r *riskCheck) error {
+		r.clientRPC = clientRPC
+		return nil
+	}
+}
+
+// WithProbe sets the input mint and the raw amount used for the liquidity probe quote.
+func WithProbe(inputMint string, amount uint64) RiskCheckOption {
+	return func(r *riskCheck) error {
+		r.probeMint = inputMint
+		r.probeAmount = amount
+		return nil
+	}
+}
+
+// SwapperOption is a function that allows to specify options for the swapper.
+type SwapperOption func(*swapper) error
+
+// WithRiskPolicy makes the swapper check the output mint of every quote and refuse
+// to build the swap when the policy is violated.
+func WithRiskPolicy(check RiskCheck, policy RiskPolicy) SwapperOption {
+	return func(s *swapper) error {
+		s.riskCheck = check
+		s.riskPolicy = policy
+		return nil
+	}
+}
+
+// WithVerifier makes the swapper verify every swap transaction against its quote before sending it.
+func WithVerifier(v Verifier) SwapperOption {
+	return func(s *swapper) error {
+		s.verifier = v
+		return nil
+	}
+}
+
+// WithEscalation makes the swapper wait for every swap with the monitor, which needs a block height source, and
+// build and send it again with a higher fee, following the policy, when its blockhash expires before it lands.
+func WithEscalation(monitor jupSolana.Monitor, policy EscalationPolicy) SwapperOption {
+	return func(s *swapper) error {
+		if monitor == nil {
+			return fmt.Errorf("monitor is required")
+		}
+
+		if policy.Schedule == nil {
+			return fmt.Errorf("fee schedule is required")
+		}
+
+		if policy.BudgetLamports == 0 {
+			return fmt.Errorf("escalation budget must be positive")
+		}
+
+		if policy.PriorityLevel == "" {
+			policy.PriorityLevel = jupiter.VeryHigh
+		}
+
+		if policy.Commitment == (jupSolana.CommitmentStatus{}) {
+			policy.Commitment = jupSolana.CommitmentConfirmed
+		}
+
+		s.monitor = monitor
+		s.escalation = &policy
+
+		return nil
+	}
+}
+
+// WithRequote makes the swapper quote and execute a swap again when it fails on slippage, widening the slippage or
+// enabling dynamic slippage as set by the policy. Without an escalation policy the swapper does not wait for swap
+// transactions, so only the failures of the preflight simulation are seen.
+func WithRequote(policy RequotePolicy) SwapperOption {
+	return func(s *swapper) error {
+		if policy.MaxRequotes <= 0 {
+			return fmt.Errorf("max re-quotes must be positive")
+		}
+
+		if policy.SlippageStepBps > 0 && policy.MaxSlippageBps == 0 {
+			return fmt.Errorf("max slippage is required to widen the slippage")
+		}
+
+		s.requote = &policy
+
+		return nil
+	}
+}
+
+// BuilderOption is a function that allows to specify options for the builder.
+type BuilderOption func(*builder) error
+
+// WithShrinkPolicy sets how the builder restricts the quote of a transaction that is too large.
+// MaxAccounts must be positive and decreasing.
+func WithShrinkPolicy(policy ShrinkPolicy) BuilderOption {
+	return func(b *builder) error {
+		for i, maxAccounts := range policy.MaxAccounts {
+			if maxAccounts == 0 {
+				return fmt.Errorf("max accounts must be positive")
+			}
+
+			if i > 0 && maxAccounts >= policy.MaxAccounts[i-1] {
+				return fmt.Errorf("max accounts must be decreasing")
+			}
+		}
+
+		b.policy = policy
+
+		return nil
+	}
+}
+
+// VerifierOption is a function that allows to specify options for the verifier.
+type VerifierOption func(*verifier) error
+
+// WithVerifierRPC sets the RPC service used by the verifier to resolve address lookup tables.
+func WithVerifierRPC(clientRPC rpcService) VerifierOption {
+	return func(v *verifier) error {
+		v.clientRPC = clientRPC
+		return nil
+	}
+}
+
+// WithAllowedPrograms allows additional programs to be invoked by the verified transactions.
+func WithAllowedPrograms(programIDs ...string) VerifierOption {
+	return func(v *verifier) error {
+		for _, id := range programIDs {
+			pk, err := solana.PublicKeyFromBase58(id)
+			if err != nil {
+				return fmt.Errorf("invalid program id %s: %w", id, err)
+			}
+			v.allowedPrograms[pk] = struct{}{}
+		}
+		return nil
+	}
+}
+
+// WithMaxSlippageBps accepts route instructions whose slippage differs from the quote, as it happens
+// with dynamic slippage, as long as it does not exceed maxSlippageBps.
+func WithMaxSlippageBps(maxSlippageBps uint64) VerifierOption {
+	return func(v *verifier) error {
+		v.maxSlippageBps = &maxSlippageBps
+		return nil
+	}
+}
+
+// WithMaxPriorityFee sets the maximum lamports a transaction can pay for its compute unit price, 0.01 SOL by default.
+// A transaction without a compute unit limit is assumed to use the maximum of 1.4M compute units.
+func WithMaxPriorityFee(lamports uint64) VerifierOption {
+	return func(v *verifier) error {
+		v.maxPriorityFee = lamports
+		return nil
+	}
+}
+
+// WithMaxTip sets the maximum lamports a transaction can transfer to Jito tip accounts, 0.01 SOL by default.
+func WithMaxTip(lamports uint64) VerifierOption {
+	return func(v *verifier) error {
+		v.maxTip = lamports
+		return nil
+	}
+}
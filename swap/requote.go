@@ -0,0 +1,71 @@
+package swap
+
+import (
+	"errors"
+
+	"github.com/ilkamo/jupiter-go/jupiter/aggregator"
+	"github.com/ilkamo/jupiter-go/solana"
+)
+
+// SlippageToleranceExceeded is the error code of the Jupiter aggregator when the output of a swap is below the
+// minimum of its quote.
+const SlippageToleranceExceeded uint32 = 6001
+
+// ErrSlippageExceeded is returned when a swap still fails on slippage after the re-quotes of its RequotePolicy.
+var ErrSlippageExceeded = errors.New("slippage tolerance exceeded")
+
+// RequotePolicy defines how a swap that failed on slippage is quoted and executed again.
+type RequotePolicy struct {
+	// MaxRequotes is how many times a swap is quoted again.
+	MaxRequotes int
+	// SlippageStepBps widens the slippage of every new quote, up to MaxSlippageBps.
+	SlippageStepBps uint64
+	// MaxSlippageBps is the hard cap of the widened slippage. A slippage already above it is kept, not widened.
+	MaxSlippageBps uint64
+	// DynamicSlippage lets Jupiter estimate the slippage of the new quotes and swaps.
+	DynamicSlippage bool
+}
+
+// isSlippageError reports whether a swap failed because its output was below the minimum of its quote:
+// the Jupiter aggregator instruction of the transaction failed with SlippageToleranceExceeded. Other programs
+// use the same code for their own errors.
+func isSlippageError(err error, swapTransaction string) bool {
+	code, ok := solana.CustomErrorCode(err)
+	if !ok || code != SlippageToleranceExceeded {
+		return false
+	}
+
+	index, ok := solana.InstructionErrorIndex(err)
+	if !ok {
+		return false
+	}
+
+	tx, txErr := solana.NewTransactionFromBase64(swapTransaction)
+	if txErr != nil || index >= len(tx.Message.Instructions) {
+		return false
+	}
+
+	programID, txErr := tx.Message.ResolveProgramIDIndex(tx.Message.Instructions[index].ProgramIDIndex)
+
+	return txErr == nil && programID.Equals(aggregator.ProgramID)
+}
+
+// next returns the request of the new quote, widening the slippage of the previous quote.
+func (p RequotePolicy) next(req Request, previousSlippageBps uint64) Request {
+	if p.SlippageStepBps > 0 {
+		slippage := previousSlippageBps + p.SlippageStepBps
+		if slippage > p.MaxSlippageBps {
+			slippage = max(previousSlippageBps, p.MaxSlippageBps)
+		}
+
+		req.Quote.SlippageBps = &slippage
+	}
+
+	if p.DynamicSlippage {
+		dynamic := true
+		req.Quote.DynamicSlippage = &dynamic
+		req.Swap.DynamicSlippage = &dynamic
+	}
+
+	return req
+}
@@ -0,0 +1,254 @@
+package swap_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilkamo/jupiter-go/jupiter"
+	"github.com/ilkamo/jupiter-go/jupiter/aggregator"
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+	"github.com/ilkamo/jupiter-go/swap"
+)
+
+// failingSendMock fails its sends with errs in order, then succeeds.
+type failingSendMock struct {
+	solanaClientMock
+	errs []error
+	sent *int
+}
+
+func (s failingSendMock) SendTransaction(
+	ctx context.Context,
+	tx *solana.Transaction,
+	opts jupSolana.SendOptions,
+) (jupSolana.SendResult, error) {
+	*s.sent++
+
+	if *s.sent <= len(s.errs) {
+		return jupSolana.SendResult{}, s.errs[*s.sent-1]
+	}
+
+	return s.solanaClientMock.SendTransaction(ctx, tx, opts)
+}
+
+// slippageErr is the error of an RPC node rejecting a swap whose preflight simulation failed on slippage.
+var slippageErr = &jsonrpc.RPCError{
+	Code:    -32002,
+	Message: "Transaction simulation failed: Error processing Instruction 2: custom program error: 0x1771",
+	Data: map[string]any{
+		"err": map[string]any{"InstructionError": []any{2.0, map[string]any{"Custom": 6001.0}}},
+	},
+}
+
+// testJupiterSwapTx builds a swap transaction whose instruction 2, the one of slippageErr, is for the Jupiter
+// aggregator.
+func testJupiterSwapTx(t *testing.T) string {
+	t.Helper()
+
+	payer := solana.MustPublicKeyFromBase58(testUserPublicKey)
+	transfer := system.NewTransferInstruction(1000, payer, solana.NewWallet().PublicKey()).Build()
+
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{
+			transfer,
+			transfer,
+			solana.NewInstruction(aggregator.ProgramID, solana.AccountMetaSlice{solana.Meta(payer)}, []byte{1}),
+		},
+		solana.Hash{1},
+		solana.TransactionPayer(payer),
+	)
+	require.NoError(t, err)
+
+	txBase64, err := tx.ToBase64()
+	require.NoError(t, err)
+
+	return txBase64
+}
+
+func TestSwapper_Requote(t *testing.T) {
+	slippage := uint64(50)
+	swapTx := testJupiterSwapTx(t)
+
+	req := swap.Request{
+		Quote: jupiter.QuoteGetParams{
+			InputMint:   swap.WrappedSolMint,
+			OutputMint:  testMint,
+			Amount:      100000,
+			SlippageBps: &slippage,
+		},
+	}
+
+	policy := swap.RequotePolicy{MaxRequotes: 3, SlippageStepBps: 50, MaxSlippageBps: 120}
+
+	newSwapper := func(
+		t *testing.T,
+		quoteParams *[]jupiter.QuoteGetParams,
+		swapReq *jupiter.SwapRequest,
+		policy swap.RequotePolicy,
+		sendErrs ...error,
+	) swap.Swapper {
+		t.Helper()
+
+		var sent int
+
+		s, err := swap.NewSwapper(
+			jupiterMock{quoteParams: quoteParams, lastSwapRequest: swapReq, swapTx: swapTx},
+			failingSendMock{errs: sendErrs, sent: &sent},
+			testUserPublicKey,
+			swap.WithRequote(policy),
+		)
+		require.NoError(t, err)
+
+		return s
+	}
+
+	slippages := func(params []jupiter.QuoteGetParams) []uint64 {
+		var bps []uint64
+		for _, p := range params {
+			bps = append(bps, *p.SlippageBps)
+		}
+
+		return bps
+	}
+
+	t.Run("invalid policy", func(t *testing.T) {
+		_, err := swap.NewSwapper(jupiterMock{}, solanaClientMock{}, testUserPublicKey,
+			swap.WithRequote(swap.RequotePolicy{}))
+		require.EqualError(t, err, "could not apply option: max re-quotes must be positive")
+
+		_, err = swap.NewSwapper(jupiterMock{}, solanaClientMock{}, testUserPublicKey,
+			swap.WithRequote(swap.RequotePolicy{MaxRequotes: 1, SlippageStepBps: 50}))
+		require.EqualError(t, err, "could not apply option: max slippage is required to widen the slippage")
+	})
+
+	t.Run("widen the slippage up to the cap", func(t *testing.T) {
+		var quoteParams []jupiter.QuoteGetParams
+
+		s := newSwapper(t, &quoteParams, nil, policy, slippageErr, slippageErr, slippageErr)
+
+		res, err := s.Swap(context.TODO(), req)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{50, 100, 120, 120}, slippages(quoteParams))
+		require.Equal(t, uint64(120), res.Quote.SlippageBps)
+		require.Equal(t, jupSolana.TxID(testSignature), res.TxID)
+
+		require.Len(t, res.Attempts, 4)
+		for i, bps := range []uint64{50, 100, 120} {
+			require.Equal(t, bps, res.Attempts[i].Quote.SlippageBps)
+			require.ErrorIs(t, res.Attempts[i].Err, slippageErr)
+			require.Empty(t, res.Attempts[i].TxID)
+		}
+
+		require.NoError(t, res.Attempts[3].Err)
+		require.Equal(t, jupSolana.TxID(testSignature), res.Attempts[3].TxID)
+
+		require.Equal(t, uint64(50), slippage, "the request of the caller must not change")
+	})
+
+	t.Run("keep a slippage above the cap", func(t *testing.T) {
+		var quoteParams []jupiter.QuoteGetParams
+
+		high := uint64(200)
+		highReq := req
+		highReq.Quote.SlippageBps = &high
+
+		_, err := newSwapper(t, &quoteParams, nil, policy, slippageErr).Swap(context.TODO(), highReq)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{200, 200}, slippages(quoteParams))
+	})
+
+	t.Run("dynamic slippage", func(t *testing.T) {
+		var quoteParams []jupiter.QuoteGetParams
+		var swapReq jupiter.SwapRequest
+
+		dynamic := swap.RequotePolicy{MaxRequotes: 1, DynamicSlippage: true}
+
+		res, err := newSwapper(t, &quoteParams, &swapReq, dynamic, slippageErr).Swap(context.TODO(), req)
+		require.NoError(t, err)
+		require.Len(t, quoteParams, 2)
+		require.Nil(t, quoteParams[0].DynamicSlippage)
+		require.True(t, *quoteParams[1].DynamicSlippage)
+		require.Equal(t, uint64(50), *quoteParams[1].SlippageBps)
+		require.True(t, *swapReq.DynamicSlippage)
+
+		require.False(t, res.Attempts[0].DynamicSlippage)
+		require.True(t, res.Attempts[1].DynamicSlippage)
+	})
+
+	t.Run("stop after the max re-quotes", func(t *testing.T) {
+		var quoteParams []jupiter.QuoteGetParams
+
+		tight := policy
+		tight.MaxRequotes = 1
+
+		res, err := newSwapper(t, &quoteParams, nil, tight, slippageErr, slippageErr).Swap(context.TODO(), req)
+		require.ErrorIs(t, err, swap.ErrSlippageExceeded)
+		require.ErrorIs(t, err, slippageErr)
+		require.ErrorContains(t, err, "slippage tolerance exceeded after 1 re-quotes: ")
+		require.Len(t, quoteParams, 2)
+		require.Len(t, res.Attempts, 2)
+	})
+
+	t.Run("do not re-quote other errors", func(t *testing.T) {
+		var quoteParams []jupiter.QuoteGetParams
+
+		res, err := newSwapper(t, &quoteParams, nil, policy, errors.New("mocked error")).Swap(context.TODO(), req)
+		require.EqualError(t, err, "mocked error")
+		require.Len(t, quoteParams, 1)
+		require.Len(t, res.Attempts, 1)
+	})
+
+	t.Run("do not re-quote the same error code of another program", func(t *testing.T) {
+		var quoteParams []jupiter.QuoteGetParams
+
+		transferErr := &jupSolana.InstructionError{
+			Err: map[string]any{"InstructionError": []any{0.0, map[string]any{"Custom": 6001.0}}},
+		}
+
+		_, err := newSwapper(t, &quoteParams, nil, policy, transferErr).Swap(context.TODO(), req)
+		require.ErrorIs(t, err, transferErr)
+		require.NotErrorIs(t, err, swap.ErrSlippageExceeded)
+		require.Len(t, quoteParams, 1)
+	})
+
+	t.Run("re-quote a swap that landed with a slippage error", func(t *testing.T) {
+		var quoteParams []jupiter.QuoteGetParams
+		var waited []waitedTx
+
+		failed := monitorOutcome{resp: jupSolana.MonitorResponse{
+			Ok: true,
+			InstructionErr: &jupSolana.InstructionError{
+				Err: map[string]any{"InstructionError": []any{2.0, map[string]any{"Custom": 6001.0}}},
+			},
+		}}
+
+		escalation := swap.EscalationPolicy{
+			Schedule:       swap.LinearFeeSchedule{InitialLamports: 1_000, StepLamports: 500},
+			BudgetLamports: 10_000,
+		}
+
+		var sent int
+
+		s, err := swap.NewSwapper(
+			jupiterMock{quoteParams: &quoteParams, swapTx: swapTx},
+			failingSendMock{sent: &sent},
+			testUserPublicKey,
+			swap.WithEscalation(monitorMock{outcomes: []monitorOutcome{failed, landed}, waited: &waited}, escalation),
+			swap.WithRequote(policy),
+		)
+		require.NoError(t, err)
+
+		res, err := s.Swap(context.TODO(), req)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{50, 100}, slippages(quoteParams))
+		require.Len(t, res.Attempts, 2)
+		require.ErrorIs(t, res.Attempts[0].Err, failed.resp.InstructionErr)
+		require.Equal(t, []uint64{1_000, 1_000}, []uint64{res.Attempts[0].FeeLamports, res.Attempts[1].FeeLamports})
+	})
+}
@@ -0,0 +1,249 @@
+package swap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/shopspring/decimal"
+
+	"github.com/ilkamo/jupiter-go/jupiter"
+)
+
+const (
+	// WrappedSolMint is the mint of wrapped SOL.
+	WrappedSolMint = "So11111111111111111111111111111111111111112"
+
+	defaultProbeMint   = WrappedSolMint
+	defaultProbeAmount = uint64(100_000_000) // 0.1 SOL
+
+	// noRouteErrorCode is the error code of the 400 Bad Request Jupiter answers when no route exists for the pair.
+	noRouteErrorCode = "COULD_NOT_FIND_ANY_ROUTE"
+)
+
+// ErrRiskPolicyViolation is returned when a mint does not satisfy a RiskPolicy.
+var ErrRiskPolicyViolation = errors.New("risk policy violation")
+
+// RiskReport describes the on-chain and liquidity properties of a mint.
+type RiskReport struct {
+	Mint         string
+	TokenProgram string
+	Decimals     uint8
+	Supply       uint64
+	// MintAuthority is set if new tokens can still be minted.
+	MintAuthority *string
+	// FreezeAuthority is set if token accounts of the mint can be frozen.
+	FreezeAuthority *string
+	// PermanentDelegate is set if a Token-2022 permanent delegate can move or burn any balance.
+	PermanentDelegate *string
+	// TransferHookProgram is set if a Token-2022 transfer hook runs on every transfer.
+	TransferHookProgram *string
+	// HasRoute is false if Jupiter could not find a route for the probe quote.
+	HasRoute bool
+	// PriceImpactPct is the price impact of the probe quote, as returned by Jupiter.
+	PriceImpactPct decimal.Decimal
+	// ProbeMint and ProbeAmount describe the probe quote.
+	ProbeMint   string
+	ProbeAmount uint64
+}
+
+// RiskPolicy defines which mint properties are acceptable before swapping into a mint.
+type RiskPolicy struct {
+	AllowMintAuthority     bool
+	AllowFreezeAuthority   bool
+	AllowPermanentDelegate bool
+	AllowTransferHook      bool
+	AllowNoRoute           bool
+	// MaxPriceImpactPct is the maximum accepted price impact of the probe quote.
+	// The check is disabled when zero.
+	MaxPriceImpactPct decimal.Decimal
+}
+
+// DefaultRiskPolicy rejects mints with any authority, permanent delegate or transfer hook set,
+// mints without a route, and probe quotes with a price impact above 5%.
+var DefaultRiskPolicy = RiskPolicy{
+	MaxPriceImpactPct: decimal.NewFromFloat(0.05),
+}
+
+// Violations returns the list of rules of the policy the report does not satisfy.
+func (p RiskPolicy) Violations(r RiskReport) []string {
+	var violations []string
+
+	if r.MintAuthority != nil && !p.AllowMintAuthority {
+		violations = append(violations, fmt.Sprintf("mint authority is set (%s)", *r.MintAuthority))
+	}
+
+	if r.FreezeAuthority != nil && !p.AllowFreezeAuthority {
+		violations = append(violations, fmt.Sprintf("freeze authority is set (%s)", *r.FreezeAuthority))
+	}
+
+	if r.PermanentDelegate != nil && !p.AllowPermanentDelegate {
+		violations = append(violations, fmt.Sprintf("permanent delegate is set (%s)", *r.PermanentDelegate))
+	}
+
+	if r.TransferHookProgram != nil && !p.AllowTransferHook {
+		violations = append(violations, fmt.Sprintf("transfer hook is set (%s)", *r.TransferHookProgram))
+	}
+
+	if !r.HasRoute && !p.AllowNoRoute {
+		violations = append(violations, "no route found for probe quote")
+	}
+
+	if r.HasRoute && p.MaxPriceImpactPct.IsPositive() && r.PriceImpactPct.Abs().GreaterThan(p.MaxPriceImpactPct) {
+		violations = append(violations, fmt.Sprintf(
+			"probe price impact %s exceeds %s", r.PriceImpactPct, p.MaxPriceImpactPct,
+		))
+	}
+
+	return violations
+}
+
+// Evaluate returns an error wrapping ErrRiskPolicyViolation if the report violates the policy.
+func (p RiskPolicy) Evaluate(r RiskReport) error {
+	violations := p.Violations(r)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w for mint %s: %s", ErrRiskPolicyViolation, r.Mint, strings.Join(violations, "; "))
+}
+
+type riskCheck struct {
+	jupClient   jupiter.ClientWithResponsesInterface
+	clientRPC   rpcService
+	probeMint   string
+	probeAmount uint64
+}
+
+// NewRiskCheck creates a risk check that combines on-chain mint data, fetched from the given
+// RPC endpoint, with a Jupiter probe quote.
+func NewRiskCheck(
+	jupClient jupiter.ClientWithResponsesInterface,
+	rpcEndpoint string,
+	opts ...RiskCheckOption,
+) (RiskCheck, error) {
+	if jupClient == nil {
+		return nil, fmt.Errorf("jupiter client is required")
+	}
+
+	r := &riskCheck{
+		jupClient:   jupClient,
+		probeMint:   defaultProbeMint,
+		probeAmount: defaultProbeAmount,
+	}
+
+	for _, opt := range opts {
+		if err := opt(r); err != nil {
+			return nil, fmt.Errorf("could not apply option: %w", err)
+		}
+	}
+
+	if r.clientRPC == nil {
+		if rpcEndpoint == "" {
+			return nil, fmt.Errorf("rpcEndpoint is required when no RPC service is provided")
+		}
+
+		r.clientRPC = rpc.New(rpcEndpoint)
+	}
+
+	return r, nil
+}
+
+// Check fetches the mint account and a probe quote into the mint and returns a report.
+func (r riskCheck) Check(ctx context.Context, mintAddress string) (RiskReport, error) {
+	mintPk, err := solana.PublicKeyFromBase58(mintAddress)
+	if err != nil {
+		return RiskReport{}, fmt.Errorf("could not parse mint public key: %w", err)
+	}
+
+	account, err := r.clientRPC.GetAccountInfoWithOpts(ctx, mintPk, &rpc.GetAccountInfoOpts{
+		Encoding:   solana.EncodingBase64,
+		Commitment: rpc.CommitmentConfirmed,
+	})
+	if err != nil {
+		return RiskReport{}, fmt.Errorf("could not get mint account: %w", err)
+	}
+
+	if account == nil || account.Value == nil {
+		return RiskReport{}, fmt.Errorf("could not get mint account: account not found")
+	}
+
+	m, err := parseMint(account.Value.Owner, account.Value.Data.GetBinary())
+	if err != nil {
+		return RiskReport{}, fmt.Errorf("could not parse mint account: %w", err)
+	}
+
+	report := RiskReport{
+		Mint:                mintAddress,
+		TokenProgram:        account.Value.Owner.String(),
+		Decimals:            m.decimals,
+		Supply:              m.supply,
+		MintAuthority:       keyString(m.mintAuthority),
+		FreezeAuthority:     keyString(m.freezeAuthority),
+		PermanentDelegate:   keyString(m.permanentDelegate),
+		TransferHookProgram: keyString(m.transferHookProgram),
+		ProbeMint:           r.probeMint,
+		ProbeAmount:         r.probeAmount,
+	}
+
+	if mintAddress == r.probeMint {
+		report.HasRoute = true
+		return report, nil
+	}
+
+	quoteResp, err := r.jupClient.QuoteGetWithResponse(ctx, &jupiter.QuoteGetParams{
+		InputMint:  r.probeMint,
+		OutputMint: mintAddress,
+		Amount:     r.probeAmount,
+	})
+	if err != nil {
+		return RiskReport{}, fmt.Errorf("could not get probe quote: %w", err)
+	}
+
+	if quoteResp.JSON200 == nil {
+		// Other failures, e.g. a rate limit, say nothing about the liquidity of the mint.
+		if isNoRoute(quoteResp) {
+			return report, nil
+		}
+
+		return RiskReport{}, fmt.Errorf("could not get probe quote: %s: %s", quoteResp.Status(), quoteResp.Body)
+	}
+
+	priceImpact, err := decimal.NewFromString(quoteResp.JSON200.PriceImpactPct)
+	if err != nil {
+		return RiskReport{}, fmt.Errorf("could not parse probe price impact: %w", err)
+	}
+
+	report.HasRoute = true
+	report.PriceImpactPct = priceImpact
+
+	return report, nil
+}
+
+// isNoRoute reports whether Jupiter could not find any route for the quote.
+func isNoRoute(resp *jupiter.QuoteGetResponse) bool {
+	if resp.StatusCode() != http.StatusBadRequest {
+		return false
+	}
+
+	var body struct {
+		ErrorCode string `json:"errorCode"`
+	}
+
+	return json.Unmarshal(resp.Body, &body) == nil && body.ErrorCode == noRouteErrorCode
+}
+
+func keyString(key *solana.PublicKey) *string {
+	if key == nil {
+		return nil
+	}
+
+	s := key.String()
+
+	return &s
+}
@@ -0,0 +1,222 @@
+package swap_test
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilkamo/jupiter-go/swap"
+)
+
+const (
+	testMint      = "JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN"
+	testAuthority = "BXzmfHxfEMcMj8hDccUNdrwXVNeybyfb2iV2nktE1VnJ"
+)
+
+type rpcMock struct {
+	owner           solana.PublicKey
+	data            []byte
+	shouldFailGetAI bool
+}
+
+func (r rpcMock) GetAccountInfoWithOpts(
+	_ context.Context,
+	_ solana.PublicKey,
+	_ *rpc.GetAccountInfoOpts,
+) (*rpc.GetAccountInfoResult, error) {
+	if r.shouldFailGetAI {
+		return nil, errors.New("mocked error")
+	}
+
+	return &rpc.GetAccountInfoResult{
+		Value: &rpc.Account{
+			Owner: r.owner,
+			Data:  rpc.DataBytesOrJSONFromBytes(r.data),
+		},
+	}, nil
+}
+
+type testMintState struct {
+	mintAuthority     *solana.PublicKey
+	freezeAuthority   *solana.PublicKey
+	permanentDelegate *solana.PublicKey
+	transferHook      *solana.PublicKey
+}
+
+func (m testMintState) bytes() []byte {
+	data := make([]byte, 82)
+	if m.mintAuthority != nil {
+		binary.LittleEndian.PutUint32(data[0:4], 1)
+		copy(data[4:36], m.mintAuthority[:])
+	}
+	binary.LittleEndian.PutUint64(data[36:44], 1_000_000)
+	data[44] = 6
+	data[45] = 1
+	if m.freezeAuthority != nil {
+		binary.LittleEndian.PutUint32(data[46:50], 1)
+		copy(data[50:82], m.freezeAuthority[:])
+	}
+
+	if m.permanentDelegate == nil && m.transferHook == nil {
+		return data
+	}
+
+	data = append(data, make([]byte, 165-82)...)
+	data = append(data, 1) // account type: mint
+
+	if m.permanentDelegate != nil {
+		data = binary.LittleEndian.AppendUint16(data, 12)
+		data = binary.LittleEndian.AppendUint16(data, 32)
+		data = append(data, m.permanentDelegate[:]...)
+	}
+
+	if m.transferHook != nil {
+		data = binary.LittleEndian.AppendUint16(data, 14)
+		data = binary.LittleEndian.AppendUint16(data, 64)
+		data = append(data, make([]byte, 32)...)
+		data = append(data, m.transferHook[:]...)
+	}
+
+	return data
+}
+
+func TestRiskCheck_Check(t *testing.T) {
+	authority := solana.MustPublicKeyFromBase58(testAuthority)
+
+	t.Run("risk check without rpc endpoint", func(t *testing.T) {
+		_, err := swap.NewRiskCheck(jupiterMock{}, "")
+		require.EqualError(t, err, "rpcEndpoint is required when no RPC service is provided")
+	})
+
+	t.Run("invalid mint", func(t *testing.T) {
+		rc, err := swap.NewRiskCheck(jupiterMock{}, "", swap.WithRiskCheckRPC(rpcMock{}))
+		require.NoError(t, err)
+
+		_, err = rc.Check(context.TODO(), "invalid mint")
+		require.ErrorContains(t, err, "could not parse mint public key")
+	})
+
+	t.Run("error when getting the mint account", func(t *testing.T) {
+		rc, err := swap.NewRiskCheck(jupiterMock{}, "", swap.WithRiskCheckRPC(rpcMock{shouldFailGetAI: true}))
+		require.NoError(t, err)
+
+		_, err = rc.Check(context.TODO(), testMint)
+		require.EqualError(t, err, "could not get mint account: mocked error")
+	})
+
+	t.Run("account not owned by a token program", func(t *testing.T) {
+		rc, err := swap.NewRiskCheck(jupiterMock{}, "", swap.WithRiskCheckRPC(rpcMock{
+			owner: solana.SystemProgramID,
+			data:  testMintState{}.bytes(),
+		}))
+		require.NoError(t, err)
+
+		_, err = rc.Check(context.TODO(), testMint)
+		require.ErrorContains(t, err, "account is not owned by a token program")
+	})
+
+	t.Run("safe spl token mint", func(t *testing.T) {
+		rc, err := swap.NewRiskCheck(jupiterMock{priceImpactPct: "0.001"}, "", swap.WithRiskCheckRPC(rpcMock{
+			owner: solana.TokenProgramID,
+			data:  testMintState{}.bytes(),
+		}))
+		require.NoError(t, err)
+
+		report, err := rc.Check(context.TODO(), testMint)
+		require.NoError(t, err)
+		require.Equal(t, uint8(6), report.Decimals)
+		require.Equal(t, uint64(1_000_000), report.Supply)
+		require.Nil(t, report.MintAuthority)
+		require.Nil(t, report.FreezeAuthority)
+		require.True(t, report.HasRoute)
+		require.Equal(t, "0.001", report.PriceImpactPct.String())
+		require.NoError(t, swap.DefaultRiskPolicy.Evaluate(report))
+	})
+
+	t.Run("token-2022 mint with authorities and extensions", func(t *testing.T) {
+		rc, err := swap.NewRiskCheck(jupiterMock{priceImpactPct: "0.2"}, "", swap.WithRiskCheckRPC(rpcMock{
+			owner: solana.Token2022ProgramID,
+			data: testMintState{
+				mintAuthority:     &authority,
+				freezeAuthority:   &authority,
+				permanentDelegate: &authority,
+				transferHook:      &authority,
+			}.bytes(),
+		}))
+		require.NoError(t, err)
+
+		report, err := rc.Check(context.TODO(), testMint)
+		require.NoError(t, err)
+		require.Equal(t, testAuthority, *report.MintAuthority)
+		require.Equal(t, testAuthority, *report.FreezeAuthority)
+		require.Equal(t, testAuthority, *report.PermanentDelegate)
+		require.Equal(t, testAuthority, *report.TransferHookProgram)
+
+		require.Len(t, swap.DefaultRiskPolicy.Violations(report), 5)
+
+		err = swap.DefaultRiskPolicy.Evaluate(report)
+		require.ErrorIs(t, err, swap.ErrRiskPolicyViolation)
+	})
+
+	newRiskCheck := func(t *testing.T, jup jupiterMock) swap.RiskCheck {
+		t.Helper()
+
+		rc, err := swap.NewRiskCheck(jup, "", swap.WithRiskCheckRPC(rpcMock{
+			owner: solana.TokenProgramID,
+			data:  testMintState{}.bytes(),
+		}))
+		require.NoError(t, err)
+
+		return rc
+	}
+
+	t.Run("no route for probe quote", func(t *testing.T) {
+		rc := newRiskCheck(t, jupiterMock{
+			quoteStatusCode: http.StatusBadRequest,
+			quoteBody:       `{"error":"Could not find any route","errorCode":"COULD_NOT_FIND_ANY_ROUTE"}`,
+		})
+
+		report, err := rc.Check(context.TODO(), testMint)
+		require.NoError(t, err)
+		require.False(t, report.HasRoute)
+		require.Equal(t, []string{"no route found for probe quote"}, swap.DefaultRiskPolicy.Violations(report))
+	})
+
+	t.Run("other probe quote failures are errors", func(t *testing.T) {
+		for _, jup := range []jupiterMock{
+			{quoteStatusCode: http.StatusTooManyRequests, quoteBody: "rate limited"},
+			{quoteStatusCode: http.StatusUnauthorized},
+			{quoteStatusCode: http.StatusBadRequest, quoteBody: `{"errorCode":"TOKEN_NOT_TRADABLE"}`},
+		} {
+			_, err := newRiskCheck(t, jup).Check(context.TODO(), testMint)
+			require.ErrorContains(t, err, "could not get probe quote: "+http.StatusText(jup.quoteStatusCode))
+		}
+	})
+}
+
+func TestRiskPolicy_Violations(t *testing.T) {
+	authority := testAuthority
+
+	policy := swap.RiskPolicy{
+		AllowMintAuthority: true,
+		MaxPriceImpactPct:  decimal.NewFromFloat(0.1),
+	}
+
+	report := swap.RiskReport{
+		Mint:           testMint,
+		MintAuthority:  &authority,
+		HasRoute:       true,
+		PriceImpactPct: decimal.NewFromFloat(0.05),
+	}
+	require.Empty(t, policy.Violations(report))
+
+	report.PriceImpactPct = decimal.NewFromFloat(0.11)
+	require.Equal(t, []string{"probe price impact 0.11 exceeds 0.1"}, policy.Violations(report))
+}
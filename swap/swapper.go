@@ -0,0 +1,221 @@
+package swap
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ilkamo/jupiter-go/jupiter"
+	"github.com/ilkamo/jupiter-go/solana"
+)
+
+// Request describes a swap to execute.
+type Request struct {
+	// Quote holds the parameters used to get a quote from Jupiter.
+	Quote jupiter.QuoteGetParams
+	// Swap is used as a template for the /swap call.
+	// QuoteResponse and UserPublicKey are filled by the swapper.
+	Swap jupiter.SwapRequest
+	// Send holds the options the swap transaction is sent with.
+	Send solana.SendOptions
+}
+
+// Result holds the outcome of a swap.
+type Result struct {
+	Quote jupiter.QuoteResponse
+	Swap  jupiter.SwapResponse
+	TxID  solana.TxID
+	// LastValidBlockHeight is the last block height at which the swap transaction can land.
+	LastValidBlockHeight uint64
+	// Risk is filled when the swapper is configured with a risk policy.
+	Risk *RiskReport
+	// Attempts lists the swap transactions built, the last one last. There are several when the swapper escalates
+	// fees or re-quotes.
+	Attempts []Attempt
+}
+
+type swapper struct {
+	jupClient     jupiter.ClientWithResponsesInterface
+	solClient     solana.Client
+	userPublicKey string
+	riskCheck     RiskCheck
+	riskPolicy    RiskPolicy
+	verifier      Verifier
+	escalation    *EscalationPolicy
+	monitor       solana.Monitor
+	requote       *RequotePolicy
+}
+
+// NewSwapper creates a swapper that quotes with Jupiter, builds the swap transaction
+// for the given user public key and sends it on-chain with the Solana client.
+func NewSwapper(
+	jupClient jupiter.ClientWithResponsesInterface,
+	solClient solana.Client,
+	userPublicKey string,
+	opts ...SwapperOption,
+) (Swapper, error) {
+	if jupClient == nil {
+		return nil, fmt.Errorf("jupiter client is required")
+	}
+
+	if solClient == nil {
+		return nil, fmt.Errorf("solana client is required")
+	}
+
+	if userPublicKey == "" {
+		return nil, fmt.Errorf("userPublicKey is required")
+	}
+
+	s := &swapper{
+		jupClient:     jupClient,
+		solClient:     solClient,
+		userPublicKey: userPublicKey,
+	}
+
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, fmt.Errorf("could not apply option: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+// Swap gets a quote, checks the output mint against the risk policy if configured,
+// builds the swap transaction, verifies it against the quote if configured and sends it on-chain.
+// With an escalation policy, it waits for the swap to land and sends it again with a higher fee if it expires.
+// With a re-quote policy, it quotes and executes the swap again when it fails on slippage.
+func (s swapper) Swap(ctx context.Context, req Request) (Result, error) {
+	quote, err := s.quote(ctx, req.Quote)
+	if err != nil {
+		return Result{}, err
+	}
+
+	res := Result{Quote: quote}
+
+	if s.riskCheck != nil {
+		report, err := s.riskCheck.Check(ctx, quote.OutputMint)
+		if err != nil {
+			return res, fmt.Errorf("could not check output mint: %w", err)
+		}
+
+		res.Risk = &report
+
+		if err := s.riskPolicy.Evaluate(report); err != nil {
+			return res, err
+		}
+	}
+
+	for requotes := 0; ; requotes++ {
+		if s.escalation != nil {
+			res, err = s.escalate(ctx, req, quote, res)
+		} else {
+			res, err = s.execute(ctx, req, quote, res)
+		}
+
+		if err == nil || s.requote == nil || !isSlippageError(err, res.Swap.SwapTransaction) {
+			return res, err
+		}
+
+		if requotes == s.requote.MaxRequotes {
+			return res, fmt.Errorf("%w after %d re-quotes: %w", ErrSlippageExceeded, requotes, err)
+		}
+
+		req = s.requote.next(req, quote.SlippageBps)
+
+		quote, err = s.quote(ctx, req.Quote)
+		if err != nil {
+			return res, err
+		}
+	}
+}
+
+// execute builds the swap transaction of the quote, verifies it if configured and sends it on-chain, recording the
+// attempt in the result.
+func (s swapper) execute(ctx context.Context, req Request, quote jupiter.QuoteResponse, res Result) (Result, error) {
+	res.Quote = quote
+	res.Attempts = append(res.Attempts, Attempt{
+		Quote:           quote,
+		DynamicSlippage: req.Swap.DynamicSlippage != nil && *req.Swap.DynamicSlippage,
+	})
+	attempt := &res.Attempts[len(res.Attempts)-1]
+
+	fail := func(err error) (Result, error) {
+		attempt.Err = err
+		return res, err
+	}
+
+	swapResp, err := s.swap(ctx, req.Swap, quote)
+	if err != nil {
+		return fail(err)
+	}
+
+	res.Swap = swapResp
+
+	tx, err := solana.NewTransactionFromBase64(swapResp.SwapTransaction)
+	if err != nil {
+		return fail(fmt.Errorf("could not deserialize swap transaction: %w", err))
+	}
+
+	if s.verifier != nil {
+		if err := s.verifier.Verify(ctx, tx, quote); err != nil {
+			return fail(err)
+		}
+	}
+
+	sent, err := s.solClient.SendTransaction(ctx, &tx, req.Send)
+	if err != nil {
+		return fail(err)
+	}
+
+	res.TxID = sent.TxID
+	attempt.TxID = sent.TxID
+	res.LastValidBlockHeight = sent.LastValidBlockHeight
+
+	// The client does not know the last valid block height of the blockhash Jupiter placed in the transaction.
+	if res.LastValidBlockHeight == 0 {
+		res.LastValidBlockHeight = swapResp.LastValidBlockHeight
+	}
+
+	return res, nil
+}
+
+func (s swapper) quote(ctx context.Context, params jupiter.QuoteGetParams) (jupiter.QuoteResponse, error) {
+	if err := params.Validate(); err != nil {
+		return jupiter.QuoteResponse{}, fmt.Errorf("could not get quote: %w", err)
+	}
+
+	resp, err := s.jupClient.QuoteGetWithResponse(ctx, &params)
+	if err != nil {
+		return jupiter.QuoteResponse{}, fmt.Errorf("could not get quote: %w", err)
+	}
+
+	if resp.JSON200 == nil {
+		return jupiter.QuoteResponse{}, fmt.Errorf("could not get quote: %s: %s", resp.Status(), resp.Body)
+	}
+
+	return *resp.JSON200, nil
+}
+
+func (s swapper) swap(
+	ctx context.Context,
+	req jupiter.SwapRequest,
+	quote jupiter.QuoteResponse,
+) (jupiter.SwapResponse, error) {
+	req.QuoteResponse = quote
+	req.UserPublicKey = s.userPublicKey
+
+	if err := req.Validate(); err != nil {
+		return jupiter.SwapResponse{}, fmt.Errorf("could not get swap transaction: %w", err)
+	}
+
+	resp, err := s.jupClient.SwapPostWithResponse(ctx, req)
+	if err != nil {
+		return jupiter.SwapResponse{}, fmt.Errorf("could not get swap transaction: %w", err)
+	}
+
+	if resp.JSON200 == nil {
+		return jupiter.SwapResponse{}, fmt.Errorf("could not get swap transaction: %s: %s", resp.Status(), resp.Body)
+	}
+
+	return *resp.JSON200, nil
+}
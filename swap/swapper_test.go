@@ -0,0 +1,270 @@
+package swap_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilkamo/jupiter-go/jupiter"
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+	"github.com/ilkamo/jupiter-go/swap"
+)
+
+const (
+	testUserPublicKey = "BXzmfHxfEMcMj8hDccUNdrwXVNeybyfb2iV2nktE1VnJ"
+	testSwapTx        = "AAEAAQPrM+1WcczVrvBstwqcH1lXpPpbHuKVFpSj9kZOi1GITD6KBh4ENmDzZ4cG9x+7s1w6q77AoogJbaz28WWsI0elAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAANgS9CVZkT3oU8ECpERHXI92vwg8ofvcIVgdQtcOK3NgECAgABDAIAAACghgEAAAAAAA=="
+	testSignature     = "24jRjMP3medE9iMqVSPRbkwfe9GdPmLfeftKPuwRHZdYTZJ6UyzNMGGKo4BHrTu2zVj4CgFF3CEuzS79QXUo2CMC"
+)
+
+type jupiterMock struct {
+	jupiter.ClientWithResponsesInterface
+	priceImpactPct  string
+	quoteStatusCode int
+	quoteBody       string
+	shouldFailSwap  bool
+	lastSwapRequest *jupiter.SwapRequest
+	quoteParams     *[]jupiter.QuoteGetParams
+	// swapTx is the transaction of the swap responses, testSwapTx if empty.
+	swapTx string
+}
+
+func (j jupiterMock) QuoteGetWithResponse(
+	_ context.Context,
+	params *jupiter.QuoteGetParams,
+	_ ...jupiter.RequestEditorFn,
+) (*jupiter.QuoteGetResponse, error) {
+	if j.quoteStatusCode != 0 {
+		return &jupiter.QuoteGetResponse{
+			HTTPResponse: &http.Response{StatusCode: j.quoteStatusCode, Status: http.StatusText(j.quoteStatusCode)},
+			Body:         []byte(j.quoteBody),
+		}, nil
+	}
+
+	if j.quoteParams != nil {
+		*j.quoteParams = append(*j.quoteParams, *params)
+	}
+
+	slippageBps := uint64(250)
+	if params.SlippageBps != nil {
+		slippageBps = *params.SlippageBps
+	}
+
+	return &jupiter.QuoteGetResponse{
+		HTTPResponse: &http.Response{StatusCode: http.StatusOK},
+		JSON200: &jupiter.QuoteResponse{
+			InputMint:            params.InputMint,
+			OutputMint:           params.OutputMint,
+			InAmount:             "100000",
+			OutAmount:            "2000",
+			OtherAmountThreshold: "1950",
+			PriceImpactPct:       j.priceImpactPct,
+			SlippageBps:          slippageBps,
+			SwapMode:             jupiter.SwapModeExactIn,
+		},
+	}, nil
+}
+
+func (j jupiterMock) SwapPostWithResponse(
+	_ context.Context,
+	body jupiter.SwapPostJSONRequestBody,
+	_ ...jupiter.RequestEditorFn,
+) (*jupiter.SwapPostResponse, error) {
+	if j.shouldFailSwap {
+		return nil, errors.New("mocked error")
+	}
+
+	if j.lastSwapRequest != nil {
+		*j.lastSwapRequest = body
+	}
+
+	swapTx := testSwapTx
+	if j.swapTx != "" {
+		swapTx = j.swapTx
+	}
+
+	return &jupiter.SwapPostResponse{
+		HTTPResponse: &http.Response{StatusCode: http.StatusOK},
+		JSON200: &jupiter.SwapResponse{
+			LastValidBlockHeight: 123,
+			SwapTransaction:      swapTx,
+		},
+	}, nil
+}
+
+type solanaClientMock struct {
+	jupSolana.Client
+	sentTx      *solana.Transaction
+	sendOptions *jupSolana.SendOptions
+	// lastValidBlockHeight is the one of the blockhash the client sends with, zero if it keeps the blockhash.
+	lastValidBlockHeight uint64
+}
+
+func (s solanaClientMock) SendTransaction(
+	_ context.Context,
+	tx *solana.Transaction,
+	opts jupSolana.SendOptions,
+) (jupSolana.SendResult, error) {
+	if s.sentTx != nil {
+		*s.sentTx = *tx
+	}
+
+	if s.sendOptions != nil {
+		*s.sendOptions = opts
+	}
+
+	return jupSolana.SendResult{
+		TxID:                 jupSolana.TxID(testSignature),
+		LastValidBlockHeight: s.lastValidBlockHeight,
+	}, nil
+}
+
+type riskCheckMock struct {
+	report swap.RiskReport
+}
+
+func (r riskCheckMock) Check(_ context.Context, mint string) (swap.RiskReport, error) {
+	r.report.Mint = mint
+	return r.report, nil
+}
+
+func TestSwapper_Swap(t *testing.T) {
+	req := swap.Request{
+		Quote: jupiter.QuoteGetParams{
+			InputMint:  swap.WrappedSolMint,
+			OutputMint: testMint,
+			Amount:     100000,
+		},
+	}
+
+	t.Run("swapper without user public key", func(t *testing.T) {
+		_, err := swap.NewSwapper(jupiterMock{}, solanaClientMock{}, "")
+		require.EqualError(t, err, "userPublicKey is required")
+	})
+
+	t.Run("execute valid swap", func(t *testing.T) {
+		var sentTx solana.Transaction
+		var swapReq jupiter.SwapRequest
+
+		s, err := swap.NewSwapper(
+			jupiterMock{lastSwapRequest: &swapReq},
+			solanaClientMock{sentTx: &sentTx},
+			testUserPublicKey,
+		)
+		require.NoError(t, err)
+
+		res, err := s.Swap(context.TODO(), req)
+		require.NoError(t, err)
+		require.Equal(t, jupSolana.TxID(testSignature), res.TxID)
+		swapTx, err := jupSolana.NewTransactionFromBase64(testSwapTx)
+		require.NoError(t, err)
+		require.Equal(t, swapTx.Message, sentTx.Message)
+		require.Equal(t, testUserPublicKey, swapReq.UserPublicKey)
+		require.Equal(t, "2000", swapReq.QuoteResponse.OutAmount)
+		require.Equal(t, uint64(123), res.LastValidBlockHeight, "the blockhash of Jupiter is kept")
+		require.Nil(t, res.Risk)
+	})
+
+	t.Run("last valid block height of a refreshed blockhash", func(t *testing.T) {
+		s, err := swap.NewSwapper(jupiterMock{}, solanaClientMock{lastValidBlockHeight: 456}, testUserPublicKey)
+		require.NoError(t, err)
+
+		res, err := s.Swap(context.TODO(), req)
+		require.NoError(t, err)
+		require.Equal(t, uint64(456), res.LastValidBlockHeight)
+	})
+
+	t.Run("send with the options of the request", func(t *testing.T) {
+		var sendOpts jupSolana.SendOptions
+
+		s, err := swap.NewSwapper(jupiterMock{}, solanaClientMock{sendOptions: &sendOpts}, testUserPublicKey)
+		require.NoError(t, err)
+
+		withOpts := req
+		withOpts.Send = jupSolana.SendOptions{SkipPreflight: true}
+
+		_, err = s.Swap(context.TODO(), withOpts)
+		require.NoError(t, err)
+		require.True(t, sendOpts.SkipPreflight)
+	})
+
+	t.Run("error when building the swap", func(t *testing.T) {
+		s, err := swap.NewSwapper(jupiterMock{shouldFailSwap: true}, solanaClientMock{}, testUserPublicKey)
+		require.NoError(t, err)
+
+		_, err = s.Swap(context.TODO(), req)
+		require.EqualError(t, err, "could not get swap transaction: mocked error")
+	})
+
+	t.Run("invalid quote params", func(t *testing.T) {
+		var quoteParams []jupiter.QuoteGetParams
+
+		s, err := swap.NewSwapper(jupiterMock{quoteParams: &quoteParams}, solanaClientMock{}, testUserPublicKey)
+		require.NoError(t, err)
+
+		invalid := req
+		invalid.Quote.Amount = 0
+
+		_, err = s.Swap(context.TODO(), invalid)
+		require.EqualError(t, err, "could not get quote: invalid request: amount: must be positive")
+		require.Empty(t, quoteParams)
+	})
+
+	t.Run("refuse to swap when the risk policy is violated", func(t *testing.T) {
+		authority := solana.MustPublicKeyFromBase58(testAuthority).String()
+
+		var sentTx solana.Transaction
+
+		s, err := swap.NewSwapper(
+			jupiterMock{},
+			solanaClientMock{sentTx: &sentTx},
+			testUserPublicKey,
+			swap.WithRiskPolicy(
+				riskCheckMock{report: swap.RiskReport{FreezeAuthority: &authority, HasRoute: true}},
+				swap.DefaultRiskPolicy,
+			),
+		)
+		require.NoError(t, err)
+
+		res, err := s.Swap(context.TODO(), req)
+		require.ErrorIs(t, err, swap.ErrRiskPolicyViolation)
+		require.NotNil(t, res.Risk)
+		require.Empty(t, sentTx)
+	})
+
+	t.Run("refuse to send when the transaction verification fails", func(t *testing.T) {
+		var sentTx solana.Transaction
+
+		v, err := swap.NewVerifier(testUserPublicKey, "", swap.WithVerifierRPC(rpcMock{owner: solana.TokenProgramID}))
+		require.NoError(t, err)
+
+		s, err := swap.NewSwapper(
+			jupiterMock{},
+			solanaClientMock{sentTx: &sentTx},
+			testUserPublicKey,
+			swap.WithVerifier(v),
+		)
+		require.NoError(t, err)
+
+		_, err = s.Swap(context.TODO(), req)
+		require.ErrorIs(t, err, swap.ErrVerificationFailed)
+		require.Empty(t, sentTx)
+	})
+
+	t.Run("swap when the risk policy is satisfied", func(t *testing.T) {
+		s, err := swap.NewSwapper(
+			jupiterMock{},
+			solanaClientMock{},
+			testUserPublicKey,
+			swap.WithRiskPolicy(riskCheckMock{report: swap.RiskReport{HasRoute: true}}, swap.DefaultRiskPolicy),
+		)
+		require.NoError(t, err)
+
+		res, err := s.Swap(context.TODO(), req)
+		require.NoError(t, err)
+		require.Equal(t, testMint, res.Risk.Mint)
+	})
+}
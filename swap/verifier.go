@@ -0,0 +1,607 @@
+package swap
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/gagliardetto/solana-go"
+	addresslookuptable "github.com/gagliardetto/solana-go/programs/address-lookup-table"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"github.com/ilkamo/jupiter-go/jupiter"
+	"github.com/ilkamo/jupiter-go/jupiter/aggregator"
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+)
+
+// ErrVerificationFailed is returned when a swap transaction does not match what was expected.
+var ErrVerificationFailed = errors.New("transaction verification failed")
+
+const (
+	systemInstructionTransfer            = 2
+	systemInstructionAdvanceNonceAccount = 4
+
+	tokenInstructionCloseAccount = 9
+	tokenInstructionSyncNative   = 17
+
+	ataInstructionCreate           = 0
+	ataInstructionCreateIdempotent = 1
+
+	// maxComputeUnitLimit is the most compute units a transaction can use, when it does not set a limit.
+	maxComputeUnitLimit     = 1_400_000
+	microLamportsPerLamport = 1_000_000
+
+	// defaultMaxPriorityFee and defaultMaxTip are 0.01 SOL.
+	defaultMaxPriorityFee = 10_000_000
+	defaultMaxTip         = 10_000_000
+)
+
+type verifier struct {
+	clientRPC       rpcService
+	wallet          solana.PublicKey
+	allowedPrograms map[solana.PublicKey]struct{}
+	maxSlippageBps  *uint64
+	maxPriorityFee  uint64
+	maxTip          uint64
+}
+
+// NewVerifier creates a verifier for swap transactions built by Jupiter for the given user public key.
+// The RPC endpoint is used to resolve address lookup tables.
+func NewVerifier(userPublicKey string, rpcEndpoint string, opts ...VerifierOption) (Verifier, error) {
+	wallet, err := solana.PublicKeyFromBase58(userPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse user public key: %w", err)
+	}
+
+	v := &verifier{
+		wallet: wallet,
+		allowedPrograms: map[solana.PublicKey]struct{}{
+			aggregator.ProgramID:                      {},
+			solana.ComputeBudget:                      {},
+			solana.TokenProgramID:                     {},
+			solana.Token2022ProgramID:                 {},
+			solana.SPLAssociatedTokenAccountProgramID: {},
+			solana.SystemProgramID:                    {},
+		},
+		maxPriorityFee: defaultMaxPriorityFee,
+		maxTip:         defaultMaxTip,
+	}
+
+	for _, opt := range opts {
+		if err := opt(v); err != nil {
+			return nil, fmt.Errorf("could not apply option: %w", err)
+		}
+	}
+
+	if v.clientRPC == nil {
+		if rpcEndpoint == "" {
+			return nil, fmt.Errorf("rpcEndpoint is required when no RPC service is provided")
+		}
+
+		v.clientRPC = rpc.New(rpcEndpoint)
+	}
+
+	return v, nil
+}
+
+// VerifyTransaction checks the signers, the invoked programs and the Jupiter route instruction
+// of the transaction without comparing it to a quote.
+func (v verifier) VerifyTransaction(ctx context.Context, tx solana.Transaction) error {
+	_, err := v.verify(ctx, tx)
+	return err
+}
+
+// Verify checks the transaction and that its Jupiter route instruction is consistent with the quote.
+func (v verifier) Verify(ctx context.Context, tx solana.Transaction, quote jupiter.QuoteResponse) error {
+	route, err := v.verify(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	return v.verifyQuote(route, quote)
+}
+
+// ForQuote returns a TransactionVerifier bound to the quote, to be used with solana.WithTransactionVerifier.
+func (v verifier) ForQuote(quote jupiter.QuoteResponse) jupSolana.TransactionVerifier {
+	return quoteVerifier{v: v, quote: quote}
+}
+
+type quoteVerifier struct {
+	v     verifier
+	quote jupiter.QuoteResponse
+}
+
+func (q quoteVerifier) VerifyTransaction(ctx context.Context, tx solana.Transaction) error {
+	return q.v.Verify(ctx, tx, q.quote)
+}
+
+func (v verifier) verify(ctx context.Context, tx solana.Transaction) (aggregator.Route, error) {
+	msg := tx.Message
+
+	if len(msg.AccountKeys) == 0 || !msg.AccountKeys[0].Equals(v.wallet) {
+		return aggregator.Route{}, fmt.Errorf("%w: fee payer is not %s", ErrVerificationFailed, v.wallet)
+	}
+
+	for _, signer := range msg.Signers() {
+		if !signer.Equals(v.wallet) {
+			return aggregator.Route{}, fmt.Errorf("%w: unexpected signer %s", ErrVerificationFailed, signer)
+		}
+	}
+
+	keys, err := v.resolveKeys(ctx, &msg)
+	if err != nil {
+		return aggregator.Route{}, err
+	}
+
+	var (
+		route      aggregator.Route
+		routeFound bool
+		unitLimit  uint32
+		unitPrice  uint64
+		tips       uint64
+	)
+
+	for i, ix := range msg.Instructions {
+		if int(ix.ProgramIDIndex) >= len(keys) {
+			return aggregator.Route{}, fmt.Errorf("%w: instruction %d: invalid program index", ErrVerificationFailed, i)
+		}
+
+		programID := keys[ix.ProgramIDIndex]
+		if _, ok := v.allowedPrograms[programID]; !ok {
+			return aggregator.Route{}, fmt.Errorf("%w: instruction %d: program %s is not allowed", ErrVerificationFailed, i, programID)
+		}
+
+		accounts := make([]solana.PublicKey, len(ix.Accounts))
+		for j, idx := range ix.Accounts {
+			if int(idx) >= len(keys) {
+				return aggregator.Route{}, fmt.Errorf("%w: instruction %d: invalid account index", ErrVerificationFailed, i)
+			}
+			accounts[j] = keys[idx]
+		}
+
+		switch {
+		case programID.Equals(aggregator.ProgramID):
+			if routeFound {
+				return aggregator.Route{}, fmt.Errorf("%w: more than one jupiter instruction", ErrVerificationFailed)
+			}
+
+			route, err = decodeRoute(ix.Data, accounts)
+			if err != nil {
+				return aggregator.Route{}, fmt.Errorf("%w: instruction %d: %w", ErrVerificationFailed, i, err)
+			}
+
+			routeFound = true
+		case programID.Equals(solana.ComputeBudget):
+			switch {
+			case len(ix.Data) >= 5 && ix.Data[0] == computebudget.Instruction_SetComputeUnitLimit:
+				unitLimit = binary.LittleEndian.Uint32(ix.Data[1:])
+			case len(ix.Data) >= 9 && ix.Data[0] == computebudget.Instruction_SetComputeUnitPrice:
+				unitPrice = binary.LittleEndian.Uint64(ix.Data[1:])
+			}
+		case programID.Equals(solana.SystemProgramID):
+			tip, err := v.verifySystemInstruction(i, ix.Data, accounts)
+			if err != nil {
+				return aggregator.Route{}, fmt.Errorf("%w: instruction %d: %w", ErrVerificationFailed, i, err)
+			}
+
+			if tip > v.maxTip-tips {
+				return aggregator.Route{}, fmt.Errorf("%w: jito tips exceed %d lamports", ErrVerificationFailed, v.maxTip)
+			}
+
+			tips += tip
+		case programID.IsAnyOf(solana.TokenProgramID, solana.Token2022ProgramID):
+			if err := v.verifyTokenInstruction(ix.Data, accounts); err != nil {
+				return aggregator.Route{}, fmt.Errorf("%w: instruction %d: %w", ErrVerificationFailed, i, err)
+			}
+		case programID.Equals(solana.SPLAssociatedTokenAccountProgramID):
+			if err := v.verifyATAInstruction(ix.Data, accounts); err != nil {
+				return aggregator.Route{}, fmt.Errorf("%w: instruction %d: %w", ErrVerificationFailed, i, err)
+			}
+		}
+	}
+
+	if !routeFound {
+		return aggregator.Route{}, fmt.Errorf("%w: no jupiter route instruction", ErrVerificationFailed)
+	}
+
+	if fee := priorityFee(unitLimit, unitPrice); fee > v.maxPriorityFee {
+		return aggregator.Route{}, fmt.Errorf(
+			"%w: priority fee of %d lamports exceeds %d lamports", ErrVerificationFailed, fee, v.maxPriorityFee,
+		)
+	}
+
+	if !route.Accounts.UserTransferAuthority.Equals(v.wallet) {
+		return aggregator.Route{}, fmt.Errorf(
+			"%w: route transfer authority is %s", ErrVerificationFailed, route.Accounts.UserTransferAuthority,
+		)
+	}
+
+	if v.maxSlippageBps != nil && uint64(route.SlippageBps) > *v.maxSlippageBps {
+		return aggregator.Route{}, fmt.Errorf(
+			"%w: route slippage %d bps exceeds %d bps", ErrVerificationFailed, route.SlippageBps, *v.maxSlippageBps,
+		)
+	}
+
+	if recipient := route.Accounts.RecipientTokenAccount; recipient != nil {
+		return aggregator.Route{}, fmt.Errorf("%w: route sends the output to %s", ErrVerificationFailed, recipient)
+	}
+
+	if err := v.verifyDestination(ctx, route.Accounts); err != nil {
+		return aggregator.Route{}, err
+	}
+
+	return route, nil
+}
+
+func (v verifier) resolveKeys(ctx context.Context, msg *solana.Message) (solana.PublicKeySlice, error) {
+	if len(msg.AddressTableLookups) == 0 {
+		return msg.AccountKeys, nil
+	}
+
+	if msg.GetAddressTables() != nil {
+		return msg.GetAllKeys()
+	}
+
+	tables := make(map[solana.PublicKey]solana.PublicKeySlice, len(msg.AddressTableLookups))
+
+	for _, lookup := range msg.AddressTableLookups {
+		account, err := v.clientRPC.GetAccountInfoWithOpts(ctx, lookup.AccountKey, &rpc.GetAccountInfoOpts{
+			Encoding: solana.EncodingBase64,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not get address lookup table %s: %w", lookup.AccountKey, err)
+		}
+
+		if account == nil || account.Value == nil {
+			return nil, fmt.Errorf("could not get address lookup table %s: account not found", lookup.AccountKey)
+		}
+
+		state, err := addresslookuptable.DecodeAddressLookupTableState(account.Value.Data.GetBinary())
+		if err != nil {
+			return nil, fmt.Errorf("could not decode address lookup table %s: %w", lookup.AccountKey, err)
+		}
+
+		tables[lookup.AccountKey] = state.Addresses
+	}
+
+	if err := msg.SetAddressTables(tables); err != nil {
+		return nil, fmt.Errorf("could not set address lookup tables: %w", err)
+	}
+
+	keys, err := msg.GetAllKeys()
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve address lookup tables: %w", err)
+	}
+
+	return keys, nil
+}
+
+// verifySystemInstruction only allows transfers from the wallet to a Jito tip account or to one of the wallet's
+// wrapped SOL token accounts, and advancing a nonce of the wallet as first instruction of a durable nonce transaction.
+// It returns the lamports tipped.
+func (v verifier) verifySystemInstruction(index int, data []byte, accounts []solana.PublicKey) (uint64, error) {
+	if len(data) >= 4 && binary.LittleEndian.Uint32(data[0:4]) == systemInstructionAdvanceNonceAccount {
+		if index != 0 {
+			return 0, fmt.Errorf("nonce advanced after the first instruction")
+		}
+
+		if len(accounts) < 3 || !accounts[2].Equals(v.wallet) {
+			return 0, fmt.Errorf("nonce authority is not the wallet")
+		}
+
+		return 0, nil
+	}
+
+	if len(data) < 12 || binary.LittleEndian.Uint32(data[0:4]) != systemInstructionTransfer || len(accounts) < 2 {
+		return 0, fmt.Errorf("unexpected system instruction")
+	}
+
+	if !accounts[0].Equals(v.wallet) {
+		return 0, fmt.Errorf("system transfer from %s", accounts[0])
+	}
+
+	if accounts[1].IsAnyOf(jupSolana.JitoTipAccounts...) {
+		return binary.LittleEndian.Uint64(data[4:12]), nil
+	}
+
+	wsolMint := solana.MustPublicKeyFromBase58(WrappedSolMint)
+	if ok, _ := v.isWalletATA(accounts[1], wsolMint); ok {
+		return 0, nil
+	}
+
+	return 0, fmt.Errorf("system transfer to %s", accounts[1])
+}
+
+// verifyTokenInstruction only allows syncing native accounts and closing accounts back to the wallet.
+func (v verifier) verifyTokenInstruction(data []byte, accounts []solana.PublicKey) error {
+	if len(data) == 0 {
+		return fmt.Errorf("empty token instruction")
+	}
+
+	switch data[0] {
+	case tokenInstructionSyncNative:
+		return nil
+	case tokenInstructionCloseAccount:
+		if len(accounts) < 3 || !accounts[1].Equals(v.wallet) || !accounts[2].Equals(v.wallet) {
+			return fmt.Errorf("token account closed to a foreign account")
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unexpected token instruction %d", data[0])
+}
+
+// verifyATAInstruction only allows the creation of token accounts owned by the wallet.
+func (v verifier) verifyATAInstruction(data []byte, accounts []solana.PublicKey) error {
+	if len(data) > 0 && data[0] != ataInstructionCreate && data[0] != ataInstructionCreateIdempotent {
+		return fmt.Errorf("unexpected associated token account instruction %d", data[0])
+	}
+
+	if len(accounts) < 3 || !accounts[2].Equals(v.wallet) {
+		return fmt.Errorf("associated token account created for a foreign owner")
+	}
+
+	return nil
+}
+
+func (v verifier) isWalletATA(account, mint solana.PublicKey) (bool, error) {
+	for _, tokenProgram := range []solana.PublicKey{solana.TokenProgramID, solana.Token2022ProgramID} {
+		ata, err := v.walletATA(mint, tokenProgram)
+		if err != nil {
+			return false, err
+		}
+
+		if ata.Equals(account) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (v verifier) walletATA(mint, tokenProgram solana.PublicKey) (solana.PublicKey, error) {
+	ata, _, err := solana.FindProgramAddress(
+		[][]byte{v.wallet[:], tokenProgram[:], mint[:]},
+		solana.SPLAssociatedTokenAccountProgramID,
+	)
+
+	return ata, err
+}
+
+// verifyDestination checks that the route sends the output to the associated token account of the wallet,
+// derived with the token program of the destination mint.
+func (v verifier) verifyDestination(ctx context.Context, accounts aggregator.Accounts) error {
+	tokenProgram, err := v.mintTokenProgram(ctx, accounts.DestinationMint)
+	if err != nil {
+		return err
+	}
+
+	destination, err := v.walletATA(accounts.DestinationMint, tokenProgram)
+	if err != nil {
+		return fmt.Errorf("could not derive destination token account: %w", err)
+	}
+
+	if !accounts.DestinationTokenAccount.Equals(destination) {
+		return fmt.Errorf("%w: route destination token account %s is not the wallet token account %s",
+			ErrVerificationFailed, accounts.DestinationTokenAccount, destination)
+	}
+
+	return nil
+}
+
+// mintTokenProgram returns the token program owning the mint.
+func (v verifier) mintTokenProgram(ctx context.Context, mint solana.PublicKey) (solana.PublicKey, error) {
+	account, err := v.clientRPC.GetAccountInfoWithOpts(ctx, mint, &rpc.GetAccountInfoOpts{
+		Encoding:   solana.EncodingBase64,
+		Commitment: rpc.CommitmentConfirmed,
+	})
+	if err != nil {
+		return solana.PublicKey{}, fmt.Errorf("could not get mint account %s: %w", mint, err)
+	}
+
+	if account == nil || account.Value == nil {
+		return solana.PublicKey{}, fmt.Errorf("could not get mint account %s: account not found", mint)
+	}
+
+	owner := account.Value.Owner
+	if !owner.IsAnyOf(solana.TokenProgramID, solana.Token2022ProgramID) {
+		return solana.PublicKey{}, fmt.Errorf(
+			"%w: mint %s is not owned by a token program: %s", ErrVerificationFailed, mint, owner,
+		)
+	}
+
+	return owner, nil
+}
+
+// priorityFee returns the lamports paid for the compute unit price, rounded up like the runtime does.
+func priorityFee(unitLimit uint32, unitPrice uint64) uint64 {
+	if unitLimit == 0 {
+		unitLimit = maxComputeUnitLimit
+	}
+
+	fee := new(big.Int).SetUint64(unitPrice)
+	fee.Mul(fee, new(big.Int).SetUint64(uint64(unitLimit)))
+	fee.Add(fee, big.NewInt(microLamportsPerLamport-1))
+	fee.Div(fee, big.NewInt(microLamportsPerLamport))
+
+	if !fee.IsUint64() {
+		return math.MaxUint64
+	}
+
+	return fee.Uint64()
+}
+
+// decodeRoute decodes the Jupiter instruction of the transaction. The route plan is not verified,
+// so swap variants unknown to the decoder are accepted.
+func decodeRoute(data []byte, accounts []solana.PublicKey) (aggregator.Route, error) {
+	route, err := aggregator.DecodeInstruction(data, accounts)
+	if err != nil && !errors.Is(err, aggregator.ErrUnknownSwap) {
+		return aggregator.Route{}, err
+	}
+
+	// The in amount of token ledger routes is only known on-chain.
+	if route.Kind.TokenLedger() {
+		return aggregator.Route{}, fmt.Errorf("unsupported jupiter instruction %s", route.Kind)
+	}
+
+	return route, nil
+}
+
+func (v verifier) verifyQuote(route aggregator.Route, quote jupiter.QuoteResponse) error {
+	inputMint, err := solana.PublicKeyFromBase58(quote.InputMint)
+	if err != nil {
+		return fmt.Errorf("could not parse quote input mint: %w", err)
+	}
+
+	outputMint, err := solana.PublicKeyFromBase58(quote.OutputMint)
+	if err != nil {
+		return fmt.Errorf("could not parse quote output mint: %w", err)
+	}
+
+	accounts := route.Accounts
+
+	if !accounts.DestinationMint.Equals(outputMint) {
+		return fmt.Errorf("%w: route output mint %s, quote output mint %s",
+			ErrVerificationFailed, accounts.DestinationMint, outputMint)
+	}
+
+	if accounts.SourceMint != nil {
+		if !accounts.SourceMint.Equals(inputMint) {
+			return fmt.Errorf("%w: route input mint %s, quote input mint %s",
+				ErrVerificationFailed, accounts.SourceMint, inputMint)
+		}
+	} else {
+		ok, err := v.isWalletATA(accounts.SourceTokenAccount, inputMint)
+		if err != nil {
+			return fmt.Errorf("could not derive source token account: %w", err)
+		}
+
+		if !ok {
+			return fmt.Errorf("%w: route source token account %s does not hold %s",
+				ErrVerificationFailed, accounts.SourceTokenAccount, inputMint)
+		}
+	}
+
+	if err := verifyPlatformFee(route, quote.PlatformFee); err != nil {
+		return err
+	}
+
+	threshold, err := strconv.ParseUint(quote.OtherAmountThreshold, 10, 64)
+	if err != nil {
+		return fmt.Errorf("could not parse quote other amount threshold: %w", err)
+	}
+
+	if route.Kind.ExactOut() {
+		if quote.SwapMode != jupiter.SwapModeExactOut {
+			return fmt.Errorf("%w: exact out route for a %s quote", ErrVerificationFailed, quote.SwapMode)
+		}
+
+		return verifyAmounts(
+			route.OutAmount, route.QuotedInAmount, route.SlippageBps,
+			quote.OutAmount, quote.InAmount, threshold, true, v.maxSlippageBps,
+		)
+	}
+
+	if quote.SwapMode == jupiter.SwapModeExactOut {
+		return fmt.Errorf("%w: exact in route for a %s quote", ErrVerificationFailed, quote.SwapMode)
+	}
+
+	return verifyAmounts(
+		route.InAmount, route.QuotedOutAmount, route.SlippageBps,
+		quote.InAmount, quote.OutAmount, threshold, false, v.maxSlippageBps,
+	)
+}
+
+// verifyPlatformFee checks that the route charges the platform fee of the quote, and only then has a fee account.
+func verifyPlatformFee(route aggregator.Route, fee *jupiter.PlatformFee) error {
+	var feeBps uint64
+	if fee != nil && fee.FeeBps != nil {
+		feeBps = *fee.FeeBps
+	}
+
+	if uint64(route.PlatformFeeBps) != feeBps {
+		return fmt.Errorf("%w: route platform fee %d bps, quote platform fee %d bps",
+			ErrVerificationFailed, route.PlatformFeeBps, feeBps)
+	}
+
+	account := route.Accounts.PlatformFeeAccount
+
+	if feeBps == 0 && account != nil {
+		return fmt.Errorf("%w: route platform fee account %s without a platform fee", ErrVerificationFailed, account)
+	}
+
+	if feeBps > 0 && account == nil {
+		return fmt.Errorf("%w: route platform fee without a platform fee account", ErrVerificationFailed)
+	}
+
+	return nil
+}
+
+// verifyAmounts checks that the fixed amount of the route matches the quote and that the
+// worst case amount accepted by the route is not worse than the one accepted by the quote.
+// When maxSlippageBps is set the worst case is computed against the quoted amount with that slippage instead.
+func verifyAmounts(
+	routeAmount uint64,
+	routeQuotedAmount uint64,
+	routeSlippageBps uint16,
+	quoteAmount string,
+	quoteQuotedAmount string,
+	threshold uint64,
+	exactOut bool,
+	maxSlippageBps *uint64,
+) error {
+	amount, err := strconv.ParseUint(quoteAmount, 10, 64)
+	if err != nil {
+		return fmt.Errorf("could not parse quote amount: %w", err)
+	}
+
+	if routeAmount != amount {
+		return fmt.Errorf("%w: route amount %d, quote amount %d", ErrVerificationFailed, routeAmount, amount)
+	}
+
+	if maxSlippageBps != nil {
+		quoted, err := strconv.ParseUint(quoteQuotedAmount, 10, 64)
+		if err != nil {
+			return fmt.Errorf("could not parse quote amount: %w", err)
+		}
+
+		threshold = applySlippage(quoted, *maxSlippageBps, exactOut)
+	}
+
+	worst := applySlippage(routeQuotedAmount, uint64(routeSlippageBps), exactOut)
+
+	if exactOut && worst > threshold {
+		return fmt.Errorf("%w: route maximum in amount %d exceeds %d", ErrVerificationFailed, worst, threshold)
+	}
+
+	if !exactOut && worst < threshold {
+		return fmt.Errorf("%w: route minimum out amount %d is below %d", ErrVerificationFailed, worst, threshold)
+	}
+
+	return nil
+}
+
+// applySlippage returns the minimum out amount (exact in) or the maximum in amount (exact out)
+// accepted with the given slippage, rounded down like the quote threshold.
+func applySlippage(amount uint64, slippageBps uint64, exactOut bool) uint64 {
+	a := new(big.Int).SetUint64(amount)
+	denominator := big.NewInt(10_000)
+
+	if exactOut {
+		a.Mul(a, new(big.Int).SetUint64(10_000+slippageBps))
+		return a.Div(a, denominator).Uint64()
+	}
+
+	if slippageBps > 10_000 {
+		return 0
+	}
+
+	a.Mul(a, new(big.Int).SetUint64(10_000-slippageBps))
+
+	return a.Div(a, denominator).Uint64()
+}
@@ -0,0 +1,445 @@
+package swap_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilkamo/jupiter-go/jupiter"
+	jupSolana "github.com/ilkamo/jupiter-go/solana"
+	"github.com/ilkamo/jupiter-go/swap"
+)
+
+var (
+	testJupiterProgramID = solana.MustPublicKeyFromBase58("JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4")
+	testBlockhash        = solana.MustHashFromBase58("uiYzZ5PCq6C8BRSLSUGBScrXo62bBFbRFP9EkPcaWN9")
+)
+
+type testRoute struct {
+	name        string
+	inAmount    uint64
+	quotedOut   uint64
+	slippageBps uint16
+	outputMint  solana.PublicKey
+	// destination is the wallet's associated token account of the output mint when zero.
+	destination        solana.PublicKey
+	recipient          *solana.PublicKey
+	platformFeeBps     uint8
+	platformFeeAccount *solana.PublicKey
+}
+
+func testSharedRoute() testRoute {
+	return testRoute{
+		name:        "shared_accounts_route",
+		inAmount:    100000,
+		quotedOut:   2000,
+		slippageBps: 250,
+		outputMint:  solana.MustPublicKeyFromBase58(testMint),
+	}
+}
+
+// instruction builds a shared_accounts_route instruction, or a route instruction when the name is "route".
+func (r testRoute) instruction(wallet solana.PublicKey) solana.Instruction {
+	sum := sha256.Sum256([]byte("global:" + r.name))
+
+	data := append([]byte{}, sum[:8]...)
+	if r.name != "route" {
+		data = append(data, 0) // id
+	}
+	data = append(data, 0, 0, 0, 0) // empty route plan
+	data = binary.LittleEndian.AppendUint64(data, r.inAmount)
+	data = binary.LittleEndian.AppendUint64(data, r.quotedOut)
+	data = binary.LittleEndian.AppendUint16(data, r.slippageBps)
+	data = append(data, r.platformFeeBps)
+
+	inputMint := solana.MustPublicKeyFromBase58(swap.WrappedSolMint)
+	eventAuthority := solana.MustPublicKeyFromBase58("D8cy77BBepLMngZx6ZukaTff5hCt1HrWyKk3Hnd9oitf")
+
+	destination := r.destination
+	if destination.IsZero() {
+		destination, _, _ = solana.FindAssociatedTokenAddress(wallet, r.outputMint)
+	}
+
+	optional := func(account *solana.PublicKey) solana.PublicKey {
+		if account == nil {
+			return testJupiterProgramID
+		}
+
+		return *account
+	}
+
+	if r.name == "route" {
+		return solana.NewInstruction(testJupiterProgramID, solana.AccountMetaSlice{
+			solana.Meta(solana.TokenProgramID),
+			solana.Meta(wallet).SIGNER(),
+			solana.Meta(solana.NewWallet().PublicKey()).WRITE(),
+			solana.Meta(destination).WRITE(),
+			solana.Meta(optional(r.recipient)).WRITE(),
+			solana.Meta(r.outputMint),
+			solana.Meta(optional(r.platformFeeAccount)),
+			solana.Meta(eventAuthority),
+			solana.Meta(testJupiterProgramID),
+		}, data)
+	}
+
+	return solana.NewInstruction(testJupiterProgramID, solana.AccountMetaSlice{
+		solana.Meta(solana.TokenProgramID),
+		solana.Meta(solana.MustPublicKeyFromBase58("45ruCyfdRkWpRNGEqWzjCiXRHkZs8WXCLQ67Pnpye7Hp")),
+		solana.Meta(wallet).SIGNER(),
+		solana.Meta(solana.NewWallet().PublicKey()).WRITE(),
+		solana.Meta(solana.NewWallet().PublicKey()).WRITE(),
+		solana.Meta(solana.NewWallet().PublicKey()).WRITE(),
+		solana.Meta(destination).WRITE(),
+		solana.Meta(inputMint),
+		solana.Meta(r.outputMint),
+		solana.Meta(optional(r.platformFeeAccount)),
+		solana.Meta(solana.Token2022ProgramID),
+		solana.Meta(eventAuthority),
+		solana.Meta(testJupiterProgramID),
+	}, data)
+}
+
+func buildSwapTx(
+	t *testing.T,
+	wallet solana.PublicKey,
+	extra []solana.Instruction,
+	opts ...solana.TransactionOption,
+) solana.Transaction {
+	return buildRouteTx(t, wallet, testSharedRoute(), extra, opts...)
+}
+
+func buildRouteTx(
+	t *testing.T,
+	wallet solana.PublicKey,
+	route testRoute,
+	extra []solana.Instruction,
+	opts ...solana.TransactionOption,
+) solana.Transaction {
+	instructions := []solana.Instruction{
+		solana.NewInstruction(solana.ComputeBudget, solana.AccountMetaSlice{}, []byte{2, 0x40, 0x0d, 0x03, 0x00}),
+		route.instruction(wallet),
+		system.NewTransferInstruction(1000, wallet, jupSolana.JitoTipAccounts[0]).Build(),
+	}
+	instructions = append(instructions, extra...)
+
+	opts = append(opts, solana.TransactionPayer(wallet))
+
+	tx, err := solana.NewTransaction(instructions, testBlockhash, opts...)
+	require.NoError(t, err)
+
+	return *tx
+}
+
+func testQuote() jupiter.QuoteResponse {
+	return jupiter.QuoteResponse{
+		InputMint:            swap.WrappedSolMint,
+		OutputMint:           testMint,
+		InAmount:             "100000",
+		OutAmount:            "2000",
+		OtherAmountThreshold: "1950",
+		SlippageBps:          250,
+		SwapMode:             jupiter.SwapModeExactIn,
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	wallet := solana.MustPublicKeyFromBase58(testUserPublicKey)
+
+	v, err := swap.NewVerifier(testUserPublicKey, "", swap.WithVerifierRPC(rpcMock{owner: solana.TokenProgramID}))
+	require.NoError(t, err)
+
+	t.Run("verifier without rpc endpoint", func(t *testing.T) {
+		_, err := swap.NewVerifier(testUserPublicKey, "")
+		require.EqualError(t, err, "rpcEndpoint is required when no RPC service is provided")
+	})
+
+	t.Run("valid swap transaction", func(t *testing.T) {
+		tx := buildSwapTx(t, wallet, nil)
+
+		require.NoError(t, v.VerifyTransaction(context.TODO(), tx))
+		require.NoError(t, v.Verify(context.TODO(), tx, testQuote()))
+		require.NoError(t, v.ForQuote(testQuote()).VerifyTransaction(context.TODO(), tx))
+	})
+
+	t.Run("fee payer is not the wallet", func(t *testing.T) {
+		tx := buildSwapTx(t, solana.NewWallet().PublicKey(), nil)
+
+		err := v.VerifyTransaction(context.TODO(), tx)
+		require.ErrorIs(t, err, swap.ErrVerificationFailed)
+		require.ErrorContains(t, err, "fee payer is not")
+	})
+
+	t.Run("program not allowed", func(t *testing.T) {
+		memo := solana.MustPublicKeyFromBase58("MemoSq4gqABAXKb96qnH8TysNcWxMyWCqXgDLGmfcHr")
+		tx := buildSwapTx(t, wallet, []solana.Instruction{
+			solana.NewInstruction(memo, solana.AccountMetaSlice{}, []byte("hello")),
+		})
+
+		err := v.VerifyTransaction(context.TODO(), tx)
+		require.ErrorContains(t, err, "is not allowed")
+
+		withMemo, err := swap.NewVerifier(
+			testUserPublicKey,
+			"",
+			swap.WithVerifierRPC(rpcMock{owner: solana.TokenProgramID}),
+			swap.WithAllowedPrograms(memo.String()),
+		)
+		require.NoError(t, err)
+		require.NoError(t, withMemo.VerifyTransaction(context.TODO(), tx))
+	})
+
+	t.Run("system transfer to a foreign account", func(t *testing.T) {
+		tx := buildSwapTx(t, wallet, []solana.Instruction{
+			system.NewTransferInstruction(1000, wallet, solana.NewWallet().PublicKey()).Build(),
+		})
+
+		err := v.VerifyTransaction(context.TODO(), tx)
+		require.ErrorContains(t, err, "system transfer to")
+	})
+
+	t.Run("output mint does not match the quote", func(t *testing.T) {
+		tx := buildSwapTx(t, wallet, nil)
+
+		quote := testQuote()
+		quote.OutputMint = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+
+		err := v.Verify(context.TODO(), tx, quote)
+		require.ErrorIs(t, err, swap.ErrVerificationFailed)
+		require.ErrorContains(t, err, "route output mint")
+	})
+
+	t.Run("in amount does not match the quote", func(t *testing.T) {
+		tx := buildSwapTx(t, wallet, nil)
+
+		quote := testQuote()
+		quote.InAmount = "100001"
+
+		err := v.Verify(context.TODO(), tx, quote)
+		require.ErrorContains(t, err, "route amount 100000, quote amount 100001")
+	})
+
+	t.Run("minimum out below the quote threshold", func(t *testing.T) {
+		tx := buildSwapTx(t, wallet, nil)
+
+		quote := testQuote()
+		quote.OtherAmountThreshold = "1990"
+
+		err := v.Verify(context.TODO(), tx, quote)
+		require.ErrorContains(t, err, "route minimum out amount 1950 is below 1990")
+	})
+
+	t.Run("slippage above the configured maximum", func(t *testing.T) {
+		tx := buildSwapTx(t, wallet, nil)
+
+		strict, err := swap.NewVerifier(
+			testUserPublicKey,
+			"",
+			swap.WithVerifierRPC(rpcMock{owner: solana.TokenProgramID}),
+			swap.WithMaxSlippageBps(100),
+		)
+		require.NoError(t, err)
+
+		err = strict.VerifyTransaction(context.TODO(), tx)
+		require.ErrorContains(t, err, "route slippage 250 bps exceeds 100 bps")
+	})
+
+	t.Run("destination is not the wallet token account", func(t *testing.T) {
+		route := testSharedRoute()
+		route.destination = solana.NewWallet().PublicKey()
+
+		err := v.VerifyTransaction(context.TODO(), buildRouteTx(t, wallet, route, nil))
+		require.ErrorIs(t, err, swap.ErrVerificationFailed)
+		require.ErrorContains(t, err, "route destination token account")
+	})
+
+	t.Run("destination derived with another token program", func(t *testing.T) {
+		route := testSharedRoute()
+
+		destination, _, err := solana.FindProgramAddress(
+			[][]byte{wallet[:], solana.Token2022ProgramID[:], route.outputMint[:]},
+			solana.SPLAssociatedTokenAccountProgramID,
+		)
+		require.NoError(t, err)
+
+		route.destination = destination
+		tx := buildRouteTx(t, wallet, route, nil)
+
+		err = v.VerifyTransaction(context.TODO(), tx)
+		require.ErrorContains(t, err, "route destination token account")
+
+		token2022, err := swap.NewVerifier(
+			testUserPublicKey,
+			"",
+			swap.WithVerifierRPC(rpcMock{owner: solana.Token2022ProgramID}),
+		)
+		require.NoError(t, err)
+		require.NoError(t, token2022.VerifyTransaction(context.TODO(), tx))
+	})
+
+	t.Run("output mint not owned by a token program", func(t *testing.T) {
+		noMint, err := swap.NewVerifier(testUserPublicKey, "", swap.WithVerifierRPC(rpcMock{}))
+		require.NoError(t, err)
+
+		err = noMint.VerifyTransaction(context.TODO(), buildSwapTx(t, wallet, nil))
+		require.ErrorIs(t, err, swap.ErrVerificationFailed)
+		require.ErrorContains(t, err, "is not owned by a token program")
+	})
+
+	t.Run("route output sent to another token account", func(t *testing.T) {
+		recipient := solana.NewWallet().PublicKey()
+
+		route := testSharedRoute()
+		route.name = "route"
+
+		require.NoError(t, v.VerifyTransaction(context.TODO(), buildRouteTx(t, wallet, route, nil)))
+
+		route.recipient = &recipient
+
+		err := v.VerifyTransaction(context.TODO(), buildRouteTx(t, wallet, route, nil))
+		require.ErrorIs(t, err, swap.ErrVerificationFailed)
+		require.ErrorContains(t, err, "route sends the output to "+recipient.String())
+	})
+
+	t.Run("platform fee does not match the quote", func(t *testing.T) {
+		feeAccount := solana.NewWallet().PublicKey()
+		feeBps := uint64(10)
+
+		quote := testQuote()
+		quote.PlatformFee = &jupiter.PlatformFee{FeeBps: &feeBps}
+
+		route := testSharedRoute()
+		route.platformFeeBps = 10
+		route.platformFeeAccount = &feeAccount
+
+		tx := buildRouteTx(t, wallet, route, nil)
+		require.NoError(t, v.Verify(context.TODO(), tx, quote))
+
+		err := v.Verify(context.TODO(), tx, testQuote())
+		require.ErrorIs(t, err, swap.ErrVerificationFailed)
+		require.ErrorContains(t, err, "route platform fee 10 bps, quote platform fee 0 bps")
+
+		route.platformFeeBps = 0
+		err = v.Verify(context.TODO(), buildRouteTx(t, wallet, route, nil), testQuote())
+		require.ErrorContains(t, err, "without a platform fee")
+
+		route.platformFeeBps = 10
+		route.platformFeeAccount = nil
+		err = v.Verify(context.TODO(), buildRouteTx(t, wallet, route, nil), quote)
+		require.ErrorContains(t, err, "route platform fee without a platform fee account")
+	})
+
+	t.Run("priority fee above the maximum", func(t *testing.T) {
+		// 100 lamports per compute unit for the 200k compute units of the transaction.
+		price := binary.LittleEndian.AppendUint64([]byte{3}, 100_000_000)
+		tx := buildSwapTx(t, wallet, []solana.Instruction{
+			solana.NewInstruction(solana.ComputeBudget, solana.AccountMetaSlice{}, price),
+		})
+
+		err := v.VerifyTransaction(context.TODO(), tx)
+		require.ErrorIs(t, err, swap.ErrVerificationFailed)
+		require.ErrorContains(t, err, "priority fee of 20000000 lamports exceeds 10000000 lamports")
+
+		generous, err := swap.NewVerifier(
+			testUserPublicKey,
+			"",
+			swap.WithVerifierRPC(rpcMock{owner: solana.TokenProgramID}),
+			swap.WithMaxPriorityFee(20_000_000),
+		)
+		require.NoError(t, err)
+		require.NoError(t, generous.VerifyTransaction(context.TODO(), tx))
+	})
+
+	t.Run("jito tip above the maximum", func(t *testing.T) {
+		tx := buildSwapTx(t, wallet, []solana.Instruction{
+			system.NewTransferInstruction(10_000_000, wallet, jupSolana.JitoTipAccounts[1]).Build(),
+		})
+
+		err := v.VerifyTransaction(context.TODO(), tx)
+		require.ErrorIs(t, err, swap.ErrVerificationFailed)
+		require.ErrorContains(t, err, "jito tips exceed 10000000 lamports")
+
+		generous, err := swap.NewVerifier(
+			testUserPublicKey,
+			"",
+			swap.WithVerifierRPC(rpcMock{owner: solana.TokenProgramID}),
+			swap.WithMaxTip(20_000_000),
+		)
+		require.NoError(t, err)
+		require.NoError(t, generous.VerifyTransaction(context.TODO(), tx))
+	})
+
+	t.Run("durable nonce transaction", func(t *testing.T) {
+		tx := buildSwapTx(t, wallet, nil)
+		require.NoError(t, jupSolana.SetDurableNonce(&tx, jupSolana.NonceAccount{
+			Address:   solana.NewWallet().PublicKey(),
+			Authority: wallet,
+			Nonce:     solana.Hash{7},
+		}))
+
+		require.NoError(t, v.Verify(context.TODO(), tx, testQuote()))
+	})
+
+	t.Run("nonce of another authority", func(t *testing.T) {
+		authority := solana.NewWallet().PublicKey()
+
+		tx := buildSwapTx(t, wallet, nil)
+		require.NoError(t, jupSolana.SetDurableNonce(&tx, jupSolana.NonceAccount{
+			Address:   solana.NewWallet().PublicKey(),
+			Authority: authority,
+			Nonce:     solana.Hash{7},
+		}))
+
+		err := v.VerifyTransaction(context.TODO(), tx)
+		require.ErrorIs(t, err, swap.ErrVerificationFailed)
+		require.ErrorContains(t, err, "unexpected signer "+authority.String())
+	})
+
+	t.Run("nonce advanced after the first instruction", func(t *testing.T) {
+		tx := buildSwapTx(t, wallet, []solana.Instruction{
+			system.NewAdvanceNonceAccountInstruction(
+				solana.NewWallet().PublicKey(),
+				solana.SysVarRecentBlockHashesPubkey,
+				wallet,
+			).Build(),
+		})
+
+		err := v.VerifyTransaction(context.TODO(), tx)
+		require.ErrorIs(t, err, swap.ErrVerificationFailed)
+		require.ErrorContains(t, err, "nonce advanced after the first instruction")
+	})
+
+	t.Run("resolve address lookup tables", func(t *testing.T) {
+		table := solana.NewWallet().PublicKey()
+		tipAccount := jupSolana.JitoTipAccounts[0]
+
+		tx := buildSwapTx(t, wallet, nil, solana.TransactionAddressTables(map[solana.PublicKey]solana.PublicKeySlice{
+			table: {tipAccount},
+		}))
+		require.NotEmpty(t, tx.Message.AddressTableLookups)
+
+		// Round trip the transaction to drop the address tables like a transaction returned by /swap.
+		txBytes, err := tx.MarshalBinary()
+		require.NoError(t, err)
+
+		tx, err = jupSolana.NewTransactionFromBytes(txBytes)
+		require.NoError(t, err)
+		require.Nil(t, tx.Message.GetAddressTables())
+
+		data := make([]byte, 56)
+		data = append(data, tipAccount[:]...)
+
+		// The mock returns the same account for the lookup table and the output mint.
+		withTable, err := swap.NewVerifier(
+			testUserPublicKey,
+			"",
+			swap.WithVerifierRPC(rpcMock{owner: solana.TokenProgramID, data: data}),
+		)
+		require.NoError(t, err)
+		require.NoError(t, withTable.Verify(context.TODO(), tx, testQuote()))
+	})
+}